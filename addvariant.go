@@ -0,0 +1,539 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype/directive"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// addVariantMain implements `go-sumtype add-variant`, which scaffolds a new
+// variant of a sum type throughout its module: it declares the variant with
+// the methods of the sum type, adds a field or method for it to every matcher
+// and visitor of the sum type, along with a stub to every implementation of
+// those visitors, and adds a case clause for it to every switch over the sum
+// type. The result is type-checked before any file is written.
+func addVariantMain(args []string) error {
+	flags := flag.NewFlagSet("add-variant", flag.ExitOnError)
+	dryRun := flags.Bool("n", false, "only print the files that would be changed")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype add-variant [flags] [package.]Type Variant\n\n")
+		fmt.Fprintf(flags.Output(), "Declare a new variant of a sum type and add it to every switch, "+
+			"matcher and visitor of the sum type.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	pattern, name := splitQualifiedName(flags.Arg(0))
+	variant := flags.Arg(1)
+	if !token.IsIdentifier(variant) {
+		return fmt.Errorf("'%s' is not a valid identifier", variant)
+	}
+
+	pkgs, err := driver.Run(nil, pattern)
+	if err != nil {
+		return err
+	}
+	st := driver.FindSumType(pkgs, name)
+	if st == nil {
+		return fmt.Errorf("no sum type named '%s' in %s", name, pattern)
+	}
+	pkg := findPackage(pkgs, st)
+	if pkg.Module == nil {
+		return fmt.Errorf("package %s is not in a module", pkg.PkgPath)
+	}
+	if st.Type.Pkg().Scope().Lookup(variant) != nil {
+		return fmt.Errorf("'%s' is already declared in package %s", variant, pkg.PkgPath)
+	}
+
+	cfg := &driver.Config{Dir: pkg.Module.Dir, Tests: true}
+	all, err := driver.Run(cfg, "./...")
+	if err != nil {
+		return err
+	}
+	if st = driver.FindSumType(all, qualifiedTypeName(st.Type)); st == nil {
+		return fmt.Errorf("sum type '%s' is not in module %s", name, pkg.Module.Path)
+	}
+	s := &scaffold{
+		st:      st,
+		variant: variant,
+		imports: map[string]bool{},
+		inserts: map[string]map[int]string{},
+		sources: map[string][]byte{},
+	}
+	s.declare(all[0].Fset)
+	for _, p := range all {
+		if err := s.extend(p); err != nil {
+			return err
+		}
+	}
+	for _, p := range all {
+		if err := s.implement(p); err != nil {
+			return err
+		}
+	}
+	files, err := s.apply()
+	if err != nil {
+		return err
+	}
+
+	// Add case clauses for the variant to the switches it made
+	// non-exhaustive, as reported by the analyzer.
+	cfg.Overlay = files
+	extended, err := driver.Run(cfg, "./...")
+	if err != nil {
+		return fmt.Errorf("adding '%s' would break the build: %v", variant, err)
+	}
+	cases := map[string]map[int]string{}
+	for _, p := range extended {
+		s.addCases(p, cases)
+	}
+	for path, inserts := range cases {
+		src, ok := files[path]
+		if !ok {
+			if src, err = os.ReadFile(path); err != nil {
+				return err
+			}
+		}
+		if files[path], err = format.Source(insertAll(src, inserts)); err != nil {
+			return fmt.Errorf("formatting %s: %v", path, err)
+		}
+	}
+	cfg.Overlay = files
+	if _, err := driver.Run(cfg, "./..."); err != nil {
+		return fmt.Errorf("adding '%s' would break the build: %v", variant, err)
+	}
+
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Println(path)
+		if *dryRun {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, files[path], info.Mode()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addCases adds to cases, by path and offset, a case clause for the variant
+// in every switch of pkg over the sum type that only misses the variant. The
+// clause is inserted before the default clause, if any, or at the end of the
+// switch.
+func (s *scaffold) addCases(pkg *driver.Package, cases map[string]map[int]string) {
+	for _, f := range pkg.Findings {
+		if f.Check != sumtype.CategoryExhaustiveness || f.Type != qualifiedTypeName(s.st.Type) ||
+			len(f.Missing) != 1 || f.Missing[0] != s.variant {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				swtch, ok := n.(*ast.TypeSwitchStmt)
+				if !ok {
+					return true
+				}
+				// Findings report the positions line directives map
+				// switches to, but the case is inserted in the file
+				// itself.
+				if at := pkg.Fset.Position(swtch.Pos()); at.Filename != f.Position.File ||
+					at.Line != f.Position.Line || at.Column != f.Position.Column {
+					return true
+				}
+				pos := swtch.Body.Rbrace
+				for _, stmt := range swtch.Body.List {
+					if clause := stmt.(*ast.CaseClause); clause.List == nil {
+						pos = clause.Pos()
+						break
+					}
+				}
+				p := pkg.Fset.PositionFor(pos, false)
+				indent := strings.Repeat("\t", p.Column-1)
+				if cases[p.Filename] == nil {
+					cases[p.Filename] = map[int]string{}
+				}
+				cases[p.Filename][p.Offset] = fmt.Sprintf("case %s:\n%s\t// TODO: handle %s\n%s",
+					s.caseType(pkg, file), indent, s.variant, indent)
+				return false
+			})
+		}
+	}
+}
+
+// caseType returns the type expression matching the variant in a case clause
+// in file, qualified as the file imports the package of the sum type.
+func (s *scaffold) caseType(pkg *driver.Package, file *ast.File) string {
+	name := s.variant
+	if pkg.Types.Path() != s.st.Type.Pkg().Path() {
+		qual := s.st.Type.Pkg().Name()
+		for _, imp := range file.Imports {
+			if strings.Trim(imp.Path.Value, "`\"") == s.st.Type.Pkg().Path() && imp.Name != nil {
+				qual = imp.Name.Name
+			}
+		}
+		if qual != "." {
+			name = qual + "." + name
+		}
+	}
+	if s.pointer {
+		name = "*" + name
+	}
+	return name
+}
+
+// scaffold collects the insertions adding a variant to a sum type.
+type scaffold struct {
+	st      *sumtype.SumType
+	variant string
+	// declFile is the file declaring the sum type, to which the variant
+	// is added, and imports are the paths of the packages it must import
+	// for the methods of the variant.
+	declFile string
+	imports  map[string]bool
+	// pointer is true if the variant implements the sum type with pointer
+	// receivers.
+	pointer bool
+	// visitors are the visitors of the sum type.
+	visitors []visitor
+	// inserts maps file paths to the text inserted in them, by offset.
+	inserts map[string]map[int]string
+	// sources caches the contents of the files in which text is inserted.
+	sources map[string][]byte
+}
+
+// insert inserts text at pos.
+func (s *scaffold) insert(fset *token.FileSet, pos token.Pos, text string) {
+	p := fset.PositionFor(pos, false)
+	if s.inserts[p.Filename] == nil {
+		s.inserts[p.Filename] = map[int]string{}
+	}
+	s.inserts[p.Filename][p.Offset] = text
+}
+
+// source returns the source between the given positions.
+func (s *scaffold) source(fset *token.FileSet, start, end token.Pos) (string, error) {
+	path := fset.PositionFor(start, false).Filename
+	src, ok := s.sources[path]
+	if !ok {
+		var err error
+		if src, err = os.ReadFile(path); err != nil {
+			return "", err
+		}
+		s.sources[path] = src
+	}
+	return string(src[fset.PositionFor(start, false).Offset:fset.PositionFor(end, false).Offset]), nil
+}
+
+// declare adds the declaration of the variant, with a method for every
+// method of the sum type, at the end of the file declaring the sum type. Its
+// methods have pointer receivers, unless the existing variants implement the
+// sum type with value receivers.
+func (s *scaffold) declare(fset *token.FileSet) {
+	s.declFile = fset.PositionFor(s.st.Type.Pos(), false).Filename
+	s.pointer = len(s.st.Variants) == 0 || strings.HasPrefix(variantTypeString(s.st, s.st.Variants[0], nil), "*")
+	recv := s.variant
+	if s.pointer {
+		recv = "*" + recv
+	}
+	qual := func(pkg *types.Package) string {
+		if pkg == s.st.Type.Pkg() {
+			return ""
+		}
+		s.imports[pkg.Path()] = true
+		return pkg.Name()
+	}
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "\n// %s is a variant of %s.\ntype %s struct{}\n", s.variant, s.st.Type.Name(), s.variant)
+	iface := s.st.Type.Type().Underlying().(*types.Interface)
+	for i := 0; i < iface.NumMethods(); i++ {
+		m := iface.Method(i)
+		sig := m.Type().(*types.Signature)
+		fmt.Fprintf(&buf, "\nfunc (%s) %s%s {", recv, m.Name(),
+			strings.TrimPrefix(types.TypeString(sig, qual), "func"))
+		if sig.Results().Len() > 0 {
+			fmt.Fprintf(&buf, "\n\tpanic(\"TODO: implement %s\")\n", m.Name())
+		}
+		fmt.Fprintf(&buf, "}\n")
+	}
+	f := fset.File(s.st.Type.Pos())
+	s.insert(fset, f.Pos(f.Size()), buf.String())
+}
+
+// extend adds the variant to the matchers and visitors of the sum type
+// declared in pkg, and to the implementations of the visitors in pkg.
+func (s *scaffold) extend(pkg *driver.Package) error {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				tspec := spec.(*ast.TypeSpec)
+				var err error
+				switch ty := tspec.Type.(type) {
+				case *ast.StructType:
+					if s.isMatcher(file, gen, tspec) {
+						_, err = s.extendFields(pkg, ty.Fields.List, "func")
+					}
+				case *ast.InterfaceType:
+					if obj := pkg.TypesInfo.Defs[tspec.Name]; obj != nil && obj.Name() != s.st.Type.Name() &&
+						s.isVisitor(pkg, ty) {
+						err = s.extendVisitor(pkg, ty, obj.Type().Underlying().(*types.Interface))
+					}
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isMatcher returns true if tspec is declared as a matcher of the sum type
+// with a `go-sumtype:matcher` directive, in or above it.
+func (s *scaffold) isMatcher(file *ast.File, gen *ast.GenDecl, tspec *ast.TypeSpec) bool {
+	start := tspec.Pos()
+	if tspec.Doc != nil {
+		start = tspec.Doc.Pos()
+	} else if gen.Doc != nil && len(gen.Specs) == 1 {
+		start = gen.Doc.Pos()
+	}
+	for _, cg := range file.Comments {
+		if cg.Pos() < start || cg.End() > tspec.End() {
+			continue
+		}
+		for _, d := range directive.Parse(cg) {
+			if d.Kind == directive.Matcher && len(d.Args) > 0 &&
+				(d.Args[0] == s.st.Type.Name() || strings.HasSuffix(d.Args[0], "."+s.st.Type.Name())) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// handledVariant returns the variant of the sum type that is the type of the
+// first parameter of fn, with or without a pointer, along with that parameter's
+// type expression.
+func (s *scaffold) handledVariant(pkg *driver.Package, fn *ast.FuncType) (*types.TypeName, ast.Expr) {
+	if fn.Params == nil || len(fn.Params.List) == 0 {
+		return nil, nil
+	}
+	expr := fn.Params.List[0].Type
+	ty := pkg.TypesInfo.TypeOf(expr)
+	if ptr, ok := ty.(*types.Pointer); ok {
+		ty = ptr.Elem()
+	}
+	named, ok := ty.(*types.Named)
+	if !ok {
+		return nil, nil
+	}
+	for _, v := range s.st.Variants {
+		if qualifiedTypeName(v) == qualifiedTypeName(named.Obj()) {
+			return v, expr
+		}
+	}
+	return nil, nil
+}
+
+// isVisitor returns true if the given interface has a method handling every
+// variant of the sum type, e.g., VisitLit(*Lit).
+func (s *scaffold) isVisitor(pkg *driver.Package, iface *ast.InterfaceType) bool {
+	handled := map[string]bool{}
+	for _, m := range iface.Methods.List {
+		if fn, ok := m.Type.(*ast.FuncType); ok && len(m.Names) == 1 {
+			if v, _ := s.handledVariant(pkg, fn); v != nil && strings.Contains(m.Names[0].Name, v.Name()) {
+				handled[v.Name()] = true
+			}
+		}
+	}
+	return len(s.st.Variants) > 0 && len(handled) == len(s.st.Variants)
+}
+
+// extendFields adds a field or method for the variant after the last of the
+// given fields, or interface methods, that handle a variant, named and typed
+// like it, and returns that field. The keyword precedes the type of the
+// fields, e.g., "func" for the fields of a matcher.
+func (s *scaffold) extendFields(pkg *driver.Package, fields []*ast.Field, keyword string) (*ast.Field, error) {
+	var last *ast.Field
+	var lastVariant *types.TypeName
+	var lastParam ast.Expr
+	for _, f := range fields {
+		fn, ok := f.Type.(*ast.FuncType)
+		if !ok || len(f.Names) != 1 {
+			continue
+		}
+		if v, param := s.handledVariant(pkg, fn); v != nil && strings.Contains(f.Names[0].Name, v.Name()) {
+			last, lastVariant, lastParam = f, v, param
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+	fn := last.Type.(*ast.FuncType)
+	start := fn.Params.Pos()
+	if keyword != "" {
+		start = fn.Pos()
+	}
+	sig, err := s.signature(pkg.Fset, start, fn.End(), lastVariant, lastParam)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.Replace(last.Names[0].Name, lastVariant.Name(), s.variant, 1)
+	s.insert(pkg.Fset, last.End(), "\n"+name+" "+sig)
+	return last, nil
+}
+
+// signature returns the source between start and end, which contains the
+// type expression param of a parameter of type v, with v replaced by the new
+// variant.
+func (s *scaffold) signature(fset *token.FileSet, start, end token.Pos, v *types.TypeName, param ast.Expr) (string, error) {
+	before, err := s.source(fset, start, param.Pos())
+	if err != nil {
+		return "", err
+	}
+	typ, err := s.source(fset, param.Pos(), param.End())
+	if err != nil {
+		return "", err
+	}
+	after, err := s.source(fset, param.End(), end)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(typ, v.Name()) {
+		return "", fmt.Errorf("%s: cannot replace '%s' with '%s' in '%s'",
+			fset.Position(param.Pos()), v.Name(), s.variant, typ)
+	}
+	return before + strings.TrimSuffix(typ, v.Name()) + s.variant + after, nil
+}
+
+// extendVisitor adds a method for the variant to the given visitor
+// interface, and records it so that stubs are added to its implementations.
+func (s *scaffold) extendVisitor(pkg *driver.Package, decl *ast.InterfaceType, iface *types.Interface) error {
+	m, err := s.extendFields(pkg, decl.Methods.List, "")
+	if err != nil || m == nil {
+		return err
+	}
+	v, _ := s.handledVariant(pkg, m.Type.(*ast.FuncType))
+	s.visitors = append(s.visitors, visitor{iface: iface, method: m.Names[0].Name, variant: v})
+	return nil
+}
+
+// visitor is an interface with a method handling every variant of a sum
+// type, e.g., VisitLit(*Lit).
+type visitor struct {
+	iface *types.Interface
+	// method is the name of the method handling variant, after which the
+	// method for the new variant is added to implementations.
+	method  string
+	variant *types.TypeName
+}
+
+// implement adds a stub of the method for the variant after the method
+// handling another variant in every implementation of a visitor in pkg.
+func (s *scaffold) implement(pkg *driver.Package) error {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv == nil || fd.Body == nil {
+				continue
+			}
+			recv := pkg.TypesInfo.TypeOf(fd.Recv.List[0].Type)
+			for _, vis := range s.visitors {
+				if fd.Name.Name != vis.method || recv == nil || !types.Implements(recv, vis.iface) {
+					continue
+				}
+				v, param := s.handledVariant(pkg, fd.Type)
+				if v == nil {
+					continue
+				}
+				sig, err := s.signature(pkg.Fset, fd.Name.End(), fd.Body.Lbrace, v, param)
+				if err != nil {
+					return err
+				}
+				recvSrc, err := s.source(pkg.Fset, fd.Pos(), fd.Name.Pos())
+				if err != nil {
+					return err
+				}
+				name := strings.Replace(fd.Name.Name, v.Name(), s.variant, 1)
+				s.insert(pkg.Fset, fd.End(), fmt.Sprintf("\n\n%s%s%s{\n\tpanic(\"TODO: handle %s\")\n}",
+					recvSrc, name, sig, s.variant))
+			}
+		}
+	}
+	return nil
+}
+
+// apply returns the contents of the files with the insertions, by path. The
+// imports needed by the declaration of the variant are added to its file.
+func (s *scaffold) apply() (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for path, inserts := range s.inserts {
+		src, ok := s.sources[path]
+		if !ok {
+			var err error
+			if src, err = os.ReadFile(path); err != nil {
+				return nil, err
+			}
+		}
+		files[path] = insertAll(src, inserts)
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, s.declFile, files[s.declFile], parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	for path := range s.imports {
+		astutil.AddImport(fset, f, path)
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+	files[s.declFile] = buf.Bytes()
+	return files, nil
+}
+
+// insertAll returns src with the given text inserted at each offset.
+func insertAll(src []byte, inserts map[int]string) []byte {
+	var offsets []int
+	for offset := range inserts {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+	var buf bytes.Buffer
+	last := 0
+	for _, offset := range offsets {
+		buf.Write(src[last:offset])
+		buf.WriteString(inserts[offset])
+		last = offset
+	}
+	buf.Write(src[last:])
+	return buf.Bytes()
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// adoptionMain implements `go-sumtype adoption`, which lists the interfaces
+// that look like sum types but are not declared as such, ranked by how many
+// type switches declaring them would protect, as a roadmap for adopting
+// go-sumtype in an existing code base.
+func adoptionMain(args []string) error {
+	flags := flag.NewFlagSet("adoption", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype adoption [packages]\n\n")
+		fmt.Fprintf(flags.Output(), "List sealed interfaces that are not declared as sum types, "+
+			"ranked by the number of type switches over them.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	pkgs, err := driver.Run(nil, patterns...)
+	if err != nil {
+		return err
+	}
+	return writeCandidates(os.Stdout, candidates(pkgs))
+}
+
+// candidate is an interface that looks like a sum type but is not declared
+// as one.
+type candidate struct {
+	obj      *types.TypeName
+	pos      string
+	variants []types.Object
+	// switches is the number of type switches over the interface, of which
+	// exhaustive handle every variant and nearly handle all but one.
+	switches, exhaustive, nearly int
+}
+
+// candidates returns the candidate sum types in the given packages: sealed
+// interfaces with at least two variants that are not declared as sum types,
+// as for `go-sumtype annotate`. They are sorted by the number of type
+// switches over them in the packages, then by the number of those that are
+// exhaustive or nearly so.
+func candidates(pkgs []*driver.Package) []*candidate {
+	byName := map[string]*candidate{}
+	var cands []*candidate
+	for _, pkg := range pkgs {
+		declared := map[string]bool{}
+		for _, st := range pkg.Result.SumTypes {
+			declared[st.Type.Name()] = true
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			if declared[name] || !looksLikeSumType(pkg.Types, name) {
+				continue
+			}
+			obj := scope.Lookup(name).(*types.TypeName)
+			key := pkg.PkgPath + "." + name
+			if byName[key] != nil {
+				continue
+			}
+			c := &candidate{
+				obj:      obj,
+				pos:      position(pkg.Fset, obj.Pos()),
+				variants: sumtype.VariantsOf(pkg.Types, obj.Type().(*types.Named)),
+			}
+			byName[key] = c
+			cands = append(cands, c)
+		}
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				swtch, ok := n.(*ast.TypeSwitchStmt)
+				if !ok {
+					return true
+				}
+				named, ok := types.Unalias(switchedType(pkg.TypesInfo, swtch)).(*types.Named)
+				if !ok || named.Obj().Pkg() == nil {
+					return true
+				}
+				c := byName[named.Obj().Pkg().Path()+"."+named.Obj().Name()]
+				if c == nil {
+					return true
+				}
+				c.switches++
+				switch len(c.variants) - handledVariants(pkg.TypesInfo, swtch, c.variants) {
+				case 0:
+					c.exhaustive++
+				case 1:
+					c.nearly++
+				}
+				return true
+			})
+		}
+	}
+	sort.SliceStable(cands, func(i, j int) bool {
+		a, b := cands[i], cands[j]
+		if a.switches != b.switches {
+			return a.switches > b.switches
+		}
+		if a.exhaustive+a.nearly != b.exhaustive+b.nearly {
+			return a.exhaustive+a.nearly > b.exhaustive+b.nearly
+		}
+		return a.pos < b.pos
+	})
+	return cands
+}
+
+// switchedType returns the type of the expression a type switch switches
+// over, or nil if it is unknown.
+func switchedType(info *types.Info, swtch *ast.TypeSwitchStmt) types.Type {
+	var expr ast.Expr
+	switch s := swtch.Assign.(type) {
+	case *ast.ExprStmt:
+		expr = s.X
+	case *ast.AssignStmt:
+		expr = s.Rhs[0]
+	}
+	ta, ok := expr.(*ast.TypeAssertExpr)
+	if !ok {
+		return nil
+	}
+	return info.TypeOf(ta.X)
+}
+
+// handledVariants returns the number of the given variants handled by the
+// cases of a type switch, with or without a pointer.
+func handledVariants(info *types.Info, swtch *ast.TypeSwitchStmt, variants []types.Object) int {
+	handled := map[types.Object]bool{}
+	for _, stmt := range swtch.Body.List {
+		for _, expr := range stmt.(*ast.CaseClause).List {
+			ty := info.TypeOf(expr)
+			if ty == nil {
+				continue
+			}
+			for _, v := range variants {
+				if types.Identical(deref(ty), deref(v.Type())) {
+					handled[v] = true
+				}
+			}
+		}
+	}
+	return len(handled)
+}
+
+// deref returns the type pointed to by ty if it is a pointer, and ty
+// otherwise.
+func deref(ty types.Type) types.Type {
+	if ptr, ok := ty.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return ty
+}
+
+// writeCandidates writes a table with a row for every candidate sum type.
+func writeCandidates(w io.Writer, cands []*candidate) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "INTERFACE\tDECLARED\tVARIANTS\tSWITCHES\tEXHAUSTIVE\tMISSING ONE\n")
+	for _, c := range cands {
+		fmt.Fprintf(tw, "%s.%s\t%s\t%d\t%d\t%d\t%d\n",
+			c.obj.Pkg().Path(), c.obj.Name(), c.pos,
+			len(c.variants), c.switches, c.exhaustive, c.nearly)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// annotateMain implements `go-sumtype annotate`, which inserts
+// `//go-sumtype:decl` directives above the declarations of interfaces that
+// look like sum types, to bootstrap the use of go-sumtype in an existing
+// code base.
+func annotateMain(args []string) error {
+	flags := flag.NewFlagSet("annotate", flag.ExitOnError)
+	names := flags.String("types", "",
+		"a comma-separated list of the interfaces to annotate, instead of every one that looks like a sum type")
+	dryRun := flags.Bool("n", false, "only print the interfaces that would be annotated")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype annotate [flags] [packages]\n\n")
+		fmt.Fprintf(flags.Output(), "Add go-sumtype:decl directives for sealed interfaces "+
+			"with at least two variants.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	only := map[string]bool{}
+	for _, name := range strings.Split(*names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			only[name] = true
+		}
+	}
+
+	pkgs, err := driver.Run(nil, patterns...)
+	if err != nil {
+		return err
+	}
+	edits := map[string][]annotation{}
+	for _, pkg := range pkgs {
+		for _, a := range annotations(pkg, only) {
+			edits[a.file] = append(edits[a.file], a)
+		}
+	}
+	var files []string
+	for file := range edits {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		for _, a := range edits[file] {
+			fmt.Printf("%s:%d: %s\n", file, a.line, a.name)
+		}
+		if *dryRun {
+			continue
+		}
+		if err := annotateFile(file, edits[file]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// annotation is a directive to insert for a sum type.
+type annotation struct {
+	// file, line and offset are where the directive is inserted, which is
+	// right above the declaration of the sum type and its documentation.
+	file         string
+	line, offset int
+	// name is the name of the sum type.
+	name string
+}
+
+// annotations returns the directives to insert in the given package for
+// interfaces that look like sum types: sealed interfaces declared at the top
+// level of the package, with at least two variants, that are not declared as
+// sum types yet. If only is not empty, then only interfaces with names in it
+// are annotated, whether or not they have enough variants.
+func annotations(pkg *driver.Package, only map[string]bool) []annotation {
+	declared := map[string]bool{}
+	for _, st := range pkg.Result.SumTypes {
+		declared[st.Type.Name()] = true
+	}
+	var anns []annotation
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				name := spec.(*ast.TypeSpec).Name.Name
+				if declared[name] {
+					continue
+				}
+				if len(only) > 0 {
+					if !only[name] || !isSealed(pkg.Types, name) {
+						continue
+					}
+				} else if !looksLikeSumType(pkg.Types, name) {
+					continue
+				}
+				declared[name] = true
+				pos := gen.Pos()
+				if gen.Doc != nil {
+					pos = gen.Doc.Pos()
+				}
+				p := pkg.Fset.PositionFor(pos, false)
+				anns = append(anns, annotation{
+					file:   p.Filename,
+					line:   p.Line,
+					offset: p.Offset,
+					name:   name,
+				})
+			}
+		}
+	}
+	return anns
+}
+
+// isSealed returns true if the type with the given name in pkg is an
+// interface with at least one unexported method.
+func isSealed(pkg *types.Package, name string) bool {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok || obj.IsAlias() {
+		return false
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return false
+	}
+	for i := 0; i < iface.NumMethods(); i++ {
+		if !iface.Method(i).Exported() {
+			return true
+		}
+	}
+	return false
+}
+
+// looksLikeSumType returns true if the type with the given name in pkg is a
+// sealed interface with at least two variants.
+func looksLikeSumType(pkg *types.Package, name string) bool {
+	if !isSealed(pkg, name) {
+		return false
+	}
+	named, ok := pkg.Scope().Lookup(name).Type().(*types.Named)
+	return ok && len(sumtype.VariantsOf(pkg, named)) >= 2
+}
+
+// annotateFile inserts the given directives in the file at path and formats
+// it.
+func annotateFile(path string, anns []annotation) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sort.Slice(anns, func(i, j int) bool { return anns[i].offset > anns[j].offset })
+	for _, a := range anns {
+		directive := "//go-sumtype:decl " + a.name + "\n\n"
+		src = append(src[:a.offset], append([]byte(directive), src[a.offset:]...)...)
+	}
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("formatting %s: %v", path, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, formatted, info.Mode())
+}
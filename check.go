@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+// Exit codes of go-sumtype when checking packages. These match the exit codes
+// of the standard analysis drivers.
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitFindings = 3
+)
+
+// checkMain runs the analyzer on the packages given on the command line and
+// reports its findings. It returns the exit code of the process.
+func checkMain(args []string) int {
+	if isVetInvocation(args) {
+		unitchecker.Main(sumtype.Analyzer)
+	}
+
+	flags := flag.NewFlagSet("go-sumtype", flag.ExitOnError)
+	jsonOut := flags.Bool("json", false, "emit findings as JSON")
+	sarif := flags.Bool("sarif", false, "emit findings as a SARIF 2.1.0 log, e.g., for GitHub code scanning")
+	quiet := flags.Bool("q", false, "only print the file:line:col of each finding, to stdout")
+	context := flags.Int("c", 0, "print this many lines of source context around each finding")
+	fix := flags.Bool("fix", false, "apply all suggested fixes")
+	tests := flags.Bool("test", true, "indicates whether test files should be analyzed, too")
+	tags := flags.String("tags", "", "a comma-separated list of build tags to consider satisfied")
+	configs := flags.String("configs", "",
+		"a comma-separated list of GOOS/GOARCH pairs to analyze packages for, e.g., linux/amd64,windows/amd64, "+
+			"labeling each finding with those it occurs in")
+	stdin := flags.Bool("stdin", false,
+		"read the contents of the file given with -file from stdin and only report findings in it")
+	file := flags.String("file", "", "the file whose contents are read from stdin with -stdin")
+	overlay := flags.String("overlay", "",
+		"a JSON file, as accepted by go build -overlay, replacing the contents of files with those of others")
+	lock := flags.String("lock", "",
+		"report changes to the variants of sum types since this lock file, written by go-sumtype lock")
+	shard := flags.String("shard", "",
+		"only analyze the i-th of n shards of the packages, given as i/n, e.g., to split analysis across CI jobs")
+	failFast := flags.Bool("fail-fast", false,
+		"stop and exit after the first finding, e.g., in pre-commit hooks")
+	wholeProgram := flags.Bool("whole-program", false,
+		"also report, as informational findings that don't affect the exit status, "+
+			"type switches in dependencies that are not exhaustive over sum types of the packages")
+	exitZero := flags.Bool("exit-zero", false,
+		"exit with status 0 even when there are findings, e.g., while adopting go-sumtype gradually")
+	metrics := flags.String("metrics", "",
+		"write aggregate metrics to this file, as CSV if it ends in .csv and JSON otherwise")
+	compat := flags.String("compat", "",
+		"mimic the invocation, output and exit codes of another checker: burntsushi, the original go-sumtype")
+	sumtype.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "%s\n\n", strings.TrimSpace(sumtype.Analyzer.Doc))
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype [flags] [packages]\n")
+		fmt.Fprintf(flags.Output(), "       go-sumtype command [arguments]\n\n")
+		fmt.Fprintf(flags.Output(), "The commands are: %s.\n\n", strings.Join(commandNames(), ", "))
+		fmt.Fprintf(flags.Output(), "Flags are also read from $%s, before those on the command line.\n\n", envFlags)
+		flags.PrintDefaults()
+	}
+	if err := parseEnvFlags(flags); err != nil {
+		fmt.Fprintf(os.Stderr, "go-sumtype: %v\n", err)
+		return exitError
+	}
+	flags.Parse(args)
+	cfg := &driver.Config{Tests: *tests, WholeProgram: *wholeProgram}
+	if *tags != "" {
+		cfg.BuildFlags = append(cfg.BuildFlags, "-tags="+*tags)
+	}
+	if *shard != "" {
+		var err error
+		if cfg.Shard, cfg.Shards, err = parseShard(*shard); err != nil {
+			fmt.Fprintf(os.Stderr, "go-sumtype: invalid shard '%s' (expected i/n with 1 <= i <= n)\n", *shard)
+			return exitError
+		}
+	}
+	if *overlay != "" {
+		var err error
+		if cfg.Overlay, err = driver.ReadOverlay(*overlay); err != nil {
+			fmt.Fprintf(os.Stderr, "go-sumtype: %v\n", err)
+			return exitError
+		}
+	}
+	switch *compat {
+	case "":
+	case "burntsushi":
+		return burntSushiMain(cfg, flags.Args())
+	default:
+		fmt.Fprintf(os.Stderr, "go-sumtype: unknown compatibility mode '%s' (expected burntsushi)\n", *compat)
+		return exitError
+	}
+	if *quiet && *jsonOut {
+		fmt.Fprintf(os.Stderr, "go-sumtype: -q cannot be used with -json\n")
+		return exitError
+	}
+	if *sarif && (*quiet || *jsonOut) {
+		fmt.Fprintf(os.Stderr, "go-sumtype: -sarif cannot be used with -q or -json\n")
+		return exitError
+	}
+	patterns := flags.Args()
+	if *stdin {
+		if *file == "" || *fix {
+			fmt.Fprintf(os.Stderr, "go-sumtype: -stdin requires -file and cannot be used with -fix\n")
+			return exitError
+		}
+		path, err := stdinOverlay(cfg, *file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "go-sumtype: %v\n", err)
+			return exitError
+		}
+		*file = path
+		patterns = append(patterns, "file="+path)
+		// The buffer may be in the middle of an edit.
+		cfg.BestEffort = true
+	}
+	if len(patterns) == 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	if *failFast {
+		if *fix || *jsonOut || *sarif || *lock != "" || *metrics != "" || *configs != "" || *exitZero || *wholeProgram {
+			fmt.Fprintf(os.Stderr, "go-sumtype: -fail-fast cannot be used with "+
+				"-fix, -json, -sarif, -lock, -metrics, -configs, -exit-zero or -whole-program\n")
+			return exitError
+		}
+		only := ""
+		if *stdin {
+			only = *file
+		}
+		return firstFinding(cfg, patterns, only, newPrinter(*quiet, *context))
+	}
+
+	pkgs, err := runConfigs(cfg, *configs, patterns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-sumtype: %v\n", err)
+		return exitError
+	}
+	if *lock != "" {
+		inv, err := driver.ReadInventory(*lock)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "go-sumtype: %v\n", err)
+			return exitError
+		}
+		driver.CheckLock(pkgs, inv, filepath.Base(*lock))
+	}
+	if *stdin {
+		onlyFile(pkgs, *file)
+	}
+	if *fix {
+		if err := driver.ApplyFixes(pkgs); err != nil {
+			fmt.Fprintf(os.Stderr, "go-sumtype: %v\n", err)
+			return exitError
+		}
+	}
+
+	if *metrics != "" {
+		if err := writeMetrics(*metrics, driver.NewMetrics(pkgs)); err != nil {
+			fmt.Fprintf(os.Stderr, "go-sumtype: %v\n", err)
+			return exitError
+		}
+	}
+
+	findings := driver.Findings(pkgs)
+	if *jsonOut {
+		if err := driver.WriteJSON(os.Stdout, pkgs); err != nil {
+			fmt.Fprintf(os.Stderr, "go-sumtype: %v\n", err)
+			return exitError
+		}
+	} else if *sarif {
+		if err := driver.WriteSARIF(os.Stdout, pkgs); err != nil {
+			fmt.Fprintf(os.Stderr, "go-sumtype: %v\n", err)
+			return exitError
+		}
+	} else {
+		p := newPrinter(*quiet, *context)
+		for _, f := range findings {
+			p.print(f)
+		}
+	}
+	failing := 0
+	for _, f := range findings {
+		if !f.Informational {
+			failing++
+		}
+	}
+	if failing > 0 && !*exitZero {
+		return exitFindings
+	}
+	return exitOK
+}
+
+// envFlags is the environment variable holding flags that are parsed before
+// those given on the command line, so that they can be shared by every
+// invocation without a wrapper script.
+const envFlags = "GOSUMTYPE_FLAGS"
+
+// parseEnvFlags parses the whitespace-separated flags in $GOSUMTYPE_FLAGS
+// into the given flags. Since they are parsed first, flags on the command line
+// override them. An invalid flag is returned as an error naming the variable,
+// rather than exiting with the usage of the command line.
+func parseEnvFlags(flags *flag.FlagSet) error {
+	args := strings.Fields(os.Getenv(envFlags))
+	if len(args) == 0 {
+		return nil
+	}
+	env := flag.NewFlagSet(flags.Name(), flag.ContinueOnError)
+	env.SetOutput(io.Discard)
+	flags.VisitAll(func(f *flag.Flag) {
+		env.Var(f.Value, f.Name, f.Usage)
+	})
+	if err := env.Parse(args); err != nil {
+		return fmt.Errorf("$%s: %v", envFlags, err)
+	}
+	if env.NArg() > 0 {
+		return fmt.Errorf("$%s: unexpected argument '%s' (only flags are allowed)",
+			envFlags, env.Arg(0))
+	}
+	return nil
+}
+
+// parseShard parses the value of the -shard flag, e.g., 3/8, into the shard
+// to analyze and the number of shards. Anything but two integers i/n
+// separated by a slash, with at least one shard and 1 <= i <= n, is an error.
+func parseShard(s string) (shard, shards int, err error) {
+	i, n, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("missing '/' in shard '%s'", s)
+	}
+	if shard, err = strconv.Atoi(i); err != nil {
+		return 0, 0, err
+	}
+	if shards, err = strconv.Atoi(n); err != nil {
+		return 0, 0, err
+	}
+	if shards < 1 || shard < 1 || shard > shards {
+		return 0, 0, fmt.Errorf("shard %d out of range 1..%d", shard, shards)
+	}
+	return shard, shards, nil
+}
+
+// printer prints findings as text.
+type printer struct {
+	// quiet is true if only the positions of findings are printed.
+	quiet bool
+	// context is the number of lines of source printed before and after
+	// the line of each finding.
+	context int
+	// lines caches the lines of the files of findings.
+	lines map[string][]string
+}
+
+func newPrinter(quiet bool, context int) *printer {
+	return &printer{quiet: quiet, context: context, lines: map[string][]string{}}
+}
+
+// print prints a finding to stderr, followed by the lines of source around
+// it, or only its position to stdout if the printer is quiet.
+func (p *printer) print(f driver.Finding) {
+	if p.quiet {
+		fmt.Printf("%s:%d:%d\n", f.Position.File, f.Position.Line, f.Position.Column)
+		return
+	}
+	msg := f.Message
+	if len(f.Configs) > 0 {
+		msg += " [" + strings.Join(f.Configs, ", ") + "]"
+	}
+	fmt.Fprintf(os.Stderr, "%s:%d:%d: %s\n",
+		f.Position.File, f.Position.Line, f.Position.Column, msg)
+	if p.context <= 0 {
+		return
+	}
+	lines, ok := p.lines[f.Position.File]
+	if !ok {
+		src, err := os.ReadFile(f.Position.File)
+		if err == nil {
+			// The newline ending the last line doesn't begin another.
+			lines = strings.Split(strings.TrimSuffix(string(src), "\n"), "\n")
+		}
+		p.lines[f.Position.File] = lines
+	}
+	start, end := max(f.Position.Line-p.context, 1), min(f.Position.Line+p.context, len(lines))
+	for n := start; n <= end; n++ {
+		marker := " "
+		if n == f.Position.Line {
+			marker = ">"
+		}
+		fmt.Fprintf(os.Stderr, "%s %5d | %s\n", marker, n, lines[n-1])
+	}
+}
+
+// firstFinding analyzes the packages matching patterns until the first
+// finding, which it prints, and returns the exit code of the process. If only
+// is not empty, then findings outside of the file at that path are ignored.
+func firstFinding(cfg *driver.Config, patterns []string, only string, p *printer) int {
+	code := exitOK
+	err := driver.StreamUntil(cfg, func(f driver.Finding) bool {
+		if only != "" && f.Position.File != only {
+			return true
+		}
+		p.print(f)
+		code = exitFindings
+		return false
+	}, patterns...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-sumtype: %v\n", err)
+		return exitError
+	}
+	return code
+}
+
+// runConfigs runs the analyzer on the packages matching patterns once for
+// each of the given comma-separated GOOS/GOARCH pairs, and returns all of the
+// analyzed packages. If no configurations are given, then the analyzer is run
+// once for the host platform (or the one given by $GOOS and $GOARCH).
+func runConfigs(cfg *driver.Config, configs string, patterns []string) ([]*driver.Package, error) {
+	if configs == "" {
+		return driver.Run(cfg, patterns...)
+	}
+	var list []string
+	for _, config := range strings.Split(configs, ",") {
+		list = append(list, strings.TrimSpace(config))
+	}
+	return driver.RunConfigs(cfg, list, patterns...)
+}
+
+// stdinOverlay reads the contents of the file with the given name from stdin
+// and adds them to the overlay of cfg, so that the file is analyzed as if it
+// had been saved. It returns the absolute path of the file.
+func stdinOverlay(cfg *driver.Config, name string) (string, error) {
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+	contents, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("reading stdin: %v", err)
+	}
+	if cfg.Overlay == nil {
+		cfg.Overlay = map[string][]byte{}
+	}
+	cfg.Overlay[path] = contents
+	return path, nil
+}
+
+// onlyFile removes every finding that is not in the file at path from the
+// given packages.
+func onlyFile(pkgs []*driver.Package, path string) {
+	for _, pkg := range pkgs {
+		var findings []driver.Finding
+		for _, f := range pkg.Findings {
+			if f.Position.File == path {
+				findings = append(findings, f)
+			}
+		}
+		pkg.Findings = findings
+	}
+}
+
+// writeMetrics writes metrics to the file at path, as CSV if its name ends in
+// .csv and as JSON otherwise.
+func writeMetrics(path string, m *driver.Metrics) error {
+	var buf bytes.Buffer
+	if strings.HasSuffix(path, ".csv") {
+		if err := m.WriteCSV(&buf); err != nil {
+			return err
+		}
+	} else {
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(m); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, buf.Bytes(), 0666)
+}
+
+// isVetInvocation reports whether go-sumtype was invoked by `go vet` using the
+// -vettool flag, in which case the unitchecker driver must be used.
+func isVetInvocation(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if args[0] == "-flags" || strings.HasPrefix(args[0], "-V") {
+		return true
+	}
+	return strings.HasSuffix(args[len(args)-1], ".cfg")
+}
@@ -0,0 +1,35 @@
+// Command gosumtype-check is the go-sumtype analyzer wrapped by the standard
+// single-analyzer driver of golang.org/x/tools/go/analysis/singlechecker,
+// for those who prefer its conventions to those of the full go-sumtype
+// command in the root of this module:
+//
+//	go install github.com/BurntSushi/go-sumtype/cmd/gosumtype-check@latest
+//	gosumtype-check ./...
+//
+// It accepts package patterns, the analyzer's flags and the driver's own,
+// e.g., -fix, -json and -test, while build flags such as -tags are read from
+// $GOFLAGS. It exits with status 3 when there are findings and 1 when
+// packages could not be loaded. None of the subcommands of the full
+// go-sumtype command are available.
+package main
+
+import (
+	"flag"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	// The driver's -trace flag, which writes an execution trace, takes
+	// precedence over the analyzer's, which is dropped rather than
+	// reported as a conflict on every run.
+	a := *sumtype.Analyzer
+	a.Flags = flag.FlagSet{}
+	sumtype.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		if f.Name != "trace" {
+			a.Flags.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	singlechecker.Main(&a)
+}
@@ -0,0 +1,21 @@
+// Command gosumtype-vet is the go-sumtype analyzer as a vet tool, built on
+// golang.org/x/tools/go/analysis/unitchecker, so that go vet runs it package
+// by package and caches its results:
+//
+//	go install github.com/BurntSushi/go-sumtype/cmd/gosumtype-vet@latest
+//	go vet -vettool=$(which gosumtype-vet) ./...
+//
+// Sum types declared in dependencies are known from the facts go vet passes
+// between packages, so switches over them are checked as with go-sumtype. The
+// analyzer's flags are given to go vet prefixed with the analyzer's name,
+// e.g., -gosumtype.scope=exported.
+package main
+
+import (
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(sumtype.Analyzer)
+}
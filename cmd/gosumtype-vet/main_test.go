@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the test binary as the vet tool when go vet invokes it.
+func TestMain(m *testing.M) {
+	if os.Getenv("GOSUMTYPE_VET_TOOL") != "" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestVet(t *testing.T) {
+	dir := t.TempDir()
+	for name, src := range map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.22\n",
+		"ast/ast.go": `package ast
+
+//go-sumtype:decl Expr
+
+type Expr interface{ expr() }
+
+type Lit struct{}
+
+func (Lit) expr() {}
+
+type Neg struct{ X Expr }
+
+func (Neg) expr() {}
+`,
+		"eval/eval.go": `package eval
+
+import "example.com/m/ast"
+
+func eval(e ast.Expr) {
+	switch e.(type) {
+	case ast.Lit:
+	}
+}
+`,
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "vet", "-vettool="+exe, "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOSUMTYPE_VET_TOOL=1")
+	// Depending on its version, go vet asks for findings as JSON, in which
+	// case it exits successfully, so only its output is checked.
+	out, _ := cmd.CombinedOutput()
+	// The sum type of package ast is known in package eval from its facts.
+	for _, want := range []string{
+		"eval.go:6:2",
+		"exhaustiveness check failed for sum type 'Expr': missing cases for Neg",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("go vet output does not contain %q:\n%s", want, out)
+		}
+	}
+}
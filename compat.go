@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+)
+
+// burntSushiUsage is the usage message of the original go-sumtype.
+const burntSushiUsage = `Usage: go-sumtype <args>
+
+go-sumtype takes a list of Go package paths or files and looks for sum type
+declarations in each package/file provided. Exhaustiveness checks are then
+performed for each use of a declared sum type in a type switch statement.
+Namely, go-sumtype will report an error for any type switch statement that
+either lacks a default clause or does not account for all possible variants.
+
+Declarations are provided in comments like so:
+
+//go-sumtype:decl MySumType
+
+MySumType must satisfy the following:
+
+	1. It is a type defined in the same package.
+	2. It is an interface.
+	3. It is *sealed*. That is, part of its interface definition contains an
+	   unexported method.
+
+go-sumtype will produce an error if any of the above is not true.
+
+For valid declarations, go-sumtype will look for all occurrences in which a
+value of type MySumType participates in a type switch statement. In those
+occurrences, it will attempt to detect whether the type switch is exhaustive
+or not. If it's not, go-sumtype will report an error.`
+
+// burntSushiMain checks the packages matching patterns with cfg the way the
+// original go-sumtype did, so that it can replace it in existing scripts:
+// findings are printed to stderr as file:line:col: message, without any
+// context, and both findings and errors exit with status 1. The original took
+// no flags, so this mode is usually enabled with -compat burntsushi in
+// $GOSUMTYPE_FLAGS.
+func burntSushiMain(cfg *driver.Config, patterns []string) int {
+	if len(patterns) == 0 {
+		fmt.Fprintln(os.Stderr, burntSushiUsage)
+		return exitError
+	}
+	pkgs, err := driver.Run(cfg, patterns...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	var list []string
+	for _, f := range driver.Findings(pkgs) {
+		list = append(list, fmt.Sprintf("%s:%d:%d: %s",
+			f.Position.File, f.Position.Line, f.Position.Column, f.Message))
+	}
+	if len(list) > 0 {
+		fmt.Fprintln(os.Stderr, strings.Join(list, "\n"))
+		return exitError
+	}
+	return exitOK
+}
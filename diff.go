@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+)
+
+// diffMain implements `go-sumtype diff`, which reports the sum types and
+// variants added and removed between two inventories written by
+// `go-sumtype lock`, e.g., for two releases of a library.
+func diffMain(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype diff old.lock new.lock\n\n")
+		fmt.Fprintf(flags.Output(), "Report sum types and variants added and removed "+
+			"between two inventories written by go-sumtype lock.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	old, err := driver.ReadInventory(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	new, err := driver.ReadInventory(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	writeDiff(os.Stdout, driver.DiffInventories(old, new))
+	return nil
+}
+
+// writeDiff writes a line for every change, prefixed with + for added sum
+// types, - for removed sum types and ~ for sum types whose variants changed.
+func writeDiff(w io.Writer, changes []driver.InventoryChange) {
+	for _, c := range changes {
+		switch {
+		case c.Added:
+			fmt.Fprintf(w, "+ %s (%s)\n", c.Type, strings.Join(c.AddedVariants, ", "))
+		case c.Removed:
+			fmt.Fprintf(w, "- %s (%s)\n", c.Type, strings.Join(c.RemovedVariants, ", "))
+		default:
+			fmt.Fprintf(w, "~ %s: %s\n", c.Type, c)
+		}
+	}
+}
@@ -52,6 +52,448 @@ Adding either a default clause or a clause to handle *VariantB will cause
 exhaustive checks to pass.
 
 As a special case, if the type switch statement contains a default clause
-that always panics, then exhaustiveness checks are still performed.
+that always panics, then exhaustiveness checks are still performed. The same
+goes for a default clause ending in a call that never returns, such as
+log.Fatalf, os.Exit, runtime.Goexit or t.Fatal, after statements that can't
+leave the clause.
+
+Type switches over a sum type are checked in every package that imports it,
+directly or indirectly, including through a dot import, and in external test
+packages (package foo_test) of the package declaring it. This includes switches
+over aliases of a sum type, e.g., type Expr = internal.Expr, and of its
+variants. Variants that are not exported can't be named outside of their
+package, so such switches need a default clause.
+
+A variant may be a generic type, in which case a clause for any of its
+instantiations, e.g., case *Box[int]:, covers it. Such clauses are only
+recognized in files whose Go version is at least 1.18, as given by the module's
+go directive or by a go:build constraint in the file.
+
+A generic constraint whose type set is a union of named types, e.g., type Shape
+interface{ Circle | Square }, can be declared as a sum type too, without an
+unexported method. Its variants are the types of the union. A switch on a
+value of a type parameter constrained by a sum type, after converting it to an
+interface as in switch any(v).(type), is checked against the sum type.
+Constraints with approximation terms, like ~int, are rejected.
+
+A sum type can be composed of other sum types declared in the same package,
+each of which must implement it, with //go-sumtype:decl Node = Expr | Stmt. Its
+variants are the union of theirs, and a switch over it covers them with a case
+for each part, with cases for their variants, or with a mix of both.
+
+A variant may also be an interface embedding the sum type, e.g., TerminalNode
+for Node, whose own variants are those of the sum type implementing it. A case
+naming it covers all of them, and a switch over a value of its type, e.g.,
+bound by such a case, is checked against them.
+
+When a switch over a sum type is missing variants, go-sumtype also suggests a
+fix that inserts a case clause for each of them before the default clause or at
+the end of the switch. Inserted clauses follow -fix-grouped and -fix-body, as
+for enums.
+
+With the -require-default-value flag, a panicking default clause must also
+mention the switched value in its call to panic, so that the variant that was
+missed can be identified from the panic message. A default clause ending in
+another call that never returns, e.g., os.Exit, must mention it in that call or
+in a statement before it.
+
+With the -require-binding flag, a switch over a sum type that discards the
+switched variable, as in switch x.(type), is reported when its cases refer to
+x. The suggested fix binds it with switch x := x.(type).
+
+With the -require-nil flag, every switch over a sum type must handle a nil
+value with a case nil: clause, even if it has a default clause. A single sum
+type can require it with the nil option of its declaration, as in
+//go-sumtype:decl MySumType nil.
+
+The -default flag sets the policy for default clauses of switches over sum
+types: allow, the default, under which a default clause that doesn't panic
+disables the exhaustiveness check; must-panic, which reports default clauses
+that don't panic; or forbid, which reports every default clause. Under the
+latter two, switches with default clauses are still checked. A sum type can
+override the flag with an option in its declaration, as in
+//go-sumtype:decl MySumType default=forbid.
+
+With the -report-at-variants flag, each variant missing from a switch is also
+reported at its own declaration, which lists the switches to update for the
+author of a new variant. Only variants declared in the package containing the
+switch are reported this way.
+
+With -scope exported, only switches over exported sum types must be
+exhaustive, e.g., to enforce the public contract of a library while its
+internal sum types are still changing. The default is -scope all.
+
+With the -auto-seal flag, every non-generic interface with an unexported method
+is treated as a sum type, as if it were declared with a go-sumtype:decl
+directive. Such an interface can only be implemented in its own package, so
+its variants are still known.
+
+With the -check-reflect-switches flag, switches over reflect.TypeOf of a sum
+type value are checked too, where each case identifies a variant by
+reflect.TypeOf of a value of its type, e.g., reflect.TypeOf(&VariantA{}), or by
+reflect.TypeFor.
+
+A type switch over a value whose static type is not a sum type, e.g., any, may
+still be checked against a sum type by putting a directive naming it on the
+line immediately above the switch:
+
+	//go-sumtype:expect MySumType
+	switch v.(type) {
+	...
+	}
+
+A sum type of another package is qualified by the name of its import, e.g.,
+//go-sumtype:expect ast.Expr, as it is in directives naming sum types in
+general.
+
+With the -check-wrapped-errors flag, when the sum type is an error interface and
+the switched error may have been wrapped by fmt.Errorf with %w or by
+errors.Join, the switch is reported, since wrapped variants match none of its
+cases, and errors.As should be used instead.
+
+Similarly, a parameter of type any that is known to hold a sum type can be bound
+to it with a //go-sumtype:param name MySumType directive inside its function,
+so that every type switch over the parameter in the function's body is checked.
+
+To find where such directives are needed, the -report-escapes flag reports
+values of sum types that escape into any in the exported API of a package:
+values returned as any from exported functions and methods, and values assigned
+to exported variables or fields of type any.
+
+Code that dispatches through a registry of handlers instead of a switch can be
+checked too. A map variable or a registration function containing a
+//go-sumtype:registry MySumType directive must register every variant, where a
+variant is identified by a value of its type or by its reflect.Type. For a map,
+the keys are checked; for a function, the first argument of every call to it in
+the package is checked.
+
+With the -check-assertion-chains flag, chains of two or more if statements
+asserting the same sum type value to its variants, as in
+`if a, ok := v.(*VariantA); ok {...} else if ...`, are checked like type
+switches, where a final else block plays the part of a default clause. The
+suggested fix rewrites such a chain into a type switch that includes the
+missing variants.
+
+A struct containing a //go-sumtype:matcher MySumType directive is a matcher,
+with a function field for each variant, whose first parameter is the variant.
+Matchers missing a field for a variant, function fields that handle no variant
+and literals of a matcher that leave a field nil are reported.
+
+A function containing a //go-sumtype:constructs MySumType directive is a
+factory, which must construct every variant, by returning a value of its type
+or with a composite literal of it, except those listed after "except", e.g.,
+//go-sumtype:constructs MySumType except VariantC.
+
+A struct that embeds the sum type, or an interface that includes it, satisfies
+the sum type whatever value it wraps, which effectively unseals it. Such
+variants are reported, or excluded from the variants of the sum type with the
+-exclude-embedding-variants flag.
+
+A //go-sumtype:require MySumType fmt.Stringer directive requires every variant
+of a sum type declared in the same package to implement the interfaces listed
+after it, which are qualified by the name or path of an imported package unless
+they are declared in the same package. Each variant that doesn't is reported at
+its declaration.
+
+In a multi-module workspace, go-sumtype work analyzes every package of every
+module listed in go.work in one run, so that sum types declared in one module
+are checked in the switches of the others.
+
+Variants and switches in files behind build constraints are only seen when
+those constraints are satisfied. Build tags are given with the -tags flag.
+
+Likewise, files for other platforms are only seen when analyzing for those
+platforms. The -configs flag analyzes packages once for each of a
+comma-separated list of GOOS/GOARCH pairs, e.g., -configs
+linux/amd64,windows/amd64, and merges the findings for all of them, each
+labeled with the configurations it occurs in.
+
+With the -whole-program flag, the type switches in dependencies of the
+packages, e.g., vendored or third-party packages, over sum types declared in
+the packages are checked too. They are reported as informational upstream-gap
+findings, which never affect the exit status, to show which dependencies break
+when a variant is added.
+
+The -shard flag, e.g., -shard 3/8, only analyzes the third of eight disjoint
+shards of the packages, so that analysis can be split across CI jobs. Packages
+are assigned to shards by a hash of their path, so assignments are stable. The
+JSON reports of the shards can be combined with go-sumtype merge.
+
+Files larger than the number of bytes given with the -max-file-size flag, such
+as multi-megabyte generated files, are neither scanned for declarations nor
+checked. A note is logged for each file skipped.
+
+Findings in generated files with //line directives are reported at the
+positions the directives map them to, e.g., in the templates the files were
+generated from. Suggested fixes still edit the generated files themselves.
+
+Types whose names match any of the comma-separated regular expressions given
+with the -ignore-variants flag, or listed as ignoreVariants in the file given
+with the -config flag, are never variants of a sum type. This keeps generated
+test doubles that implement a sealed interface from requiring cases in every
+switch.
+
+When a switch isn't reported as expected, the -trace flag logs how each type
+switch was handled: which sum type it matched and how (or why it matched
+none), which variants each case covered, and whether its default clause
+disabled the check. Only the switches of the main modules are traced, not those
+of dependencies or of the standard library. Tools embedding go-sumtype get the
+same trace, whether or not -trace is given, in the Trace field of the analyzer's
+result.
+
+# Enums
+
+go-sumtype can also check switch statements over enums. An enum is a named
+type with an integer or string underlying type, and its members are the
+constants of that type declared in the same package. Enums are declared like
+so:
+
+	//go-sumtype:enum Color
+
+Every switch statement whose tag is a Color is then checked for
+exhaustiveness, using the same rules for default clauses as type switches.
+
+An enum whose members are flags meant to be combined may be declared as a
+bitflag enum:
+
+	//go-sumtype:enum Perm bitflag
+
+Switches over bitflag enums are not checked, since they cannot reasonably
+enumerate every combination. Instead, map literals keyed by a bitflag enum
+(i.e., dispatch tables) are checked to contain an entry for every member that
+has exactly one bit set. So are switches without a tag whose cases all test the
+same value for flags, e.g., case p&Read != 0:, which must test every such
+member.
+
+Since a value of an enum type may hold a value that isn't one of its members,
+the -enum-require-default flag additionally requires every switch over an enum
+to have a default clause, even when all members are covered.
+
+Array literals indexed by an enum, such as [NumColors]string{...} or
+[...]string{Red: "red", ...}, are checked to contain an element for every
+member of the enum, since such lookup tables break silently when an enum
+grows. Members that are out of bounds for the array are not required. A
+constant used as the length of an array type in the enum's package, like a
+trailing NumColors sentinel, counts the members rather than being one, so
+neither switches nor lookup tables need to cover it.
+
+When an enum switch is missing cases, go-sumtype also suggests a fix that
+inserts a case clause for each missing member. With the -fix-grouped flag, the
+missing members are inserted as a single grouped case clause instead.
+
+Inserted case clauses call panic("unhandled"). The -fix-body flag, or fixBody in
+the file given with -config, picks another body: todo leaves a TODO comment,
+zero returns the zero values of the results of the enclosing function, and
+error does the same but returns errors.New("unhandled") as a last error result.
+
+A bridge directive, //go-sumtype:bridge ExprKind Expr, declares that the members
+of an enum are the kinds of the variants of a sum type declared in the same
+package. Every variant must have a method without parameters that only returns
+its kind, e.g., func (*Lit) Kind() ExprKind { return KindLit }, and every member
+must be the kind of exactly one variant. Switches over the enum, e.g., over
+node.Kind(), are then checked against the variants of the sum type.
+
+Enums defined outside of the packages being analyzed, such as time.Weekday
+from the standard library, can be declared in a JSON configuration file given
+with the -config flag. Each enum names its fully qualified type and may
+optionally restrict its members to an allowlist:
+
+	{
+	  "enums": [
+	    {"type": "time.Weekday"},
+	    {"type": "reflect.Kind", "members": ["Bool", "Int", "String"]}
+	  ]
+	}
+
+# Presets
+
+Rather than picking options one by one, the -preset flag sets them to one of a
+few curated policies: lenient only checks exhaustiveness, which is the default,
+standard also implies -require-default-value, and strict also implies
+-default=must-panic, -enum-require-default, -require-nil and -require-binding.
+Flags given after -preset override it.
+
+Flags may also be given in the GOSUMTYPE_FLAGS environment variable, separated
+by whitespace. They are parsed before the flags on the command line, which
+override them.
+
+With -compat burntsushi, e.g., in GOSUMTYPE_FLAGS, go-sumtype mimics the
+original go-sumtype for existing scripts: findings are printed to stderr as
+file:line:col: message, and both findings and errors exit with status 1.
+
+# Machine-readable output
+
+With the -json flag, findings are printed to stdout as a JSON report with a
+schemaVersion field and a list of findings. Each finding records its package,
+position, check (exhaustiveness, enum-default, default-panic, binding,
+nil-case, default-policy, registry, require, embedding, escape, matcher,
+constructs, wrapped-error, unhandled-variant, dispatch-table, lookup-table,
+declaration, lock or upstream-gap), message, and for exhaustiveness failures
+the qualified name of the type and its missing cases, along with related
+positions, such as the declaration of the sum type, and the edits of any
+suggested fixes. Fields may be added
+without changing the schema version, but removing or changing the meaning of a
+field increments it.
+
+With the -sarif flag, findings are instead printed to stdout as a SARIF 2.1.0
+log, e.g., for GitHub code scanning, with a rule for each check and the
+declaration of the sum type of a finding as a related location.
+
+With the -split-missing flag, each variant or member missing from a switch is
+reported as its own finding, rather than as a single finding listing all of
+them, for tools that track findings individually.
+
+With -metrics file, aggregate counts of packages, sum types, variants, switches
+and findings are written to the given file, as CSV if its name ends in .csv and
+as JSON otherwise.
+
+With -c N, N lines of source before and after each finding are printed below
+it.
+
+With the -q flag, only the position of each finding is printed to stdout, as
+file:line:col.
+
+With the -fail-fast flag, go-sumtype stops as soon as the first finding is
+reported, prints it and exits.
+
+go-sumtype exits with status 3 when there are findings, other than
+informational ones, and status 1 when packages could not be loaded. With -fix, suggested fixes are applied.
+
+With the -exit-zero flag, findings are reported but go-sumtype exits with
+status 0, so that findings can be surfaced in CI without failing the build
+during gradual adoption.
+
+# Editor integration
+
+Editor plugins can check an unsaved buffer in the context of its package with
+go-sumtype -stdin -file path/to/x.go, passing the buffer's contents on stdin.
+Only findings in that file are reported. Directives are read from the buffer
+too, so unsaved declarations are seen.
+
+The buffer need not type-check: packages with type errors are analyzed on a
+best-effort basis, skipping the switches whose subject or cases can't be
+resolved.
+
+The -overlay flag accepts an overlay file in the JSON format of go build
+-overlay, which maps files to the files whose contents replace them, e.g., for
+build systems that generate or rewrite files on the fly.
+
+# Standard driver
+
+The cmd/gosumtype-check command wraps the analyzer in the standard
+singlechecker driver of golang.org/x/tools/go/analysis instead, for those who
+prefer its conventions. It has none of the subcommands below.
+
+The cmd/gosumtype-vet command is built on unitchecker instead, for use with
+go vet -vettool, which runs it package by package and caches its results. The
+analyzer's flags are then given to go vet prefixed with gosumtype., e.g.,
+-gosumtype.scope=exported.
+
+# golangci-lint
+
+The plugin/golangci package builds go-sumtype as a plugin for golangci-lint's
+legacy Go plugin system, with go build -buildmode=plugin. Its settings in
+.golangci.yml are the analyzer's flags without the leading dash.
+
+# Subcommands
+
+Besides checking packages, go-sumtype has a few subcommands that make use of
+the sum types it finds.
+
+go-sumtype annotate [packages] inserts a go-sumtype:decl directive above every
+sealed interface that is implemented by at least two types in its package, or
+only above the interfaces given with -types. With -n, the interfaces are only
+printed.
+
+go-sumtype adoption [packages] lists the same interfaces, ranked by the number
+of type switches over them, along with how many of those are already
+exhaustive or miss a single variant, as a roadmap for a gradual rollout.
+
+go-sumtype add-variant [package.]Type Variant declares a new variant of a sum
+type, with a stub of every method of the sum type, and adds a case clause for
+it to every switch over the sum type that only misses it, a field to every
+matcher of the sum type, and a method to every visitor of the sum type and its
+implementations. The module is type-checked before any file is written. With
+-n, the files are only printed.
+
+go-sumtype remove-variant [package.]Variant removes the declaration of a
+variant and its methods, and removes it from the case clauses of every type
+switch in the module. It lists the removed clauses whose body was not empty and
+the remaining references to the variant, which need manual attention. The module
+is type-checked before any file is written, so nothing is written while
+references remain.
+
+go-sumtype rename-variant [package.]Variant NewName renames a variant of a sum
+type throughout its module, along with the functions, methods and fields named
+after it whose signatures refer to it, e.g., a visitor's VisitLit method, and
+the directives naming it. The renamed module is type-checked before any file is
+written. With -n, the renames are only printed.
+
+go-sumtype snippet [package.]Type prints a ready-to-paste exhaustive type
+switch over a sum type, with a case for every current variant and a panicking
+default clause.
+
+go-sumtype gen kind [package.]Type... generates code from the variants of sum
+types. The rapid kind generates a pgregory.net/rapid generator for each sum
+type that produces every variant, recursing into fields whose type is another
+sum type in the same package, which requires a variant of each sum type that
+does not contain one. The copy kind generates a CopyT function for each sum
+type that returns a copy of its argument using an exhaustive type switch over
+its variants, matching each variant both by value and by pointer. Fields
+holding sum types are copied recursively, and slices and maps are copied one
+level deep, but pointers are shared with the argument. The fuzz kind generates
+a native fuzz target FuzzT for each sum type T whose seed corpus contains every
+variant, constructed with a function NewV() for a variant V if there is one,
+which passes each variant and the fuzzed bytes to a function fuzzT that the
+user writes. The proto kind generates functions TToProto and TFromProto
+converting between each sum type T and the message T generated by protoc-gen-go
+in the package given with -proto, whose only oneof must have a field for every
+variant and no other fields.
+
+go-sumtype explain [package.]Type lists every type declared in the package of
+a sum type, and explains which of them are variants and how they are matched,
+which implement the sum type but are excluded, e.g., by -ignore-variants, and
+which method the others lack.
+
+go-sumtype why file.go:line explains how the type switch at the given line was
+checked, using the output of -trace restricted to that switch.
+
+go-sumtype markdown [packages] renders every sum type in the given packages as
+Markdown, including where each variant is declared and which switches handle
+it.
+
+go-sumtype lock [packages] writes a snapshot of every sum type in the given
+packages and its variants to sumtype.lock. Checking packages with -lock
+sumtype.lock then reports every sum type whose variants changed since the
+snapshot, along with every switch over it that must be revisited.
+
+go-sumtype diff old.lock new.lock compares two inventories written by
+go-sumtype lock, e.g., for two releases of a library, and reports the sum types
+that were added or removed and those whose variants changed.
+
+go-sumtype list-switches [packages] prints every type switch over a sum type,
+with the sum type it dispatches on, how many of its variants are handled and
+whether it has a default clause.
+
+go-sumtype handlers [package.]Type [packages] prints, for each variant of a sum
+type, the case clauses handling it in the type switches of the given packages,
+including the clauses naming an interface it implements and the default clauses
+handling it implicitly.
+
+go-sumtype jsonschema [package.]Type... generates a JSON Schema in which each
+sum type is a oneOf over its variants, with a discriminator property holding
+the name of each variant. With -openapi, OpenAPI components are generated
+instead.
+
+go-sumtype graphql [package.]Type... generates GraphQL SDL in which each sum
+type is a union of its variants, which must be structs. With -interface, sum
+types are interfaces with the fields common to all of their variants instead.
+With -gqlgen file, the models section of a gqlgen configuration binding the
+generated types to their Go types is written to the file too.
+
+go-sumtype typescript [package.]Type... generates TypeScript definitions in
+which each sum type is a discriminated union of its variants, with a
+discriminator property, named with -discriminator, holding the name of each
+variant.
 */
 package main
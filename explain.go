@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// explainMain implements `go-sumtype explain`, which describes how the
+// variants of a sum type are computed: every type declared in its package is
+// listed along with whether it is a variant and why.
+func explainMain(args []string) error {
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	sumtype.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype explain [flags] [package.]Type\n\n")
+		fmt.Fprintf(flags.Output(), "Explain which types are variants of a sum type and why. "+
+			"The flags are those of the analyzer that affect variants.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	pattern, name := splitQualifiedName(flags.Arg(0))
+	pkgs, err := driver.Run(nil, pattern)
+	if err != nil {
+		return err
+	}
+	st := driver.FindSumType(pkgs, name)
+	if st == nil {
+		return fmt.Errorf("no sum type named '%s' in %s", name, pattern)
+	}
+	explain(os.Stdout, findPackage(pkgs, st), st)
+	return nil
+}
+
+// explain writes a description of how the variants of the given sum type,
+// declared in pkg, are computed to w.
+func explain(w io.Writer, pkg *driver.Package, st *sumtype.SumType) {
+	qual := types.RelativeTo(pkg.Types)
+	iface := st.Type.Type().Underlying().(*types.Interface)
+	fmt.Fprintf(w, "sum type %s.%s, declared at %s\n",
+		pkg.PkgPath, st.Type.Name(), pkg.Fset.Position(st.Decl))
+	var methods []string
+	for i := 0; i < iface.NumMethods(); i++ {
+		m := iface.Method(i)
+		methods = append(methods, m.Name()+strings.TrimPrefix(types.TypeString(m.Type(), qual), "func"))
+	}
+	fmt.Fprintf(w, "a variant is a type declared in %s whose method set, or that of a pointer to it, includes:\n",
+		pkg.Types.Name())
+	for _, m := range methods {
+		fmt.Fprintf(w, "\t%s\n", m)
+	}
+
+	isVariant := map[*types.TypeName]bool{}
+	for _, v := range st.Variants {
+		isVariant[v] = true
+	}
+	var variants, excluded, others []string
+	scope := pkg.Types.Scope()
+	for _, n := range scope.Names() {
+		obj, ok := scope.Lookup(n).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		ty := obj.Type()
+		switch {
+		case obj == st.Type:
+			excluded = append(excluded, n+": the sum type itself")
+		case obj.IsAlias():
+			excluded = append(excluded, fmt.Sprintf("%s: an alias of %s, which is considered instead",
+				n, types.TypeString(types.Unalias(ty), qual)))
+		case isVariant[obj] && types.Implements(ty, iface):
+			variants = append(variants, fmt.Sprintf("%s: its method set includes every method, "+
+				"so it is matched by case %s", n, n))
+		case isVariant[obj]:
+			variants = append(variants, fmt.Sprintf("%s: a method has a pointer receiver, "+
+				"so only *%s implements the sum type and it is matched by case *%s", n, n, n))
+		case types.Identical(ty.Underlying(), iface):
+			excluded = append(excluded, n+": identical to the sum type")
+		case types.Implements(ty, iface) || types.Implements(types.NewPointer(ty), iface):
+			excluded = append(excluded, n+": implements the sum type, but is "+exclusionReason(obj, iface))
+		default:
+			m, wrongType := types.MissingMethod(types.NewPointer(ty), iface, true)
+			if wrongType {
+				others = append(others, fmt.Sprintf("%s: method %s has the wrong signature", n, m.Name()))
+			} else {
+				others = append(others, fmt.Sprintf("%s: missing method %s", n, m.Name()))
+			}
+		}
+	}
+	printList(w, "variants", variants)
+	printList(w, "excluded", excluded)
+	printList(w, "not variants", others)
+}
+
+// exclusionReason describes why the variant v of the sum type iface was
+// removed from its variants by the analyzer's options.
+func exclusionReason(v *types.TypeName, iface *types.Interface) string {
+	if s, ok := v.Type().Underlying().(*types.Struct); ok {
+		for i := 0; i < s.NumFields(); i++ {
+			f := s.Field(i)
+			if f.Embedded() && types.IsInterface(f.Type()) && types.Implements(f.Type(), iface) {
+				return "excluded because it embeds " + f.Name() + " (-exclude-embedding-variants)"
+			}
+		}
+	}
+	return "ignored by name (-ignore-variants or ignoreVariants in -config)"
+}
+
+// printList prints a titled list of explanations, unless it is empty.
+func printList(w io.Writer, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s:\n", title)
+	for _, item := range items {
+		fmt.Fprintf(w, "\t%s\n", item)
+	}
+}
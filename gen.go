@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/types"
+	"os"
+	pathpkg "path"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// generators are the kinds of code that `go-sumtype gen` can generate. Each
+// generator writes the code for a single sum type.
+var generators = map[string]func(g *generator, st *sumtype.SumType) error{
+	"copy":  genCopy,
+	"fuzz":  genFuzz,
+	"proto": genProto,
+	"rapid": genRapid,
+}
+
+// genMain implements `go-sumtype gen`, which generates code from the variants
+// of sum types.
+func genMain(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ExitOnError)
+	out := flags.String("o", "", "write generated code to this file instead of stdout")
+	proto := flags.String("proto", "",
+		"the package generated by protoc-gen-go with a message for each sum type, for the proto kind")
+	flags.Usage = func() {
+		var kinds []string
+		for kind := range generators {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype gen [flags] kind [package.]Type...\n\n")
+		fmt.Fprintf(flags.Output(), "Generate code from the variants of sum types. "+
+			"The available kinds are: %s.\n\n", strings.Join(kinds, ", "))
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() < 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	gen, ok := generators[flags.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown kind '%s'", flags.Arg(0))
+	}
+
+	var sums []*sumtype.SumType
+	var pkg *driver.Package
+	for _, arg := range flags.Args()[1:] {
+		pattern, name := splitQualifiedName(arg)
+		pkgs, err := driver.Run(nil, pattern)
+		if err != nil {
+			return err
+		}
+		st := driver.FindSumType(pkgs, name)
+		if st == nil {
+			return fmt.Errorf("no sum type named '%s' in %s", name, pattern)
+		}
+		if pkg != nil && pkg.Types != st.Type.Pkg() {
+			return fmt.Errorf("all sum types must be declared in the same package")
+		}
+		pkg = findPackage(pkgs, st)
+		sums = append(sums, st)
+	}
+
+	g := newGenerator(pkg, flags.Arg(0))
+	if *proto != "" {
+		protoPkg, err := loadProtoPackage(*proto)
+		if err != nil {
+			return err
+		}
+		g.protoPkg = protoPkg
+	}
+	for _, st := range sums {
+		if err := gen(g, st); err != nil {
+			return err
+		}
+	}
+	src, err := g.source()
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0666)
+}
+
+// generator accumulates generated code for a single package.
+type generator struct {
+	// kind is the kind of code being generated.
+	kind string
+	// pkg is the package in which the generated code lives.
+	pkg *driver.Package
+	// imports maps the paths of packages imported by the generated code to
+	// their names.
+	imports map[string]string
+	// done records the names of functions that have already been generated,
+	// so that generators may generate code for sum types they depend on.
+	done map[string]bool
+	// protoPkg is the package given with -proto, if any.
+	protoPkg *types.Package
+	buf      bytes.Buffer
+}
+
+func newGenerator(pkg *driver.Package, kind string) *generator {
+	return &generator{
+		kind:    kind,
+		pkg:     pkg,
+		imports: map[string]string{},
+		done:    map[string]bool{},
+	}
+}
+
+// printf appends formatted code to the generated source.
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// importPkg records that the generated code imports the package with the
+// given path and name, and returns the name to use for it.
+func (g *generator) importPkg(path, name string) string {
+	g.imports[path] = name
+	return name
+}
+
+// qualifier names packages relative to the package of the generated code,
+// importing them as needed.
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg.Types {
+		return ""
+	}
+	return g.importPkg(pkg.Path(), pkg.Name())
+}
+
+// typeString returns the type expression for ty in the generated code.
+func (g *generator) typeString(ty types.Type) string {
+	return types.TypeString(ty, g.qualifier)
+}
+
+// sumType returns the sum type declared in the generated code's package whose
+// type is ty, or nil if there is no such sum type.
+func (g *generator) sumType(ty types.Type) *sumtype.SumType {
+	for _, st := range g.pkg.Result.SumTypes {
+		if types.Identical(types.Unalias(ty), st.Type.Type()) {
+			return st
+		}
+	}
+	return nil
+}
+
+// source returns the formatted source code of the generated file.
+func (g *generator) source() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by go-sumtype gen %s. DO NOT EDIT.\n\n", g.kind)
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg.Name)
+	if len(g.imports) > 0 {
+		var paths []string
+		for path := range g.imports {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		fmt.Fprintf(&buf, "import (\n")
+		for _, path := range paths {
+			if name := g.imports[path]; name != pathpkg.Base(path) {
+				fmt.Fprintf(&buf, "\t%s %q\n", name, path)
+			} else {
+				fmt.Fprintf(&buf, "\t%q\n", path)
+			}
+		}
+		fmt.Fprintf(&buf, ")\n\n")
+	}
+	buf.Write(g.buf.Bytes())
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+// findPackage returns the package declaring the given sum type.
+func findPackage(pkgs []*driver.Package, st *sumtype.SumType) *driver.Package {
+	for _, pkg := range pkgs {
+		if pkg.Types == st.Type.Pkg() {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// structFields returns the fields of the given variant if it is a struct.
+func structFields(v *types.TypeName) []*types.Var {
+	s, ok := v.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	var fields []*types.Var
+	for i := 0; i < s.NumFields(); i++ {
+		fields = append(fields, s.Field(i))
+	}
+	return fields
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"go/types"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// genCopy generates a function returning a copy of a value of the given sum
+// type, with an exhaustive type switch over its variants. A variant that is
+// matched by value is also matched by pointer, since its pointer type
+// implements the sum type as well. Fields whose type is a sum type declared in
+// the same package (or a slice or map of one) are copied recursively with that
+// sum type's copy function. Other slices and maps are copied, but their
+// elements are not. All other fields, including pointers, are copied by
+// assignment, so the copy shares what they reference.
+func genCopy(g *generator, st *sumtype.SumType) error {
+	name := "Copy" + st.Type.Name()
+	if g.done[name] {
+		return nil
+	}
+	g.done[name] = true
+	fmtPkg := g.importPkg("fmt", "fmt")
+	sumTy := g.typeString(st.Type.Type())
+
+	var deps []*sumtype.SumType
+	g.printf("// %s returns a copy of v. Fields holding sum types are copied\n", name)
+	g.printf("// recursively. Slices and maps are copied, but their other elements\n")
+	g.printf("// are shared with v, as are all other fields, including pointers.\n")
+	g.printf("func %s(v %s) %s {\n", name, sumTy, sumTy)
+	g.printf("\tswitch v := v.(type) {\n")
+	g.printf("\tcase nil:\n\t\treturn nil\n")
+	for _, variant := range st.Variants {
+		ty := variantTypeString(st, variant, g.qualifier)
+		deps = append(deps, g.copyCase(ty, structFields(variant))...)
+		if !strings.HasPrefix(ty, "*") {
+			deps = append(deps, g.copyCase("*"+ty, structFields(variant))...)
+		}
+	}
+	g.printf("\tdefault:\n")
+	g.printf("\t\tpanic(%s.Sprintf(\"%s: unhandled variant %%T\", v))\n", fmtPkg, name)
+	g.printf("\t}\n")
+	g.printf("}\n\n")
+
+	for _, dep := range deps {
+		if err := genCopy(g, dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyCase prints the case clause copying a variant of type ty with the given
+// fields, and returns the sum types whose copy functions it calls. A nil
+// pointer is returned as is.
+func (g *generator) copyCase(ty string, fields []*types.Var) []*sumtype.SumType {
+	g.printf("\tcase %s:\n", ty)
+	ptr := strings.HasPrefix(ty, "*")
+	if !ptr && len(fields) == 0 {
+		g.printf("\t\treturn v\n")
+		return nil
+	}
+	if ptr {
+		g.printf("\t\tif v == nil {\n\t\t\treturn v\n\t\t}\n")
+		g.printf("\t\tc := *v\n")
+	} else {
+		g.printf("\t\tc := v\n")
+	}
+	var deps []*sumtype.SumType
+	for _, f := range fields {
+		if dep := g.copyField(f); dep != nil {
+			deps = append(deps, dep)
+		}
+	}
+	if ptr {
+		g.printf("\t\treturn &c\n")
+	} else {
+		g.printf("\t\treturn c\n")
+	}
+	return deps
+}
+
+// copyField prints the statements copying the given field of `v` into
+// `c`. If the field is copied with the copy function of a sum type, then that
+// sum type is returned.
+func (g *generator) copyField(f *types.Var) *sumtype.SumType {
+	name := f.Name()
+	if st := g.sumType(f.Type()); st != nil {
+		g.printf("\t\tc.%s = Copy%s(v.%s)\n", name, st.Type.Name(), name)
+		return st
+	}
+	switch t := f.Type().Underlying().(type) {
+	case *types.Slice:
+		g.printf("\t\tif v.%s != nil {\n", name)
+		g.printf("\t\t\tc.%s = make(%s, len(v.%s))\n", name, g.typeString(f.Type()), name)
+		if st := g.sumType(t.Elem()); st != nil {
+			g.printf("\t\t\tfor i, x := range v.%s {\n", name)
+			g.printf("\t\t\t\tc.%s[i] = Copy%s(x)\n", name, st.Type.Name())
+			g.printf("\t\t\t}\n")
+			g.printf("\t\t}\n")
+			return st
+		}
+		g.printf("\t\t\tcopy(c.%s, v.%s)\n", name, name)
+		g.printf("\t\t}\n")
+	case *types.Map:
+		g.printf("\t\tif v.%s != nil {\n", name)
+		g.printf("\t\t\tc.%s = make(%s, len(v.%s))\n", name, g.typeString(f.Type()), name)
+		g.printf("\t\t\tfor k, x := range v.%s {\n", name)
+		if st := g.sumType(t.Elem()); st != nil {
+			g.printf("\t\t\t\tc.%s[k] = Copy%s(x)\n", name, st.Type.Name())
+			g.printf("\t\t\t}\n")
+			g.printf("\t\t}\n")
+			return st
+		}
+		g.printf("\t\t\t\tc.%s[k] = x\n", name)
+		g.printf("\t\t\t}\n")
+		g.printf("\t\t}\n")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"go/types"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// genFuzz generates a native Go fuzz target for the given sum type, whose seed
+// corpus contains every variant. Fuzzing arguments are limited to basic
+// types, so each seed selects a variant by its index along with a slice of
+// bytes, and the target passes the variant and the bytes to a function named
+// fuzzT (for a sum type T) that the user writes, e.g., to decode the bytes
+// into the variant or to round trip it through an encoding.
+//
+// A variant V is constructed with a function NewV declared in the same
+// package that has no parameters and returns a single value of a type that
+// implements the sum type, if there is one. Otherwise the zero value of the
+// variant is used.
+func genFuzz(g *generator, st *sumtype.SumType) error {
+	name := st.Type.Name()
+	if g.done["Fuzz"+name] {
+		return nil
+	}
+	g.done["Fuzz"+name] = true
+	testing := g.importPkg("testing", "testing")
+	sumTy := g.typeString(st.Type.Type())
+
+	g.printf("// fuzz%sVariants returns a value of every variant of %s.\n", name, name)
+	g.printf("func fuzz%sVariants() []%s {\n", name, sumTy)
+	g.printf("\treturn []%s{\n", sumTy)
+	for _, v := range st.Variants {
+		g.printf("\t\t%s,\n", g.fuzzVariant(st, v))
+	}
+	g.printf("\t}\n")
+	g.printf("}\n\n")
+
+	g.printf("// Fuzz%s fuzzes fuzz%s with every variant of %s, chosen by index.\n",
+		name, name, name)
+	g.printf("func Fuzz%s(f *%s.F) {\n", name, testing)
+	g.printf("\tvariants := fuzz%sVariants()\n", name)
+	g.printf("\tfor i := range variants {\n")
+	g.printf("\t\tf.Add(uint(i), []byte{})\n")
+	g.printf("\t}\n")
+	g.printf("\tf.Fuzz(func(t *%s.T, i uint, data []byte) {\n", testing)
+	g.printf("\t\tfuzz%s(t, variants[i%%uint(len(variants))], data)\n", name)
+	g.printf("\t})\n")
+	g.printf("}\n\n")
+	return nil
+}
+
+// fuzzVariant returns an expression constructing the given variant for the
+// seed corpus of a fuzz target: a call to its constructor, if it has one, or
+// its zero value.
+func (g *generator) fuzzVariant(st *sumtype.SumType, v *types.TypeName) string {
+	if ctor := constructor(st, v); ctor != nil {
+		return g.qualifiedObject(ctor) + "()"
+	}
+	ty := variantTypeString(st, v, g.qualifier)
+	ptr := strings.HasPrefix(ty, "*")
+	if _, ok := v.Type().Underlying().(*types.Struct); ok {
+		if ptr {
+			return "&" + ty[1:] + "{}"
+		}
+		return ty + "{}"
+	}
+	if ptr {
+		return "new(" + ty[1:] + ")"
+	}
+	return "*new(" + ty + ")"
+}
+
+// qualifiedObject returns the name of obj in the generated code.
+func (g *generator) qualifiedObject(obj types.Object) string {
+	if qual := g.qualifier(obj.Pkg()); qual != "" {
+		return qual + "." + obj.Name()
+	}
+	return obj.Name()
+}
+
+// constructor returns the function NewV declared in the package of the
+// variant v, if it has no parameters and returns a single value whose type
+// implements the sum type st. Otherwise nil is returned.
+func constructor(st *sumtype.SumType, v *types.TypeName) *types.Func {
+	fn, ok := v.Pkg().Scope().Lookup("New" + v.Name()).(*types.Func)
+	if !ok {
+		return nil
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 || sig.TypeParams().Len() != 0 {
+		return nil
+	}
+	iface := st.Type.Type().Underlying().(*types.Interface)
+	if !types.Implements(sig.Results().At(0).Type(), iface) {
+		return nil
+	}
+	return fn
+}
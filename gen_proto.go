@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// genProto generates functions converting between the given sum type T and
+// the protobuf message T, generated by protoc-gen-go in the package given
+// with -proto, whose oneof mirrors the sum type. Every variant V must
+// correspond to a field V of the oneof, and vice versa, or no code is
+// generated.
+//
+// TToProto and TFromProto switch over the variants and the oneof fields,
+// respectively, and convert the value of each with functions tVToProto and
+// tVFromProto, which the user writes. The switch over the variants is checked
+// by go-sumtype like any other, so a variant added later without a field of
+// the oneof is reported.
+func genProto(g *generator, st *sumtype.SumType) error {
+	name := st.Type.Name()
+	if g.done[name+"ToProto"] {
+		return nil
+	}
+	g.done[name+"ToProto"] = true
+	if g.protoPkg == nil {
+		return fmt.Errorf("the proto kind requires the -proto flag")
+	}
+	oneof, err := findOneof(g.protoPkg, name)
+	if err != nil {
+		return err
+	}
+	byName := map[string]*oneofField{}
+	for _, f := range oneof.fields {
+		byName[f.name] = f
+	}
+	for _, v := range st.Variants {
+		if byName[v.Name()] == nil {
+			return fmt.Errorf("variant '%s' of sum type '%s' has no field in oneof '%s' of message '%s'",
+				v.Name(), name, oneof.name, name)
+		}
+		delete(byName, v.Name())
+	}
+	for _, f := range oneof.fields {
+		if byName[f.name] != nil {
+			return fmt.Errorf("field '%s' of oneof '%s' of message '%s' is not a variant of sum type '%s'",
+				f.name, oneof.name, name, name)
+		}
+	}
+
+	fmtPkg := g.importPkg("fmt", "fmt")
+	sumTy := g.typeString(st.Type.Type())
+	msgTy := g.typeString(oneof.message)
+	conv := lowerFirst(name)
+
+	g.printf("// %sToProto converts v to the protobuf message %s.\n", name, name)
+	g.printf("func %sToProto(v %s) *%s {\n", name, sumTy, msgTy)
+	g.printf("\tswitch v := v.(type) {\n")
+	g.printf("\tcase nil:\n\t\treturn nil\n")
+	for _, v := range st.Variants {
+		f := oneof.field(v.Name())
+		g.printf("\tcase %s:\n", variantTypeString(st, v, g.qualifier))
+		g.printf("\t\treturn &%s{%s: &%s{%s: %s%sToProto(v)}}\n",
+			msgTy, oneof.goName, g.typeString(f.wrapper), f.name, conv, v.Name())
+	}
+	g.printf("\tdefault:\n")
+	g.printf("\t\tpanic(%s.Sprintf(\"%sToProto: unhandled variant %%T\", v))\n", fmtPkg, name)
+	g.printf("\t}\n")
+	g.printf("}\n\n")
+
+	g.printf("// %sFromProto converts the protobuf message %s to a value of %s.\n",
+		name, name, name)
+	g.printf("func %sFromProto(m *%s) (%s, error) {\n", name, msgTy, sumTy)
+	g.printf("\tif m == nil {\n\t\treturn nil, nil\n\t}\n")
+	g.printf("\tswitch f := m.%s.(type) {\n", oneof.goName)
+	for _, v := range st.Variants {
+		f := oneof.field(v.Name())
+		g.printf("\tcase *%s:\n", g.typeString(f.wrapper))
+		g.printf("\t\treturn %s%sFromProto(f.%s), nil\n", conv, v.Name(), f.name)
+	}
+	g.printf("\tcase nil:\n")
+	g.printf("\t\treturn nil, %s.Errorf(\"%sFromProto: oneof %s is not set\")\n",
+		fmtPkg, name, oneof.name)
+	g.printf("\tdefault:\n")
+	g.printf("\t\treturn nil, %s.Errorf(\"%sFromProto: unknown field %%T of oneof %s\", f)\n",
+		fmtPkg, name, oneof.name)
+	g.printf("\t}\n")
+	g.printf("}\n\n")
+	return nil
+}
+
+// oneof is a oneof of a message generated by protoc-gen-go.
+type oneof struct {
+	// message is the message containing the oneof.
+	message *types.Named
+	// name is the name of the oneof in the .proto file, and goName is the
+	// name of the field of the message holding it.
+	name, goName string
+	// fields are the fields of the oneof, sorted by name.
+	fields []*oneofField
+}
+
+// oneofField is a field of a oneof.
+type oneofField struct {
+	// name is the name of the field in Go, which is both the suffix of its
+	// wrapper type and the name of the wrapper's only field.
+	name string
+	// wrapper is the type wrapping a value of the field, e.g., Expr_Lit.
+	wrapper *types.Named
+}
+
+// field returns the field of the oneof with the given name, or nil.
+func (o *oneof) field(name string) *oneofField {
+	for _, f := range o.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// findOneof finds the only oneof of the message with the given name in the
+// package generated by protoc-gen-go.
+func findOneof(pkg *types.Package, name string) (*oneof, error) {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("no message named '%s' in %s", name, pkg.Path())
+	}
+	msg, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("'%s' in %s is not a message", name, pkg.Path())
+	}
+	s, ok := msg.Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("'%s' in %s is not a message", name, pkg.Path())
+	}
+	var o *oneof
+	var iface *types.Interface
+	for i := 0; i < s.NumFields(); i++ {
+		oneofName := reflect.StructTag(s.Tag(i)).Get("protobuf_oneof")
+		if oneofName == "" {
+			continue
+		}
+		if o != nil {
+			return nil, fmt.Errorf("message '%s' has more than one oneof", name)
+		}
+		o = &oneof{message: msg, name: oneofName, goName: s.Field(i).Name()}
+		iface, _ = s.Field(i).Type().Underlying().(*types.Interface)
+	}
+	if o == nil || iface == nil {
+		return nil, fmt.Errorf("message '%s' has no oneof", name)
+	}
+	for _, n := range pkg.Scope().Names() {
+		wrapper, ok := pkg.Scope().Lookup(n).(*types.TypeName)
+		if !ok || !strings.HasPrefix(n, name+"_") {
+			continue
+		}
+		named, ok := wrapper.Type().(*types.Named)
+		if !ok || !types.Implements(types.NewPointer(named), iface) {
+			continue
+		}
+		ws, ok := named.Underlying().(*types.Struct)
+		if !ok || ws.NumFields() != 1 {
+			continue
+		}
+		o.fields = append(o.fields, &oneofField{name: ws.Field(0).Name(), wrapper: named})
+	}
+	sort.Slice(o.fields, func(i, j int) bool { return o.fields[i].name < o.fields[j].name })
+	return o, nil
+}
+
+// loadProtoPackage loads the package generated by protoc-gen-go with the
+// given pattern, for the -proto flag of `go-sumtype gen`.
+func loadProtoPackage(pattern string) (*types.Package, error) {
+	pkgs, err := driver.Run(nil, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("-proto %s matches %d packages, expected one", pattern, len(pkgs))
+	}
+	return pkgs[0].Types, nil
+}
+
+// lowerFirst returns s with its first letter in lower case.
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// genRapid generates a pgregory.net/rapid generator that produces every
+// variant of the given sum type. Fields whose type is a sum type declared in
+// the same package are generated recursively with that sum type's generator,
+// up to a maximum depth. All other fields are generated with rapid.Make. At
+// least one variant must not contain a sum type, since it is the only kind of
+// variant left to produce once the depth is exhausted.
+func genRapid(g *generator, st *sumtype.SumType) error {
+	name := "gen" + st.Type.Name()
+	if g.done[name] {
+		return nil
+	}
+	g.done[name] = true
+	if !g.hasRapidLeaf(st) {
+		return fmt.Errorf("every variant of '%s' contains a sum type, "+
+			"so none could be generated once the depth is exhausted", st.Type.Name())
+	}
+	rapid := g.importPkg("pgregory.net/rapid", "rapid")
+
+	var deps []*sumtype.SumType
+	g.printf("// %s returns a generator producing every variant of %s. Variants\n", name, st.Type.Name())
+	g.printf("// containing other sum types are only produced while depth > 0.\n")
+	g.printf("func %s(depth int) *%s.Generator[%s] {\n", name, rapid, g.typeString(st.Type.Type()))
+	g.printf("\tgens := []*%s.Generator[%s]{}\n", rapid, g.typeString(st.Type.Type()))
+	for _, v := range st.Variants {
+		recursive := false
+		for _, f := range structFields(v) {
+			if dep := g.rapidSumField(f.Type()); dep != nil {
+				recursive = true
+				deps = append(deps, dep)
+			}
+		}
+		if s, ok := v.Type().Underlying().(*types.Struct); ok && s.NumFields() == 0 {
+			// Custom generators must draw from the bitstream, so
+			// variants without any data are produced with Just.
+			g.printf("\tgens = append(gens, %s.Just[%s](%s))\n",
+				rapid, g.typeString(st.Type.Type()), g.rapidVariant(st, v))
+			continue
+		}
+		indent := "\t"
+		if recursive {
+			g.printf("\tif depth > 0 {\n")
+			indent = "\t\t"
+		}
+		g.printf("%sgens = append(gens, %s.Custom(func(t *%s.T) %s {\n",
+			indent, rapid, rapid, g.typeString(st.Type.Type()))
+		g.printf("%s\treturn %s\n", indent, g.rapidVariant(st, v))
+		g.printf("%s}))\n", indent)
+		if recursive {
+			g.printf("\t}\n")
+		}
+	}
+	g.printf("\treturn %s.OneOf(gens...)\n", rapid)
+	g.printf("}\n\n")
+
+	for _, dep := range deps {
+		if err := genRapid(g, dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasRapidLeaf returns true if a variant of the given sum type has no field
+// generated recursively, so that it may be produced at any depth.
+func (g *generator) hasRapidLeaf(st *sumtype.SumType) bool {
+	for _, v := range st.Variants {
+		leaf := true
+		for _, f := range structFields(v) {
+			if g.rapidSumField(f.Type()) != nil {
+				leaf = false
+			}
+		}
+		if leaf {
+			return true
+		}
+	}
+	return false
+}
+
+// rapidVariant returns an expression constructing the given variant with
+// every field drawn from a generator.
+func (g *generator) rapidVariant(st *sumtype.SumType, v *types.TypeName) string {
+	rapid := g.importPkg("pgregory.net/rapid", "rapid")
+	ty := g.typeString(v.Type())
+	if _, ok := v.Type().Underlying().(*types.Struct); !ok {
+		return rapid + ".Make[" + ty + "]().Draw(t, \"" + v.Name() + "\")"
+	}
+	expr := ty + "{"
+	if strings.HasPrefix(variantTypeString(st, v, g.qualifier), "*") {
+		expr = "&" + expr
+	}
+	for _, f := range structFields(v) {
+		expr += "\n" + f.Name() + ": " + g.rapidField(f) + ","
+	}
+	if len(structFields(v)) > 0 {
+		expr += "\n"
+	}
+	return expr + "}"
+}
+
+// rapidField returns an expression drawing a value for the given field.
+func (g *generator) rapidField(f *types.Var) string {
+	rapid := g.importPkg("pgregory.net/rapid", "rapid")
+	draw := ".Draw(t, \"" + f.Name() + "\")"
+	if st := g.sumType(f.Type()); st != nil {
+		return "gen" + st.Type.Name() + "(depth-1)" + draw
+	}
+	if s, ok := f.Type().Underlying().(*types.Slice); ok {
+		if st := g.sumType(s.Elem()); st != nil {
+			return rapid + ".SliceOfN(gen" + st.Type.Name() + "(depth-1), 0, 3)" + draw
+		}
+	}
+	return rapid + ".Make[" + g.typeString(f.Type()) + "]()" + draw
+}
+
+// rapidSumField returns the sum type generated recursively for a field of
+// the given type, or nil if the field is not a sum type or a slice of one.
+func (g *generator) rapidSumField(ty types.Type) *sumtype.SumType {
+	if s, ok := ty.Underlying().(*types.Slice); ok {
+		ty = s.Elem()
+	}
+	return g.sumType(ty)
+}
@@ -1,8 +1,10 @@
 module github.com/BurntSushi/go-sumtype
 
-go 1.14
+go 1.22.0
+
+require golang.org/x/tools v0.29.0
 
 require (
-	golang.org/x/sys v0.0.0-20210603125802-9665404d3644 // indirect
-	golang.org/x/tools v0.1.2
+	golang.org/x/mod v0.22.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
 )
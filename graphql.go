@@ -0,0 +1,323 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// graphQLMain implements `go-sumtype graphql`, which generates GraphQL SDL
+// describing sum types as unions, or interfaces, of their variants, so that a
+// schema backed by the Go types can't drift from them.
+func graphQLMain(args []string) error {
+	flags := flag.NewFlagSet("graphql", flag.ExitOnError)
+	out := flags.String("o", "", "write the schema to this file instead of stdout")
+	iface := flags.Bool("interface", false,
+		"describe sum types as interfaces with the fields common to all variants instead of unions")
+	gqlgen := flags.String("gqlgen", "",
+		"also write the models section of a gqlgen configuration binding the GraphQL types to the Go types to this file")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype graphql [flags] [package.]Type...\n\n")
+		fmt.Fprintf(flags.Output(), "Generate GraphQL SDL with a union over "+
+			"the variants of each sum type.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	gen := &graphQLGenerator{iface: *iface, defs: map[string]*graphQLDef{}}
+	for _, arg := range flags.Args() {
+		pattern, name := splitQualifiedName(arg)
+		pkgs, err := driver.Run(nil, pattern)
+		if err != nil {
+			return err
+		}
+		st := driver.FindSumType(pkgs, name)
+		if st == nil {
+			return fmt.Errorf("no sum type named '%s' in %s", name, pattern)
+		}
+		gen.pkgs = append(gen.pkgs, pkgs...)
+		if err := gen.sumType(st); err != nil {
+			return err
+		}
+	}
+
+	if *gqlgen != "" {
+		f, err := os.Create(*gqlgen)
+		if err != nil {
+			return err
+		}
+		gen.writeGQLGen(f)
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	if *out == "" {
+		gen.write(os.Stdout)
+		return nil
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	gen.write(f)
+	return f.Close()
+}
+
+// graphQLDef is the definition of a GraphQL type.
+type graphQLDef struct {
+	// kind is "type", "union", "interface" or "scalar".
+	kind string
+	// model is the Go type backing the definition, qualified by its package
+	// path, or empty for built-in scalars.
+	model string
+	// members are the members of a union, and implements the interfaces
+	// implemented by an object type.
+	members, implements []string
+	// fields are the fields of an object type or interface.
+	fields []graphQLField
+}
+
+// graphQLField is a field of a GraphQL object type or interface.
+type graphQLField struct {
+	name, typ string
+}
+
+// graphQLGenerator accumulates GraphQL definitions for sum types, their
+// variants and any named types they refer to.
+type graphQLGenerator struct {
+	pkgs  []*driver.Package
+	iface bool
+	defs  map[string]*graphQLDef
+	// order are the names of the definitions in the order they were added.
+	order []string
+}
+
+// add adds a definition with the given name.
+func (g *graphQLGenerator) add(name string, def *graphQLDef) {
+	g.defs[name] = def
+	g.order = append(g.order, name)
+}
+
+// sumType adds definitions for the given sum type and all of its variants.
+func (g *graphQLGenerator) sumType(st *sumtype.SumType) error {
+	name := st.Type.Name()
+	if _, ok := g.defs[name]; ok {
+		return nil
+	}
+	def := &graphQLDef{kind: "union", model: qualifiedTypeName(st.Type)}
+	g.add(name, def)
+	var variants []*graphQLDef
+	for _, v := range st.Variants {
+		if _, ok := v.Type().Underlying().(*types.Struct); !ok {
+			return fmt.Errorf("variant '%s' of sum type '%s' is not a struct, "+
+				"so it cannot be a GraphQL object type", v.Name(), name)
+		}
+		variant, err := g.object(v)
+		if err != nil {
+			return err
+		}
+		def.members = append(def.members, v.Name())
+		variants = append(variants, variant)
+	}
+	if !g.iface {
+		return nil
+	}
+	def.kind = "interface"
+	def.fields = commonFields(variants)
+	if len(def.fields) == 0 {
+		return fmt.Errorf("the variants of sum type '%s' have no field in common, "+
+			"so it cannot be a GraphQL interface", name)
+	}
+	for _, v := range variants {
+		v.implements = append(v.implements, name)
+	}
+	return nil
+}
+
+// commonFields returns the fields, with the same name and type, that every
+// one of the given object types has.
+func commonFields(objs []*graphQLDef) []graphQLField {
+	if len(objs) == 0 {
+		return nil
+	}
+	var common []graphQLField
+	for _, f := range objs[0].fields {
+		all := true
+		for _, obj := range objs[1:] {
+			found := false
+			for _, g := range obj.fields {
+				found = found || g == f
+			}
+			all = all && found
+		}
+		if all {
+			common = append(common, f)
+		}
+	}
+	return common
+}
+
+// object adds the definition of an object type for the given struct type,
+// with a field for each of its exported fields, and returns it.
+func (g *graphQLGenerator) object(obj *types.TypeName) (*graphQLDef, error) {
+	def := &graphQLDef{kind: "type", model: qualifiedTypeName(obj)}
+	g.add(obj.Name(), def)
+	s := obj.Type().Underlying().(*types.Struct)
+	for i := 0; i < s.NumFields(); i++ {
+		f := s.Field(i)
+		if !f.Exported() {
+			continue
+		}
+		name := lowerFirst(f.Name())
+		if tag, ok := reflect.StructTag(s.Tag(i)).Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		typ, err := g.typ(f.Type())
+		if err != nil {
+			return nil, fmt.Errorf("field '%s' of '%s': %v", f.Name(), obj.Name(), err)
+		}
+		def.fields = append(def.fields, graphQLField{name: name, typ: typ})
+	}
+	if len(def.fields) == 0 {
+		return nil, fmt.Errorf("'%s' has no exported fields, so it cannot be a GraphQL object type",
+			obj.Name())
+	}
+	return def, nil
+}
+
+// typ returns the GraphQL type of a field of the given Go type, which is
+// non-null unless it is a pointer, a slice, an interface or a map, as in the
+// TypeScript generated by `go-sumtype typescript`.
+func (g *graphQLGenerator) typ(ty types.Type) (string, error) {
+	ty = types.Unalias(ty)
+	if ptr, ok := ty.Underlying().(*types.Pointer); ok {
+		typ, err := g.typ(ptr.Elem())
+		return strings.TrimSuffix(typ, "!"), err
+	}
+	if named, ok := ty.(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+			g.scalar("Time")
+			return "Time!", nil
+		}
+		for _, pkg := range g.pkgs {
+			for _, st := range pkg.Result.SumTypes {
+				if st.Type == obj {
+					return obj.Name(), g.sumType(st)
+				}
+			}
+		}
+		if _, ok := named.Underlying().(*types.Struct); ok {
+			if _, ok := g.defs[obj.Name()]; !ok {
+				if _, err := g.object(obj); err != nil {
+					return "", err
+				}
+			}
+			return obj.Name() + "!", nil
+		}
+	}
+	switch t := ty.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsBoolean != 0:
+			return "Boolean!", nil
+		case t.Info()&types.IsInteger != 0:
+			return "Int!", nil
+		case t.Info()&types.IsFloat != 0:
+			return "Float!", nil
+		case t.Info()&types.IsString != 0:
+			return "String!", nil
+		}
+	case *types.Slice:
+		if b, ok := t.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return "String!", nil
+		}
+		// A nil slice is encoded as null.
+		elem, err := g.typ(t.Elem())
+		return "[" + elem + "]", err
+	case *types.Array:
+		elem, err := g.typ(t.Elem())
+		return "[" + elem + "]!", err
+	case *types.Map:
+		g.scalar("Map")
+		return "Map", nil
+	case *types.Interface:
+		g.scalar("Any")
+		return "Any", nil
+	}
+	return "", fmt.Errorf("type %s has no GraphQL equivalent", ty)
+}
+
+// scalar adds the definition of a custom scalar, e.g., Time, which gqlgen
+// provides.
+func (g *graphQLGenerator) scalar(name string) {
+	if _, ok := g.defs[name]; !ok {
+		g.add(name, &graphQLDef{kind: "scalar"})
+	}
+}
+
+// write writes the SDL of the definitions to w.
+func (g *graphQLGenerator) write(w io.Writer) {
+	for i, name := range g.order {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		def := g.defs[name]
+		switch def.kind {
+		case "scalar":
+			fmt.Fprintf(w, "scalar %s\n", name)
+		case "union":
+			fmt.Fprintf(w, "union %s = %s\n", name, strings.Join(def.members, " | "))
+		default:
+			fmt.Fprintf(w, "%s %s", def.kind, name)
+			if len(def.implements) > 0 {
+				fmt.Fprintf(w, " implements %s", strings.Join(def.implements, " & "))
+			}
+			fmt.Fprintf(w, " {\n")
+			for _, f := range def.fields {
+				fmt.Fprintf(w, "  %s: %s\n", f.name, f.typ)
+			}
+			fmt.Fprintf(w, "}\n")
+		}
+	}
+}
+
+// writeGQLGen writes the models section of a gqlgen configuration binding
+// each GraphQL type to the Go type backing it to w.
+func (g *graphQLGenerator) writeGQLGen(w io.Writer) {
+	var names []string
+	for name, def := range g.defs {
+		if def.model != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	fmt.Fprintf(w, "models:\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s:\n    model: %s\n", name, g.defs[name].model)
+	}
+}
+
+// qualifiedTypeName returns the name of obj qualified by its package path,
+// e.g., github.com/foo/ast.Expr.
+func qualifiedTypeName(obj *types.TypeName) string {
+	return obj.Pkg().Path() + "." + obj.Name()
+}
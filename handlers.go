@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// handlersMain implements `go-sumtype handlers`, which prints, for each
+// variant of a sum type, the case clauses of the type switches in the given
+// packages that handle it, so that every handling site can be found when the
+// semantics of a variant change.
+func handlersMain(args []string) error {
+	flags := flag.NewFlagSet("handlers", flag.ExitOnError)
+	tests := flags.Bool("test", true, "indicates whether test files should be searched, too")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype handlers [flags] [package.]Type [packages]\n\n")
+		fmt.Fprintf(flags.Output(), "Print the case clauses handling each variant of a sum type.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	pattern, name := splitQualifiedName(flags.Arg(0))
+	patterns := flags.Args()[1:]
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	pkgs, err := driver.Run(nil, pattern)
+	if err != nil {
+		return err
+	}
+	st := driver.FindSumType(pkgs, name)
+	if st == nil {
+		return fmt.Errorf("no sum type named '%s' in %s", name, pattern)
+	}
+	all, err := driver.Run(&driver.Config{Tests: *tests}, patterns...)
+	if err != nil {
+		return err
+	}
+
+	sites := map[string][]string{}
+	seen := map[string]bool{}
+	for _, pkg := range all {
+		for _, sw := range pkg.Result.Switches {
+			if qualifiedTypeName(sw.SumType.Type) != qualifiedTypeName(st.Type) {
+				continue
+			}
+			for name, clauses := range handlingClauses(pkg, sw) {
+				for _, site := range clauses {
+					if !seen[name+" "+site] {
+						seen[name+" "+site] = true
+						sites[name] = append(sites[name], site)
+					}
+				}
+			}
+		}
+	}
+	for _, v := range st.Variants {
+		fmt.Println(v.Name())
+		if len(sites[v.Name()]) == 0 {
+			fmt.Println("\tnone")
+		}
+		for _, site := range sites[v.Name()] {
+			fmt.Printf("\t%s\n", site)
+		}
+	}
+	return nil
+}
+
+// handlingClauses returns the positions of the case clauses of the given type
+// switch by the names of the variants they handle. As at run time, a variant
+// is handled by the first clause naming it or an interface it implements,
+// such as an interface variant or a part of a composed sum type. The variants
+// missing from the switch are handled by its default clause, if any.
+func handlingClauses(pkg *driver.Package, sw *sumtype.Switch) map[string][]string {
+	clauses := map[string][]string{}
+	handled := map[*types.TypeName]bool{}
+	for _, stmt := range sw.Stmt.Body.List {
+		clause := stmt.(*ast.CaseClause)
+		site := position(pkg.Fset, clause.Pos())
+		if clause.List == nil {
+			for _, v := range sw.Missing {
+				clauses[v.Name()] = append(clauses[v.Name()], site+" (default)")
+			}
+			continue
+		}
+		for _, expr := range clause.List {
+			ty := pkg.TypesInfo.TypeOf(expr)
+			for _, v := range sw.SumType.Variants {
+				if !handled[v] && matches(ty, v) {
+					handled[v] = true
+					clauses[v.Name()] = append(clauses[v.Name()], site)
+				}
+			}
+		}
+	}
+	return clauses
+}
+
+// matches returns true if a case clause naming ty matches the values of the
+// variant v, or pointers to them.
+func matches(ty types.Type, v *types.TypeName) bool {
+	if ty == nil {
+		return false
+	}
+	if iface, ok := ty.Underlying().(*types.Interface); ok {
+		return types.Implements(v.Type(), iface) || types.Implements(types.NewPointer(v.Type()), iface)
+	}
+	if ptr, ok := ty.(*types.Pointer); ok {
+		ty = ptr.Elem()
+	}
+	return types.Identical(ty, v.Type())
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/types"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// jsonSchemaMain implements `go-sumtype jsonschema`, which generates a JSON
+// Schema (or OpenAPI components) describing sum types as a oneOf over their
+// variants.
+func jsonSchemaMain(args []string) error {
+	flags := flag.NewFlagSet("jsonschema", flag.ExitOnError)
+	out := flags.String("o", "", "write the schema to this file instead of stdout")
+	discriminator := flags.String("discriminator", "type",
+		"the name of the property identifying the variant of a value")
+	openapi := flags.Bool("openapi", false,
+		"generate OpenAPI components instead of a JSON Schema document")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype jsonschema [flags] [package.]Type...\n\n")
+		fmt.Fprintf(flags.Output(), "Generate a JSON Schema with a oneOf over "+
+			"the variants of each sum type.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	gen := &schemaGenerator{
+		discriminator: *discriminator,
+		openapi:       *openapi,
+		defs:          map[string]*jsonSchema{},
+	}
+	for _, arg := range flags.Args() {
+		pattern, name := splitQualifiedName(arg)
+		pkgs, err := driver.Run(nil, pattern)
+		if err != nil {
+			return err
+		}
+		st := driver.FindSumType(pkgs, name)
+		if st == nil {
+			return fmt.Errorf("no sum type named '%s' in %s", name, pattern)
+		}
+		gen.pkgs = append(gen.pkgs, pkgs...)
+		gen.sumType(st)
+	}
+
+	var doc interface{}
+	if *openapi {
+		doc = map[string]interface{}{
+			"components": map[string]interface{}{"schemas": gen.defs},
+		}
+	} else {
+		doc = &jsonSchema{
+			Schema: "https://json-schema.org/draft/2020-12/schema",
+			Defs:   gen.defs,
+		}
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0666)
+}
+
+// jsonSchema is the subset of JSON Schema used to describe sum types.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Const                string                 `json:"const,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	OneOf                []*jsonSchema          `json:"oneOf,omitempty"`
+	Discriminator        *jsonDiscriminator     `json:"discriminator,omitempty"`
+	Defs                 map[string]*jsonSchema `json:"$defs,omitempty"`
+}
+
+// jsonDiscriminator is an OpenAPI discriminator object.
+type jsonDiscriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
+}
+
+// schemaGenerator accumulates schema definitions for sum types, their
+// variants and any named types they refer to.
+type schemaGenerator struct {
+	pkgs          []*driver.Package
+	discriminator string
+	openapi       bool
+	defs          map[string]*jsonSchema
+}
+
+// ref returns a reference to the definition with the given name.
+func (g *schemaGenerator) ref(name string) *jsonSchema {
+	if g.openapi {
+		return &jsonSchema{Ref: "#/components/schemas/" + name}
+	}
+	return &jsonSchema{Ref: "#/$defs/" + name}
+}
+
+// sumType adds definitions for the given sum type and all of its variants.
+func (g *schemaGenerator) sumType(st *sumtype.SumType) {
+	name := st.Type.Name()
+	if _, ok := g.defs[name]; ok {
+		return
+	}
+	def := &jsonSchema{}
+	g.defs[name] = def
+	if g.openapi {
+		def.Discriminator = &jsonDiscriminator{
+			PropertyName: g.discriminator,
+			Mapping:      map[string]string{},
+		}
+	}
+	for _, v := range st.Variants {
+		def.OneOf = append(def.OneOf, g.ref(v.Name()))
+		if g.openapi {
+			def.Discriminator.Mapping[v.Name()] = g.ref(v.Name()).Ref
+		}
+		variant := g.object(v.Type())
+		if variant.Properties == nil {
+			variant.Properties = map[string]*jsonSchema{}
+		}
+		variant.Properties[g.discriminator] = &jsonSchema{Type: "string", Const: v.Name()}
+		variant.Required = append([]string{g.discriminator}, variant.Required...)
+		g.defs[v.Name()] = variant
+	}
+}
+
+// object returns the schema of a struct type, or an empty object schema for
+// variants that are not structs.
+func (g *schemaGenerator) object(ty types.Type) *jsonSchema {
+	obj := &jsonSchema{Type: "object"}
+	s, ok := ty.Underlying().(*types.Struct)
+	if !ok {
+		return obj
+	}
+	for i := 0; i < s.NumFields(); i++ {
+		f := s.Field(i)
+		if !f.Exported() {
+			continue
+		}
+		name, omitempty := f.Name(), false
+		if tag, ok := reflect.StructTag(s.Tag(i)).Lookup("json"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				omitempty = omitempty || opt == "omitempty"
+			}
+		}
+		if obj.Properties == nil {
+			obj.Properties = map[string]*jsonSchema{}
+		}
+		obj.Properties[name] = g.schema(f.Type())
+		if !omitempty {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+	return obj
+}
+
+// schema returns the schema for a value of the given type.
+func (g *schemaGenerator) schema(ty types.Type) *jsonSchema {
+	ty = types.Unalias(ty)
+	if named, ok := ty.(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+			return &jsonSchema{Type: "string", Format: "date-time"}
+		}
+		for _, pkg := range g.pkgs {
+			for _, st := range pkg.Result.SumTypes {
+				if st.Type == obj {
+					g.sumType(st)
+					return g.ref(obj.Name())
+				}
+			}
+		}
+		if _, ok := named.Underlying().(*types.Struct); ok {
+			if _, ok := g.defs[obj.Name()]; !ok {
+				g.defs[obj.Name()] = &jsonSchema{}
+				*g.defs[obj.Name()] = *g.object(named)
+			}
+			return g.ref(obj.Name())
+		}
+	}
+	switch t := ty.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsBoolean != 0:
+			return &jsonSchema{Type: "boolean"}
+		case t.Info()&types.IsInteger != 0:
+			return &jsonSchema{Type: "integer"}
+		case t.Info()&types.IsFloat != 0:
+			return &jsonSchema{Type: "number"}
+		case t.Info()&types.IsString != 0:
+			return &jsonSchema{Type: "string"}
+		}
+	case *types.Pointer:
+		return g.schema(t.Elem())
+	case *types.Slice:
+		if b, ok := t.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return &jsonSchema{Type: "string", Format: "byte"}
+		}
+		return &jsonSchema{Type: "array", Items: g.schema(t.Elem())}
+	case *types.Array:
+		return &jsonSchema{Type: "array", Items: g.schema(t.Elem())}
+	case *types.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case *types.Struct:
+		return g.object(t)
+	}
+	// Anything else, e.g., an interface, may be any JSON value.
+	return &jsonSchema{}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+)
+
+// listSwitchesMain implements `go-sumtype list-switches`, which prints every
+// type switch over a sum type in the given packages along with how many of
+// the sum type's variants it handles. A switch over a value narrowed to an
+// interface variant is only expected to handle the variants implementing it.
+func listSwitchesMain(args []string) error {
+	flags := flag.NewFlagSet("list-switches", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype list-switches [packages]\n\n")
+		fmt.Fprintf(flags.Output(), "List every type switch over a sum type "+
+			"with the number of variants it handles.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	pkgs, err := driver.Run(nil, patterns...)
+	if err != nil {
+		return err
+	}
+	return writeSwitches(os.Stdout, pkgs)
+}
+
+// writeSwitches writes a table with a row for every type switch over a sum
+// type in the given packages.
+func writeSwitches(w io.Writer, pkgs []*driver.Package) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "SWITCH\tSUM TYPE\tCOVERED\tDEFAULT\n")
+	for _, pkg := range pkgs {
+		for _, sw := range pkg.Result.Switches {
+			def := "no"
+			if sw.HasDefault {
+				def = "yes"
+			}
+			st := sw.SumType.Type
+			name := st.Pkg().Path() + "." + st.Name()
+			if sw.Narrowed != nil {
+				name += " (" + sw.Narrowed.Name() + ")"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%s\n",
+				position(pkg.Fset, sw.Stmt.Pos()), name,
+				len(sw.Handled), len(sw.Handled)+len(sw.Missing), def)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+)
+
+// lockMain implements `go-sumtype lock`, which writes an inventory of the sum
+// types in the given packages and their variants to a lock file. Checking
+// packages with `-lock` then reports every change to the variants of a sum
+// type, along with the switches that must be revisited because of it.
+func lockMain(args []string) error {
+	flags := flag.NewFlagSet("lock", flag.ExitOnError)
+	out := flags.String("o", "sumtype.lock", "write the inventory to this file, or - for stdout")
+	tests := flags.Bool("test", true, "indicates whether test files should be analyzed, too")
+	tags := flags.String("tags", "", "a comma-separated list of build tags to consider satisfied")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype lock [flags] [packages]\n\n")
+		fmt.Fprintf(flags.Output(), "Write a snapshot of the sum types in the given "+
+			"packages and their variants.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	// Load packages as checking with -lock does, so that sum types declared in
+	// test files aren't reported as added.
+	cfg := &driver.Config{Tests: *tests}
+	if *tags != "" {
+		cfg.BuildFlags = append(cfg.BuildFlags, "-tags="+*tags)
+	}
+	pkgs, err := driver.Run(cfg, patterns...)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := driver.NewInventory(pkgs).Write(&buf); err != nil {
+		return err
+	}
+	if *out == "-" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*out, buf.Bytes(), 0666)
+}
@@ -1,10 +1,54 @@
 package main
 
 import (
-	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
-	"golang.org/x/tools/go/analysis/singlechecker"
+	"fmt"
+	"os"
+	"sort"
 )
 
+// commands are the subcommands of go-sumtype. When the first argument is not
+// the name of a subcommand, go-sumtype runs its analyzer on the packages
+// given.
+var commands = map[string]func(args []string) error{
+	"adoption":       adoptionMain,
+	"add-variant":    addVariantMain,
+	"annotate":       annotateMain,
+	"diff":           diffMain,
+	"explain":        explainMain,
+	"gen":            genMain,
+	"graphql":        graphQLMain,
+	"handlers":       handlersMain,
+	"jsonschema":     jsonSchemaMain,
+	"list-switches":  listSwitchesMain,
+	"lock":           lockMain,
+	"markdown":       markdownMain,
+	"merge":          mergeMain,
+	"remove-variant": removeVariantMain,
+	"rename-variant": renameVariantMain,
+	"snippet":        snippetMain,
+	"typescript":     typeScriptMain,
+	"why":            whyMain,
+}
+
 func main() {
-	singlechecker.Main(sumtype.Analyzer)
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			if err := cmd(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "go-sumtype %s: %v\n", os.Args[1], err)
+				os.Exit(exitError)
+			}
+			return
+		}
+	}
+	os.Exit(checkMain(os.Args[1:]))
+}
+
+// commandNames returns the names of all subcommands, sorted.
+func commandNames() []string {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+	"golang.org/x/tools/txtar"
+)
+
+var update = flag.Bool("update", false, "update the expected results of the tests in testdata")
+
+// testCommands runs the test case of each txtar archive in testdata/dir. See
+// testCommand.
+func testCommands(t *testing.T, dir string) {
+	paths, err := filepath.Glob(filepath.Join("testdata", dir, "*.txtar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no test cases in testdata/%s", dir)
+	}
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".txtar"), func(t *testing.T) {
+			testCommand(t, path)
+		})
+	}
+}
+
+// testCommand runs the test case in the given txtar archive. Its files are
+// written to a temporary directory, which is expected to hold a module, and
+// each line of its comment is then run there: a line starting with
+// go-sumtype runs a subcommand, or checks packages if it names none, and any
+// other line runs the go command, which must succeed, e.g., to check that
+// generated code compiles. A subcommand ending with `> path` writes its
+// output to path rather than along with the output of the others, and one
+// ending with `< path` reads its standard input from path. Checking
+// packages also writes to stderr, which is captured too, and a non-zero exit
+// status is written like an error.
+//
+// The archive also holds the expected results, which are not written: the
+// file named output holds the expected output of the subcommands, including
+// their errors, and a file named want/path holds the expected contents of
+// path once every command has run. The temporary directory is written $WORK
+// in the output. With -update, the expected results are rewritten instead.
+func testCommand(t *testing.T, path string) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ar, err := txtar.ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := t.TempDir()
+	for _, f := range ar.Files {
+		if f.Name == "output" || strings.HasPrefix(f.Name, "want/") {
+			continue
+		}
+		name := filepath.Join(work, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, f.Data, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(work); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	var output bytes.Buffer
+	for _, line := range strings.Split(strings.TrimSpace(string(ar.Comment)), "\n") {
+		args := strings.Fields(line)
+		if len(args) == 0 || strings.HasPrefix(args[0], "#") {
+			continue
+		}
+		if args[0] != "go-sumtype" {
+			cmd := exec.Command(args[0], args[1:]...)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("%s: %v\n%s", line, err, out)
+			}
+			continue
+		}
+		var redirect, input string
+		for n := len(args); n > 2 && (args[n-2] == ">" || args[n-2] == "<"); n = len(args) {
+			if args[n-2] == ">" {
+				redirect = args[n-1]
+			} else {
+				input = args[n-1]
+			}
+			args = args[:n-2]
+		}
+		if len(args) < 2 {
+			t.Fatalf("%s: no subcommand or packages", line)
+		}
+		run, stderr := func() error { return commands[args[1]](args[2:]) }, false
+		if commands[args[1]] == nil {
+			run, stderr = func() error {
+				if code := checkMain(args[1:]); code != exitOK {
+					return fmt.Errorf("exit status %d", code)
+				}
+				return nil
+			}, true
+		}
+		stdin := os.Stdin
+		if input != "" {
+			f, err := os.Open(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			os.Stdin = f
+		}
+		restore := saveFlags()
+		out, err := captureOutput(run, stderr)
+		restore()
+		os.Stdin = stdin
+		if err != nil {
+			t.Fatal(err)
+		}
+		if redirect == "" {
+			output.Write(out)
+		} else if err := os.WriteFile(redirect, out, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := strings.ReplaceAll(output.String(), work, "$WORK")
+
+	if *update {
+		updateArchive(t, path, ar, work, got)
+		return
+	}
+	for _, f := range ar.Files {
+		switch {
+		case f.Name == "output":
+			if got != string(f.Data) {
+				t.Errorf("got output:\n%s\nwant:\n%s", got, f.Data)
+			}
+		case strings.HasPrefix(f.Name, "want/"):
+			name := strings.TrimPrefix(f.Name, "want/")
+			data, err := os.ReadFile(filepath.Join(work, filepath.FromSlash(name)))
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+			if !bytes.Equal(data, f.Data) {
+				t.Errorf("got %s:\n%s\nwant:\n%s", name, data, f.Data)
+			}
+		}
+	}
+}
+
+// saveFlags returns a function restoring the flags of the analyzer to their
+// current values, since subcommands accepting them set them for the whole
+// process.
+func saveFlags() func() {
+	values := map[string]string{}
+	sumtype.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		values[f.Name] = f.Value.String()
+	})
+	return func() {
+		sumtype.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+			if f.Value.String() != values[f.Name] {
+				// The value may not be valid, e.g., the empty -preset
+				// flag, in which case the flags it set are restored
+				// on their own.
+				f.Value.Set(values[f.Name])
+			}
+		})
+	}
+}
+
+// captureOutput calls fn and returns what it wrote to os.Stdout and, if
+// stderr is true, to os.Stderr. An error returned by fn is written as
+// go-sumtype writes it.
+func captureOutput(fn func() error, stderr bool) ([]byte, error) {
+	f, err := os.CreateTemp("", "stdout")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	stdout, errout := os.Stdout, os.Stderr
+	os.Stdout = f
+	if stderr {
+		os.Stderr = f
+	}
+	err = fn()
+	os.Stdout, os.Stderr = stdout, errout
+	if err != nil {
+		fmt.Fprintf(f, "error: %v\n", err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(f)
+	return buf.Bytes(), err
+}
+
+// updateArchive rewrites the expected results in the archive at path with the
+// given output and the current contents of the files they name.
+func updateArchive(t *testing.T, path string, ar *txtar.Archive, work, output string) {
+	files := ar.Files[:0]
+	hasOutput := false
+	for _, f := range ar.Files {
+		switch {
+		case f.Name == "output":
+			f.Data = []byte(output)
+			hasOutput = true
+		case strings.HasPrefix(f.Name, "want/"):
+			name := strings.TrimPrefix(f.Name, "want/")
+			data, err := os.ReadFile(filepath.Join(work, filepath.FromSlash(name)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Data = data
+		}
+		files = append(files, f)
+	}
+	if !hasOutput {
+		files = append(files, txtar.File{Name: "output", Data: []byte(output)})
+	}
+	ar.Files = files
+	if err := os.WriteFile(path, txtar.Format(ar), 0666); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	testCommands(t, "check")
+}
+
+func TestRenameVariant(t *testing.T) {
+	testCommands(t, "rename-variant")
+}
+
+func TestAddVariant(t *testing.T) {
+	testCommands(t, "add-variant")
+}
+
+func TestRemoveVariant(t *testing.T) {
+	testCommands(t, "remove-variant")
+}
+
+func TestListSwitches(t *testing.T) {
+	testCommands(t, "list-switches")
+}
+
+func TestHandlers(t *testing.T) {
+	testCommands(t, "handlers")
+}
+
+func TestGen(t *testing.T) {
+	testCommands(t, "gen")
+}
+
+func TestSnippet(t *testing.T) {
+	testCommands(t, "snippet")
+}
+
+func TestMarkdown(t *testing.T) {
+	testCommands(t, "markdown")
+}
+
+func TestJSONSchema(t *testing.T) {
+	testCommands(t, "jsonschema")
+}
+
+func TestAnnotate(t *testing.T) {
+	testCommands(t, "annotate")
+}
+
+func TestExplain(t *testing.T) {
+	testCommands(t, "explain")
+}
+
+func TestWhy(t *testing.T) {
+	testCommands(t, "why")
+}
+
+func TestAdoption(t *testing.T) {
+	testCommands(t, "adoption")
+}
+
+func TestGraphQL(t *testing.T) {
+	testCommands(t, "graphql")
+}
+
+func TestTypeScript(t *testing.T) {
+	testCommands(t, "typescript")
+}
+
+func TestCompat(t *testing.T) {
+	testCommands(t, "compat")
+}
+
+func TestLock(t *testing.T) {
+	testCommands(t, "lock")
+}
+
+func TestParseShard(t *testing.T) {
+	for _, tt := range []struct {
+		in            string
+		shard, shards int
+		ok            bool
+	}{
+		{"3/8", 3, 8, true},
+		{"1/1", 1, 1, true},
+		{"1/4x", 0, 0, false},
+		{"1x/4", 0, 0, false},
+		{"1/4/2", 0, 0, false},
+		{"1 /4", 0, 0, false},
+		{"14", 0, 0, false},
+		{"/4", 0, 0, false},
+		{"1/0", 0, 0, false},
+		{"2/-1", 0, 0, false},
+		{"0/4", 0, 0, false},
+		{"5/4", 0, 0, false},
+		{"-1/4", 0, 0, false},
+	} {
+		shard, shards, err := parseShard(tt.in)
+		if (err == nil) != tt.ok || shard != tt.shard || shards != tt.shards {
+			t.Errorf("parseShard(%q) = %d, %d, %v", tt.in, shard, shards, err)
+		}
+	}
+}
+
+func TestParseEnvFlags(t *testing.T) {
+	newFlags := func() (*flag.FlagSet, *bool, *string) {
+		flags := flag.NewFlagSet("go-sumtype", flag.ExitOnError)
+		return flags, flags.Bool("q", false, ""), flags.String("tags", "", "")
+	}
+
+	t.Setenv(envFlags, "-q -tags integration")
+	flags, quiet, tags := newFlags()
+	if err := parseEnvFlags(flags); err != nil || !*quiet || *tags != "integration" {
+		t.Errorf("parseEnvFlags = %v, with -q=%v -tags=%q", err, *quiet, *tags)
+	}
+
+	for env, want := range map[string]string{
+		"-nope":    "$GOSUMTYPE_FLAGS: flag provided but not defined: -nope",
+		"-q ./...": "$GOSUMTYPE_FLAGS: unexpected argument './...' (only flags are allowed)",
+		"-q=maybe": "$GOSUMTYPE_FLAGS: invalid boolean value \"maybe\" for -q: parse error",
+		"-tags":    "$GOSUMTYPE_FLAGS: flag needs an argument: -tags",
+		"-h":       "$GOSUMTYPE_FLAGS: flag: help requested",
+	} {
+		t.Setenv(envFlags, env)
+		flags, _, _ := newFlags()
+		if err := parseEnvFlags(flags); err == nil || err.Error() != want {
+			t.Errorf("parseEnvFlags with %s=%q = %v, want %s", envFlags, env, err, want)
+		}
+	}
+}
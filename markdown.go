@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// markdownMain implements `go-sumtype markdown`, which renders the sum types
+// in the given packages, their variants and the switches handling them as
+// Markdown.
+func markdownMain(args []string) error {
+	flags := flag.NewFlagSet("markdown", flag.ExitOnError)
+	out := flags.String("o", "", "write Markdown to this file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype markdown [flags] [packages]\n\n")
+		fmt.Fprintf(flags.Output(), "Render sum types, their variants and the "+
+			"switches handling them as Markdown.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	pkgs, err := driver.Run(nil, patterns...)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	writeMarkdown(&buf, pkgs)
+	if *out == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*out, buf.Bytes(), 0666)
+}
+
+// writeMarkdown writes a Markdown document describing every sum type in the
+// given packages.
+func writeMarkdown(w io.Writer, pkgs []*driver.Package) {
+	fmt.Fprintf(w, "# Sum types\n")
+	for _, pkg := range pkgs {
+		for _, st := range pkg.Result.SumTypes {
+			var switches []*sumtype.Switch
+			for _, p := range pkgs {
+				for _, sw := range p.Result.Switches {
+					if sw.SumType.Type == st.Type {
+						switches = append(switches, sw)
+					}
+				}
+			}
+
+			fmt.Fprintf(w, "\n## %s.%s\n\n", pkg.PkgPath, st.Type.Name())
+			fmt.Fprintf(w, "Declared at `%s`.\n\n", position(pkg.Fset, st.Decl))
+			fmt.Fprintf(w, "| Variant | Declared at | Handled by |\n")
+			fmt.Fprintf(w, "| --- | --- | --- |\n")
+			for _, v := range st.Variants {
+				var sites []string
+				for _, sw := range switches {
+					for _, h := range sw.Handled {
+						if h == v {
+							sites = append(sites, "`"+position(pkg.Fset, sw.Stmt.Pos())+"`")
+						}
+					}
+				}
+				handled := strings.Join(sites, ", ")
+				if handled == "" {
+					handled = "none"
+				}
+				fmt.Fprintf(w, "| `%s` | `%s` | %s |\n",
+					v.Name(), position(pkg.Fset, v.Pos()), handled)
+			}
+			if len(switches) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "\n### Switches\n\n")
+			fmt.Fprintf(w, "| Switch | Variants handled | Default |\n")
+			fmt.Fprintf(w, "| --- | --- | --- |\n")
+			for _, sw := range switches {
+				def := "no"
+				if sw.HasDefault {
+					def = "yes"
+				}
+				fmt.Fprintf(w, "| `%s` | %d of %d | %s |\n",
+					position(pkg.Fset, sw.Stmt.Pos()),
+					len(sw.Handled), len(sw.Handled)+len(sw.Missing), def)
+			}
+		}
+	}
+}
+
+// position formats pos as file:line, with the file name relative to the
+// current directory when possible.
+func position(fset *token.FileSet, pos token.Pos) string {
+	p := fset.Position(pos)
+	name := p.Filename
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, name); err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+	}
+	return fmt.Sprintf("%s:%d", name, p.Line)
+}
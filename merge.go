@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+)
+
+// mergeMain implements `go-sumtype merge`, which combines the JSON reports
+// written with -json by separate runs, e.g., for each shard given with
+// -shard, into a single report.
+func mergeMain(args []string) error {
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	out := flags.String("o", "-", "write the merged report to this file, or - for stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype merge [flags] report.json...\n\n")
+		fmt.Fprintf(flags.Output(), "Combine JSON reports written with -json into one, "+
+			"removing duplicate findings.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	var reports []*driver.Report
+	for _, path := range flags.Args() {
+		r, err := driver.ReadReport(path)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, r)
+	}
+	var buf bytes.Buffer
+	if err := driver.MergeReports(reports...).Write(&buf); err != nil {
+		return err
+	}
+	if *out == "-" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*out, buf.Bytes(), 0666)
+}
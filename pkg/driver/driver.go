@@ -0,0 +1,407 @@
+// Package driver loads Go packages and runs the go-sumtype analyzer on them.
+//
+// It is used by the go-sumtype command, and may be used by other tools that
+// want to embed go-sumtype without writing their own analysis driver.
+package driver
+
+import (
+	"fmt"
+	"go/types"
+	"hash/fnv"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// Config controls how packages are loaded and analyzed.
+type Config struct {
+	// Dir is the directory in which to run the build system. If empty,
+	// the current directory is used.
+	Dir string
+	// Tests indicates whether test packages should also be loaded and
+	// analyzed.
+	Tests bool
+	// BuildFlags are passed to the build system when loading packages,
+	// e.g., []string{"-tags=integration"}.
+	BuildFlags []string
+	// Env are environment variables that are added to the environment of
+	// the build system, e.g., []string{"GOOS=windows"} to analyze packages
+	// as they are built for Windows.
+	Env []string
+	// Overlay maps absolute file paths to contents that are used in place
+	// of the contents of those files on disk, e.g., for unsaved editor
+	// buffers. Files in the overlay need not exist on disk. Directives are
+	// read from the overlay as well, so unsaved declarations are seen.
+	Overlay map[string][]byte
+	// Shard and Shards split the packages matching the patterns into
+	// Shards disjoint sets, so that they can be analyzed by separate jobs,
+	// and only the Shard-th of them (counting from 1) is analyzed. Packages
+	// are assigned to shards by a hash of their path, so a package stays in
+	// the same shard as packages are added and removed, and a package and
+	// its tests are always in the same shard. If Shards is zero, then every
+	// package is analyzed.
+	Shard, Shards int
+	// Suppressions, if not nil, decides which findings are suppressed.
+	// Suppressed findings are dropped before they are reported, and their
+	// diagnostics are neither returned nor fixed.
+	Suppressions SuppressionProvider
+	// WholeProgram, if true, also reports the type switches in dependencies
+	// of the packages matching the patterns, e.g., vendored or third-party
+	// packages, that are not exhaustive over sum types declared in those
+	// packages. Such findings are informational; see UpstreamGapCheck. The
+	// packages containing them are returned along with the others. It has
+	// no effect on Stream.
+	WholeProgram bool
+	// BestEffort, if true, analyzes packages that don't type-check, e.g.,
+	// unsaved editor buffers in the middle of an edit, instead of failing.
+	// Switches whose subject or cases could not be resolved are skipped.
+	// Packages that could not be loaded or parsed still fail.
+	BestEffort bool
+}
+
+// SuppressionProvider decides whether findings are suppressed, for tools
+// embedding go-sumtype that keep track of exemptions themselves, e.g., in a
+// central service, rather than in baseline files.
+type SuppressionProvider interface {
+	// Suppressed returns true if the given finding must not be reported.
+	// If it returns an error, then analysis fails with that error.
+	//
+	// Suppressed may be called concurrently for findings in different
+	// packages.
+	Suppressed(f Finding) (bool, error)
+}
+
+// SuppressionFunc is a function that implements SuppressionProvider.
+type SuppressionFunc func(f Finding) (bool, error)
+
+// Suppressed calls fn(f).
+func (fn SuppressionFunc) Suppressed(f Finding) (bool, error) {
+	return fn(f)
+}
+
+// suppressed returns true if the given finding is suppressed by the
+// configuration.
+func (cfg *Config) suppressed(f Finding) (bool, error) {
+	if cfg.Suppressions == nil {
+		return false, nil
+	}
+	ok, err := cfg.Suppressions.Suppressed(f)
+	if err != nil {
+		return false, fmt.Errorf("checking suppression of finding at %s:%d:%d: %v",
+			f.Position.File, f.Position.Line, f.Position.Column, err)
+	}
+	return ok, nil
+}
+
+// inShard returns true if the package with the given path is in the given
+// shard of shards.
+func inShard(path string, shard, shards int) bool {
+	if shards <= 0 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(strings.TrimSuffix(path, "_test")))
+	return int(h.Sum32()%uint32(shards)) == shard-1
+}
+
+// Findings returns the findings of all of the given packages, sorted by
+// position. Findings reported more than once, e.g., for a package and its
+// test variant, are only included once, labeled with every build
+// configuration they were found in.
+func Findings(pkgs []*Package) []Finding {
+	type key struct {
+		pos     Position
+		message string
+	}
+	seen := map[key]int{}
+	var findings []Finding
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Findings {
+			k := key{f.Position, f.Message}
+			if i, ok := seen[k]; ok {
+				findings[i].Configs = mergeConfigs(findings[i].Configs, f.Configs)
+				continue
+			}
+			seen[k] = len(findings)
+			findings = append(findings, f)
+		}
+	}
+	sortFindings(findings)
+	return findings
+}
+
+// mergeConfigs returns the build configurations in a followed by those in b
+// that are not in a.
+func mergeConfigs(a, b []string) []string {
+	merged := append([]string(nil), a...)
+	for _, config := range b {
+		if !slices.Contains(merged, config) {
+			merged = append(merged, config)
+		}
+	}
+	return merged
+}
+
+// Package is the result of analyzing a single package.
+type Package struct {
+	// Package is the loaded package, including its syntax and type
+	// information.
+	*packages.Package
+	// Result is the result of the analyzer for this package.
+	Result *sumtype.Result
+	// Diagnostics are the problems reported by the analyzer for this
+	// package.
+	Diagnostics []analysis.Diagnostic
+	// Findings are the problems reported by the analyzer for this package,
+	// sorted by position.
+	Findings []Finding
+}
+
+// Run loads the packages matching the given patterns and runs the go-sumtype
+// analyzer on each of them.
+//
+// If any package could not be loaded or type checked, then the errors are
+// printed to stderr and an error is returned.
+func Run(cfg *Config, patterns ...string) ([]*Package, error) {
+	return run(cfg, nil, patterns)
+}
+
+// RunConfigs is like Run, but analyzes the packages once for each of the
+// given build configurations, as GOOS/GOARCH pairs, e.g., "windows/amd64",
+// since variants and switches may differ between platforms. The findings of
+// each package are labeled with the configuration it was analyzed for, so
+// that Findings reports each finding once along with every configuration it
+// occurs in.
+func RunConfigs(cfg *Config, configs []string, patterns ...string) ([]*Package, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	var all []*Package
+	for _, config := range configs {
+		goos, goarch, ok := strings.Cut(config, "/")
+		if !ok || goos == "" || goarch == "" {
+			return nil, fmt.Errorf("invalid build configuration '%s' (expected GOOS/GOARCH)", config)
+		}
+		ccfg := *cfg
+		ccfg.Env = append(append([]string(nil), cfg.Env...), "GOOS="+goos, "GOARCH="+goarch)
+		pkgs, err := Run(&ccfg, patterns...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", config, err)
+		}
+		for _, pkg := range pkgs {
+			for i := range pkg.Findings {
+				pkg.Findings[i].Configs = []string{config}
+			}
+		}
+		all = append(all, pkgs...)
+	}
+	return all, nil
+}
+
+// Stream is like Run, but instead of returning the analyzed packages, it
+// calls fn with each finding as soon as the package containing it has been
+// analyzed. Findings are not retained once fn returns, which lets callers
+// forward them, e.g., to a review bot or language server, without holding
+// every finding in memory.
+//
+// fn is never called concurrently, but the order in which findings are
+// delivered is unspecified. Findings reported more than once, e.g., for a
+// package and its test variant, are only delivered once.
+func Stream(cfg *Config, fn func(Finding), patterns ...string) error {
+	return StreamUntil(cfg, func(f Finding) bool {
+		fn(f)
+		return true
+	}, patterns...)
+}
+
+// StreamUntil is like Stream, but stops as soon as fn returns false: fn is
+// not called again, and the packages that are left are not analyzed, e.g., to
+// exit after the first finding.
+func StreamUntil(cfg *Config, fn func(Finding) bool, patterns ...string) error {
+	_, err := run(cfg, fn, patterns)
+	return err
+}
+
+// run implements Run and StreamUntil. If fn is not nil, then findings are
+// delivered to it and nil packages are returned.
+func run(cfg *Config, fn func(Finding) bool, patterns []string) ([]*Package, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	mode := packages.LoadSyntax
+	if len(sumtype.Analyzer.FactTypes) > 0 {
+		mode = packages.LoadAllSyntax
+	}
+	pcfg := &packages.Config{
+		Mode:       mode | packages.NeedModule,
+		Dir:        cfg.Dir,
+		Tests:      cfg.Tests,
+		BuildFlags: cfg.BuildFlags,
+		Overlay:    cfg.Overlay,
+	}
+	if len(cfg.Env) > 0 {
+		pcfg.Env = append(os.Environ(), cfg.Env...)
+	}
+	pkgs, err := packages.Load(pcfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if n := printErrors(pkgs, cfg.BestEffort); n > 0 {
+		return nil, fmt.Errorf("%d errors while loading packages", n)
+	}
+	if cfg.Shards != 0 {
+		if cfg.Shards < 0 || cfg.Shard < 1 || cfg.Shard > cfg.Shards {
+			return nil, fmt.Errorf("invalid shard %d of %d", cfg.Shard, cfg.Shards)
+		}
+		var shard []*packages.Package
+		for _, pkg := range pkgs {
+			if inShard(pkg.PkgPath, cfg.Shard, cfg.Shards) {
+				shard = append(shard, pkg)
+			}
+		}
+		pkgs = shard
+	}
+	analyzer := sumtype.Analyzer
+	var deps dependencies
+	if fn != nil {
+		analyzer = streaming(cfg, pkgs, fn)
+	} else if cfg.WholeProgram {
+		analyzer = recording(pkgs, &deps)
+	}
+	graph, err := checker.Analyze([]*analysis.Analyzer{analyzer}, pkgs, nil)
+	if err != nil {
+		return nil, err
+	}
+	var results []*Package
+	for _, act := range graph.Roots {
+		if act.Err != nil {
+			return nil, fmt.Errorf("analyzing %s: %v", act.Package.PkgPath, act.Err)
+		}
+		if fn != nil {
+			continue
+		}
+		pkg := &Package{
+			Package: act.Package,
+			Result:  act.Result.(*sumtype.Result),
+		}
+		for _, d := range act.Diagnostics {
+			f := newFinding(pkg.Fset, pkg.PkgPath, pkg.Result, d)
+			suppressed, err := cfg.suppressed(f)
+			if err != nil {
+				return nil, err
+			}
+			if suppressed {
+				continue
+			}
+			pkg.Diagnostics = append(pkg.Diagnostics, d)
+			pkg.Findings = append(pkg.Findings, f)
+		}
+		sortFindings(pkg.Findings)
+		results = append(results, pkg)
+	}
+	if cfg.WholeProgram && fn == nil {
+		gaps, err := upstreamGaps(cfg, results, &deps)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, gaps...)
+	}
+	return results, nil
+}
+
+// printErrors prints the errors of the given packages and their dependencies
+// to stderr, except for type errors if they are tolerated, and returns the
+// number of errors printed.
+func printErrors(pkgs []*packages.Package, typeErrorsOK bool) int {
+	n := 0
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			if typeErrorsOK && err.Kind == packages.TypeError {
+				continue
+			}
+			fmt.Fprintln(os.Stderr, err)
+			n++
+		}
+	})
+	return n
+}
+
+// streaming returns a copy of the go-sumtype analyzer that delivers the
+// findings in each of the given root packages that are not suppressed by cfg
+// to fn once the package has been analyzed, until fn returns false. Packages
+// are then no longer analyzed. Diagnostics are not reported to the driver, so
+// that they are not also retained by it.
+func streaming(cfg *Config, roots []*packages.Package, fn func(Finding) bool) *analysis.Analyzer {
+	isRoot := map[*types.Package]bool{}
+	for _, pkg := range roots {
+		isRoot[pkg.Types] = true
+	}
+	var (
+		mu      sync.Mutex
+		seen    = map[Position]map[string]bool{}
+		stopped bool
+	)
+	a := *sumtype.Analyzer
+	a.Run = func(pass *analysis.Pass) (interface{}, error) {
+		mu.Lock()
+		done := stopped
+		mu.Unlock()
+		if done {
+			return &sumtype.Result{}, nil
+		}
+		var diags []analysis.Diagnostic
+		pass.Report = func(d analysis.Diagnostic) {
+			diags = append(diags, d)
+		}
+		res, err := sumtype.Analyzer.Run(pass)
+		if err != nil || !isRoot[pass.Pkg] {
+			return res, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, d := range diags {
+			if stopped {
+				break
+			}
+			f := newFinding(pass.Fset, pass.Pkg.Path(), res.(*sumtype.Result), d)
+			if seen[f.Position][f.Message] {
+				continue
+			}
+			suppressed, err := cfg.suppressed(f)
+			if err != nil {
+				return nil, err
+			}
+			if suppressed {
+				continue
+			}
+			if seen[f.Position] == nil {
+				seen[f.Position] = map[string]bool{}
+			}
+			seen[f.Position][f.Message] = true
+			stopped = !fn(f)
+		}
+		return res, nil
+	}
+	return &a
+}
+
+// FindSumType returns the sum type with the given name declared in one of
+// the given packages. The name may be qualified with the package's import
+// path, e.g., github.com/foo/ast.Expr. If no such sum type exists, then nil
+// is returned.
+func FindSumType(pkgs []*Package, name string) *sumtype.SumType {
+	for _, pkg := range pkgs {
+		for _, st := range pkg.Result.SumTypes {
+			if name == st.Type.Name() || name == pkg.PkgPath+"."+st.Type.Name() {
+				return st
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,562 @@
+package driver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	pkgs, err := Run(nil, "./testdata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, pkgs); err != nil {
+		t.Fatal(err)
+	}
+
+	// Decode into generic values, so that this test catches changes to the
+	// names of fields in the schema.
+	var report map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if v := report["schemaVersion"]; v != float64(SchemaVersion) {
+		t.Errorf("schemaVersion = %v; want %d", v, SchemaVersion)
+	}
+	findings := report["findings"].([]interface{})
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings; want 1", len(findings))
+	}
+	f := findings[0].(map[string]interface{})
+	pos := f["position"].(map[string]interface{})
+	delete(f, "position")
+	delete(pos, "file")
+	fixes := f["fixes"].([]interface{})
+	delete(f, "fixes")
+	related := f["related"].([]interface{})
+	delete(f, "related")
+	want := map[string]interface{}{
+		"package": "github.com/BurntSushi/go-sumtype/pkg/driver/testdata/a",
+		"check":   "exhaustiveness",
+		"message": "exhaustiveness check failed for sum type 'T': missing cases for B",
+		"type":    "github.com/BurntSushi/go-sumtype/pkg/driver/testdata/a.T",
+		"missing": []interface{}{"B"},
+	}
+	if !reflect.DeepEqual(f, want) {
+		t.Errorf("finding = %#v; want %#v", f, want)
+	}
+	wantPos := map[string]interface{}{"line": float64(16), "column": float64(2)}
+	if !reflect.DeepEqual(pos, wantPos) {
+		t.Errorf("position = %#v; want %#v", pos, wantPos)
+	}
+	if len(related) != 1 || related[0].(map[string]interface{})["message"] != "sum type 'T' declared here" {
+		t.Errorf("related = %#v; want the declaration of T", related)
+	}
+	if len(fixes) != 1 {
+		t.Fatalf("got %d fixes; want 1", len(fixes))
+	}
+	fix := fixes[0].(map[string]interface{})
+	edits := fix["edits"].([]interface{})
+	if len(edits) != 1 {
+		t.Fatalf("got %d edits; want 1", len(edits))
+	}
+	edit := edits[0].(map[string]interface{})
+	if file, _ := edit["file"].(string); filepath.Base(file) != "a.go" {
+		t.Errorf("edit of file %v; want a.go", edit["file"])
+	}
+	delete(edit, "file")
+	wantFix := map[string]interface{}{
+		"message": "Add missing cases for *B",
+		"edits": []interface{}{map[string]interface{}{
+			"start":   float64(186),
+			"end":     float64(186),
+			"newText": "case *B:\n\t\tpanic(\"unhandled\")\n\t",
+		}},
+	}
+	if !reflect.DeepEqual(fix, wantFix) {
+		t.Errorf("fix = %#v; want %#v", fix, wantFix)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	pkgs, err := Run(nil, "./testdata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, pkgs); err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI       string `json:"uri"`
+							URIBaseID string `json:"uriBaseId"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				RelatedLocations []struct {
+					PhysicalLocation struct {
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"relatedLocations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("got version %q with %d runs; want one 2.1.0 run", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if len(run.Results) != 1 {
+		t.Fatalf("got %d results; want 1", len(run.Results))
+	}
+	res := run.Results[0]
+	if res.RuleID != "exhaustiveness" || res.Level != "error" {
+		t.Errorf("result of rule %q at level %q; want exhaustiveness at error", res.RuleID, res.Level)
+	}
+	if rules := run.Tool.Driver.Rules; res.RuleIndex >= len(rules) || rules[res.RuleIndex].ID != res.RuleID {
+		t.Errorf("rule index %d does not refer to rule %q", res.RuleIndex, res.RuleID)
+	}
+	if len(res.Locations) != 1 {
+		t.Fatalf("got %d locations; want 1", len(res.Locations))
+	}
+	loc := res.Locations[0].PhysicalLocation
+	if got, want := loc.ArtifactLocation.URI, "testdata/a/a.go"; got != want {
+		t.Errorf("uri = %q; want %q", got, want)
+	}
+	if loc.ArtifactLocation.URIBaseID != "%SRCROOT%" {
+		t.Errorf("uriBaseId = %q; want %%SRCROOT%%", loc.ArtifactLocation.URIBaseID)
+	}
+	if loc.Region.StartLine != 16 || loc.Region.StartColumn != 2 {
+		t.Errorf("region = %+v; want line 16, column 2", loc.Region)
+	}
+	if len(res.RelatedLocations) != 1 || res.RelatedLocations[0].PhysicalLocation.Region.StartLine != 3 {
+		t.Errorf("related locations = %+v; want the declaration of T on line 3", res.RelatedLocations)
+	}
+}
+
+func TestStream(t *testing.T) {
+	var findings []Finding
+	err := Stream(&Config{Tests: true}, func(f Finding) {
+		findings = append(findings, f)
+	}, "./testdata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings; want 1", len(findings))
+	}
+	if got, want := findings[0].Missing, []string{"B"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("missing = %v; want %v", got, want)
+	}
+}
+
+func TestStreamUntil(t *testing.T) {
+	n := 0
+	err := StreamUntil(&Config{Tests: true}, func(f Finding) bool {
+		n++
+		return false
+	}, "./testdata/a", "./testdata/line")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("got %d findings; want 1", n)
+	}
+}
+
+func TestNewMetrics(t *testing.T) {
+	pkgs, err := Run(nil, "./testdata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := NewMetrics(pkgs)
+	want := &Metrics{
+		Packages:        1,
+		SumTypes:        1,
+		Variants:        2,
+		Switches:        1,
+		Findings:        1,
+		FindingsByCheck: map[string]int{"exhaustiveness": 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("metrics = %+v; want %+v", got, want)
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	path, err := filepath.Abs("testdata/a/a.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src = bytes.Replace(src, []byte("case *A:"), []byte("case *A, *B:"), 1)
+	cfg := &Config{Overlay: map[string][]byte{path: src}}
+	pkgs, err := Run(cfg, "./testdata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings := Findings(pkgs); len(findings) != 0 {
+		t.Errorf("got findings %v; want none", findings)
+	}
+
+	// Directives are read from the overlay too.
+	src = bytes.Replace(src, []byte("//go-sumtype:decl T"), []byte("// T is not a sum type."), 1)
+	src = bytes.Replace(src, []byte("case *A, *B:"), []byte("case *A:"), 1)
+	cfg = &Config{Overlay: map[string][]byte{path: src}}
+	pkgs, err = Run(cfg, "./testdata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings := Findings(pkgs); len(findings) != 0 {
+		t.Errorf("got findings %v; want none", findings)
+	}
+}
+
+func TestBestEffort(t *testing.T) {
+	path, err := filepath.Abs("testdata/a/a.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The buffer is in the middle of an edit and doesn't type-check.
+	src = bytes.Replace(src, []byte("case *A:"), []byte("case *A:\n\t\tundefined()"), 1)
+	cfg := &Config{Overlay: map[string][]byte{path: src}}
+	if _, err := Run(cfg, "./testdata/a"); err == nil {
+		t.Fatal("got no error for a package that doesn't type-check")
+	}
+
+	cfg.BestEffort = true
+	pkgs, err := Run(cfg, "./testdata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings := Findings(pkgs); len(findings) != 1 || !reflect.DeepEqual(findings[0].Missing, []string{"B"}) {
+		t.Errorf("got findings %v; want one missing B", findings)
+	}
+}
+
+func TestLineDirectives(t *testing.T) {
+	pkgs, err := Run(nil, "./testdata/line")
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := Findings(pkgs)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings; want 1", len(findings))
+	}
+	want, err := filepath.Abs("testdata/line/t.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := findings[0].Position; got != (Position{File: want, Line: 7, Column: 2}) {
+		t.Errorf("finding at %+v; want the position in the template", got)
+	}
+}
+
+func TestReadOverlay(t *testing.T) {
+	src, err := os.ReadFile("testdata/a/a.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	replacement := filepath.Join(dir, "a.go")
+	src = bytes.Replace(src, []byte("case *A:"), []byte("case *A, *B:"), 1)
+	if err := os.WriteFile(replacement, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(map[string]any{
+		"Replace": map[string]string{"testdata/a/a.go": replacement},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlayPath := filepath.Join(dir, "overlay.json")
+	if err := os.WriteFile(overlayPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := ReadOverlay(overlayPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs, err := Run(&Config{Overlay: overlay}, "./testdata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings := Findings(pkgs); len(findings) != 0 {
+		t.Errorf("got findings %v; want none", findings)
+	}
+
+	data = []byte(`{"Replace": {"testdata/a/a.go": ""}}`)
+	if err := os.WriteFile(overlayPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadOverlay(overlayPath); err == nil {
+		t.Errorf("got no error for an overlay deleting a file")
+	}
+}
+
+func TestBuildFlags(t *testing.T) {
+	for _, tc := range []struct {
+		flags    []string
+		findings int
+	}{
+		{nil, 0},
+		{[]string{"-tags=integration"}, 1},
+	} {
+		pkgs, err := Run(&Config{BuildFlags: tc.flags}, "./testdata/tags")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(Findings(pkgs)); got != tc.findings {
+			t.Errorf("%v: got %d findings; want %d", tc.flags, got, tc.findings)
+		}
+	}
+}
+
+func TestEnv(t *testing.T) {
+	pkgs, err := Run(&Config{Env: []string{"GOOS=windows"}}, "./testdata/tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := Findings(pkgs)
+	if len(findings) != 1 || !reflect.DeepEqual(findings[0].Missing, []string{"C"}) {
+		t.Errorf("got findings %v; want one missing C", findings)
+	}
+}
+
+func TestRunConfigs(t *testing.T) {
+	cfg := &Config{BuildFlags: []string{"-tags=integration"}}
+	configs := []string{"linux/amd64", "windows/amd64", "darwin/arm64"}
+	pkgs, err := RunConfigs(cfg, configs, "./testdata/tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][]string
+	for _, f := range Findings(pkgs) {
+		got = append(got, append(f.Missing, f.Configs...))
+	}
+	want := [][]string{
+		{"B", "linux/amd64", "darwin/arm64"},
+		{"B", "C", "windows/amd64"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got missing and configs %v; want %v", got, want)
+	}
+
+	if _, err := RunConfigs(nil, []string{"linux"}, "./testdata/tags"); err == nil {
+		t.Errorf("got no error for an invalid build configuration")
+	}
+}
+
+func TestWholeProgram(t *testing.T) {
+	patterns := []string{"./testdata/upstream/app", "./testdata/upstream/lib"}
+	pkgs, err := Run(nil, patterns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings := Findings(pkgs); len(findings) != 0 {
+		t.Errorf("got findings %v; want none", findings)
+	}
+
+	pkgs, err = Run(&Config{WholeProgram: true}, patterns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := Findings(pkgs)
+	if len(findings) != 1 {
+		t.Fatalf("got findings %v; want one", findings)
+	}
+	f := findings[0]
+	if f.Check != UpstreamGapCheck || !f.Informational || !strings.HasSuffix(f.Package, "/plugin") ||
+		!reflect.DeepEqual(f.Missing, []string{"B"}) {
+		t.Errorf("got finding %+v; want an informational upstream gap in plugin missing B", f)
+	}
+}
+
+func TestWorkspace(t *testing.T) {
+	work, err := filepath.Abs("testdata/work/go.work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		Dir: filepath.Dir(work),
+		// -mod=mod, which may be set in GOFLAGS, is not allowed in
+		// workspace mode.
+		Env: []string{"GOWORK=" + work, "GOFLAGS="},
+	}
+	pkgs, err := Run(cfg, "work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 2 {
+		t.Errorf("got %d packages; want 2", len(pkgs))
+	}
+	findings := Findings(pkgs)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings; want 1", len(findings))
+	}
+	if got, want := findings[0].Package, "example.com/eval"; got != want {
+		t.Errorf("package = %s; want %s", got, want)
+	}
+	if got, want := findings[0].Missing, []string{"Add"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("missing = %v; want %v", got, want)
+	}
+}
+
+func TestShards(t *testing.T) {
+	const shards = 3
+	seen := map[string]int{}
+	for shard := 1; shard <= shards; shard++ {
+		cfg := &Config{Tests: true, Shard: shard, Shards: shards}
+		pkgs, err := Run(cfg, "./testdata/a", "./testdata/tags")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, pkg := range pkgs {
+			path := strings.TrimSuffix(pkg.PkgPath, "_test")
+			if s, ok := seen[path]; ok && s != shard {
+				t.Errorf("%s is in shards %d and %d", pkg.ID, s, shard)
+			}
+			seen[path] = shard
+		}
+	}
+	for _, path := range []string{
+		"github.com/BurntSushi/go-sumtype/pkg/driver/testdata/a",
+		"github.com/BurntSushi/go-sumtype/pkg/driver/testdata/tags",
+	} {
+		if _, ok := seen[path]; !ok {
+			t.Errorf("%s is in no shard", path)
+		}
+	}
+
+	if _, err := Run(&Config{Shard: 4, Shards: shards}, "./testdata/a"); err == nil {
+		t.Errorf("shard 4 of %d did not fail", shards)
+	}
+	if _, err := Run(&Config{Shard: 2, Shards: -1}, "./testdata/a"); err == nil {
+		t.Errorf("shard 2 of -1 did not fail")
+	}
+}
+
+func TestSuppressions(t *testing.T) {
+	var asked []string
+	suppressions := SuppressionFunc(func(f Finding) (bool, error) {
+		asked = append(asked, f.Message)
+		return f.Type == "github.com/BurntSushi/go-sumtype/pkg/driver/testdata/a.T", nil
+	})
+	pkgs, err := Run(&Config{Suppressions: suppressions}, "./testdata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(asked) != 1 {
+		t.Errorf("asked about %d findings; want 1", len(asked))
+	}
+	if findings := Findings(pkgs); len(findings) != 0 {
+		t.Errorf("got %d findings; want 0", len(findings))
+	}
+	if len(pkgs[0].Diagnostics) != 0 {
+		t.Errorf("got %d diagnostics; want 0", len(pkgs[0].Diagnostics))
+	}
+
+	err = Stream(&Config{Suppressions: suppressions}, func(f Finding) {
+		t.Errorf("suppressed finding was streamed: %s", f.Message)
+	}, "./testdata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failing := SuppressionFunc(func(f Finding) (bool, error) {
+		return false, fmt.Errorf("exemption service unavailable")
+	})
+	if _, err := Run(&Config{Suppressions: failing}, "./testdata/a"); err == nil {
+		t.Errorf("failing suppression provider did not fail the run")
+	}
+}
+
+func TestMergeReports(t *testing.T) {
+	a := Finding{Position: Position{File: "a.go", Line: 1, Column: 2}, Message: "a"}
+	b := Finding{Position: Position{File: "b.go", Line: 3, Column: 4}, Message: "b"}
+	dir := t.TempDir()
+	var paths []string
+	for i, r := range []*Report{
+		{SchemaVersion: SchemaVersion, Findings: []Finding{b, a}},
+		{SchemaVersion: SchemaVersion, Findings: []Finding{a}},
+		{SchemaVersion: SchemaVersion},
+	} {
+		var buf bytes.Buffer
+		if err := r.Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("shard%d.json", i))
+		if err := os.WriteFile(path, buf.Bytes(), 0666); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	var reports []*Report
+	for _, path := range paths {
+		r, err := ReadReport(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reports = append(reports, r)
+	}
+	got := MergeReports(reports...).Findings
+	if want := []Finding{a, b}; !reflect.DeepEqual(got, want) {
+		t.Errorf("merged findings = %v; want %v", got, want)
+	}
+
+	linux, darwin := a, a
+	linux.Configs = []string{"linux/amd64"}
+	darwin.Configs = []string{"darwin/arm64", "linux/amd64"}
+	got = MergeReports(
+		&Report{SchemaVersion: SchemaVersion, Findings: []Finding{linux}},
+		&Report{SchemaVersion: SchemaVersion, Findings: []Finding{darwin}},
+	).Findings
+	if want := []string{"linux/amd64", "darwin/arm64"}; len(got) != 1 || !reflect.DeepEqual(got[0].Configs, want) {
+		t.Errorf("merged findings = %v; want one with configs %v", got, want)
+	}
+
+	path := filepath.Join(dir, "old.json")
+	os.WriteFile(path, []byte(`{"schemaVersion": 0, "findings": []}`), 0666)
+	if _, err := ReadReport(path); err == nil {
+		t.Errorf("reading a report with schema version 0 did not fail")
+	}
+}
@@ -0,0 +1,228 @@
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+	"golang.org/x/tools/go/analysis"
+)
+
+// SchemaVersion is the version of the JSON report format written by
+// WriteJSON.
+//
+// Within a schema version, fields of Report and Finding are never removed,
+// renamed or given a different meaning. New fields may be added, and they are
+// always optional. Any other change increments the schema version.
+const SchemaVersion = 1
+
+// Report is the JSON report of a run of go-sumtype.
+type Report struct {
+	// SchemaVersion is the version of this format. See SchemaVersion.
+	SchemaVersion int `json:"schemaVersion"`
+	// Findings are the problems found, sorted by position.
+	Findings []Finding `json:"findings"`
+}
+
+// Finding is a single problem found by go-sumtype.
+type Finding struct {
+	// Package is the import path of the package in which the problem was
+	// found.
+	Package string `json:"package"`
+	// Position is where the problem was found.
+	Position Position `json:"position"`
+	// Check is the category of the problem, e.g., "exhaustiveness". See the
+	// Category constants in the sumtype package.
+	Check string `json:"check,omitempty"`
+	// Message is the human readable description of the problem.
+	Message string `json:"message"`
+	// Type is the sum type or enum involved, qualified by its package path.
+	Type string `json:"type,omitempty"`
+	// Missing are the names of the missing variants or enum members.
+	Missing []string `json:"missing,omitempty"`
+	// Configs are the build configurations, as GOOS/GOARCH pairs, in which
+	// the problem was found when packages are analyzed for several of them
+	// with RunConfigs.
+	Configs []string `json:"configs,omitempty"`
+	// Informational is true for findings that are only reported for
+	// information and must not fail a build, e.g., those with the check
+	// UpstreamGapCheck.
+	Informational bool `json:"informational,omitempty"`
+	// Related are other places involved in the problem, e.g., the
+	// declaration of the sum type a switch is missing variants of.
+	Related []Related `json:"related,omitempty"`
+	// Fixes are the fixes suggested for the problem, e.g., inserting the
+	// missing cases of a switch.
+	Fixes []Fix `json:"fixes,omitempty"`
+}
+
+// Related is a place involved in a finding, other than where it was found.
+type Related struct {
+	Position Position `json:"position"`
+	Message  string   `json:"message"`
+}
+
+// Fix is a fix suggested for a finding.
+type Fix struct {
+	// Message describes the fix.
+	Message string `json:"message"`
+	// Edits are the changes making up the fix, which may span several
+	// files.
+	Edits []Edit `json:"edits"`
+}
+
+// Edit is a change to a file that replaces the bytes between two offsets with
+// new text. Offsets are in the file itself, even when line directives map
+// the positions of findings elsewhere.
+type Edit struct {
+	File string `json:"file"`
+	// Start and End are the byte offsets of the replaced text. They are
+	// equal for insertions.
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	NewText string `json:"newText"`
+}
+
+// Position is a position in a Go source file.
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// newPosition converts pos into a Position.
+func newPosition(fset *token.FileSet, pos token.Pos) Position {
+	p := fset.Position(pos)
+	return Position{File: p.Filename, Line: p.Line, Column: p.Column}
+}
+
+// newFinding converts a diagnostic reported for the package with the given
+// path into a finding. Details recorded by the analyzer in res for the
+// diagnostic are included when available.
+func newFinding(
+	fset *token.FileSet,
+	pkgPath string,
+	res *sumtype.Result,
+	d analysis.Diagnostic,
+) Finding {
+	f := Finding{
+		Package:  pkgPath,
+		Position: newPosition(fset, d.Pos),
+		Check:    d.Category,
+		Message:  d.Message,
+	}
+	if details := findDetails(res, d); details != nil {
+		f.Type = details.Type
+		f.Missing = details.Missing
+	}
+	for _, r := range d.Related {
+		f.Related = append(f.Related, Related{Position: newPosition(fset, r.Pos), Message: r.Message})
+	}
+	for _, sf := range d.SuggestedFixes {
+		fix := Fix{Message: sf.Message, Edits: []Edit{}}
+		for _, te := range sf.TextEdits {
+			end := te.End
+			if !end.IsValid() {
+				end = te.Pos
+			}
+			start := fset.PositionFor(te.Pos, false)
+			fix.Edits = append(fix.Edits, Edit{
+				File:    start.Filename,
+				Start:   start.Offset,
+				End:     fset.PositionFor(end, false).Offset,
+				NewText: string(te.NewText),
+			})
+		}
+		f.Fixes = append(f.Fixes, fix)
+	}
+	return f
+}
+
+// findDetails returns the finding recorded by the analyzer for the given
+// diagnostic, or nil if there is none.
+func findDetails(res *sumtype.Result, d analysis.Diagnostic) *sumtype.Finding {
+	for _, f := range res.Findings {
+		if f.Diagnostic.Pos == d.Pos && f.Diagnostic.Message == d.Message {
+			return f
+		}
+	}
+	return nil
+}
+
+// sortFindings sorts findings by file, line and column.
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i].Position, findings[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// WriteJSON writes a JSON report of the findings in the given packages.
+func WriteJSON(w io.Writer, pkgs []*Package) error {
+	report := &Report{SchemaVersion: SchemaVersion, Findings: Findings(pkgs)}
+	return report.Write(w)
+}
+
+// Write writes the report as indented JSON.
+func (r *Report) Write(w io.Writer) error {
+	if r.Findings == nil {
+		r.Findings = []Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// ReadReport reads a JSON report, as written by WriteJSON, from the file at
+// path.
+func ReadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("parsing report '%s': %v", path, err)
+	}
+	if r.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("report '%s' has schema version %d, expected %d",
+			path, r.SchemaVersion, SchemaVersion)
+	}
+	return r, nil
+}
+
+// MergeReports combines the given reports, e.g., of separate shards of the
+// same packages, into one. Findings that are in more than one report, e.g.,
+// because of a package analyzed in several shards, are only included once,
+// labeled with every build configuration they were found in.
+func MergeReports(reports ...*Report) *Report {
+	type key struct {
+		pos     Position
+		message string
+	}
+	seen := map[key]int{}
+	merged := &Report{SchemaVersion: SchemaVersion, Findings: []Finding{}}
+	for _, r := range reports {
+		for _, f := range r.Findings {
+			k := key{f.Position, f.Message}
+			if i, ok := seen[k]; ok {
+				merged.Findings[i].Configs = mergeConfigs(merged.Findings[i].Configs, f.Configs)
+				continue
+			}
+			seen[k] = len(merged.Findings)
+			merged.Findings = append(merged.Findings, f)
+		}
+	}
+	sortFindings(merged.Findings)
+	return merged
+}
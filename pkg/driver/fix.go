@@ -0,0 +1,72 @@
+package driver
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ApplyFixes applies the first suggested fix of every diagnostic in the given
+// packages to the files on disk. Edits that overlap an edit that has already
+// been accepted are skipped, as are duplicate edits reported for a package
+// and its test variant.
+func ApplyFixes(pkgs []*Package) error {
+	type edit struct {
+		start, end int
+		text       []byte
+	}
+	edits := map[string][]edit{}
+	for _, pkg := range pkgs {
+		for _, d := range pkg.Diagnostics {
+			if len(d.SuggestedFixes) == 0 {
+				continue
+			}
+			for _, te := range d.SuggestedFixes[0].TextEdits {
+				end := te.End
+				if !end.IsValid() {
+					end = te.Pos
+				}
+				// Edits apply to the files themselves, not to those
+				// line directives map them to.
+				start := pkg.Fset.PositionFor(te.Pos, false)
+				edits[start.Filename] = append(edits[start.Filename], edit{
+					start: start.Offset,
+					end:   pkg.Fset.PositionFor(end, false).Offset,
+					text:  te.NewText,
+				})
+			}
+		}
+	}
+	for filename, fileEdits := range edits {
+		sort.SliceStable(fileEdits, func(i, j int) bool {
+			return fileEdits[i].start < fileEdits[j].start
+		})
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		last := 0
+		var prev *edit
+		for i := range fileEdits {
+			e := &fileEdits[i]
+			if prev != nil && e.start == prev.start && e.end == prev.end &&
+				bytes.Equal(e.text, prev.text) {
+				continue
+			}
+			if e.start < last || e.end > len(src) {
+				continue
+			}
+			out.Write(src[last:e.start])
+			out.Write(e.text)
+			last = e.end
+			prev = e
+		}
+		out.Write(src[last:])
+		if err := os.WriteFile(filename, out.Bytes(), 0666); err != nil {
+			return fmt.Errorf("applying fixes: %v", err)
+		}
+	}
+	return nil
+}
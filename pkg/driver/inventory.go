@@ -0,0 +1,256 @@
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// InventoryVersion is the version of the JSON format of an Inventory. It is
+// incremented under the same rules as SchemaVersion.
+const InventoryVersion = 1
+
+// LockCheck is the check of findings reported by CheckLock.
+const LockCheck = "lock"
+
+// Inventory is a snapshot of the sum types declared in a set of packages and
+// their variants. It is written by `go-sumtype lock`, so that changes to the
+// variants of sum types can be detected and reviewed.
+type Inventory struct {
+	// SchemaVersion is the version of this format. See InventoryVersion.
+	SchemaVersion int `json:"schemaVersion"`
+	// SumTypes are the sum types, sorted by type.
+	SumTypes []InventorySumType `json:"sumTypes"`
+}
+
+// InventorySumType is a single sum type in an inventory.
+type InventorySumType struct {
+	// Type is the name of the sum type, qualified by its package path.
+	Type string `json:"type"`
+	// Variants are the names of the variants of the sum type, sorted.
+	Variants []string `json:"variants"`
+}
+
+// InventoryChange is a change to a sum type between two inventories.
+type InventoryChange struct {
+	// Type is the name of the sum type, qualified by its package path.
+	Type string
+	// Added is true if the sum type was added, and Removed is true if it
+	// was removed. If neither is true, then its variants changed.
+	Added, Removed bool
+	// AddedVariants and RemovedVariants are the names of the variants that
+	// were added and removed, sorted.
+	AddedVariants, RemovedVariants []string
+}
+
+// String returns a human readable description of the change to the variants
+// of the sum type, e.g., "added C; removed D".
+func (c InventoryChange) String() string {
+	var parts []string
+	if len(c.AddedVariants) > 0 {
+		parts = append(parts, "added "+strings.Join(c.AddedVariants, ", "))
+	}
+	if len(c.RemovedVariants) > 0 {
+		parts = append(parts, "removed "+strings.Join(c.RemovedVariants, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// NewInventory returns an inventory of the sum types declared in the given
+// packages. Sum types declared in both a package and its test variant are
+// only included once.
+func NewInventory(pkgs []*Package) *Inventory {
+	inv := &Inventory{SchemaVersion: InventoryVersion, SumTypes: []InventorySumType{}}
+	seen := map[string]bool{}
+	for _, pkg := range pkgs {
+		for _, st := range pkg.Result.SumTypes {
+			name := st.Type.Pkg().Path() + "." + st.Type.Name()
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			variants := []string{}
+			for _, v := range st.Variants {
+				variants = append(variants, v.Name())
+			}
+			sort.Strings(variants)
+			inv.SumTypes = append(inv.SumTypes, InventorySumType{
+				Type:     name,
+				Variants: variants,
+			})
+		}
+	}
+	sort.Slice(inv.SumTypes, func(i, j int) bool {
+		return inv.SumTypes[i].Type < inv.SumTypes[j].Type
+	})
+	return inv
+}
+
+// ReadInventory reads an inventory from the file at path.
+func ReadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	inv := &Inventory{}
+	if err := json.Unmarshal(data, inv); err != nil {
+		return nil, fmt.Errorf("parsing inventory '%s': %v", path, err)
+	}
+	if inv.SchemaVersion != InventoryVersion {
+		return nil, fmt.Errorf("inventory '%s' has schema version %d, expected %d",
+			path, inv.SchemaVersion, InventoryVersion)
+	}
+	return inv, nil
+}
+
+// Write writes the inventory as indented JSON.
+func (inv *Inventory) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(inv)
+}
+
+// DiffInventories returns the changes to sum types from old to new, sorted by
+// type.
+func DiffInventories(old, new *Inventory) []InventoryChange {
+	oldTypes := map[string][]string{}
+	for _, st := range old.SumTypes {
+		oldTypes[st.Type] = st.Variants
+	}
+	newTypes := map[string][]string{}
+	for _, st := range new.SumTypes {
+		newTypes[st.Type] = st.Variants
+	}
+
+	var changes []InventoryChange
+	for _, st := range new.SumTypes {
+		oldVariants, ok := oldTypes[st.Type]
+		if !ok {
+			changes = append(changes, InventoryChange{
+				Type:          st.Type,
+				Added:         true,
+				AddedVariants: st.Variants,
+			})
+			continue
+		}
+		added, removed := difference(st.Variants, oldVariants), difference(oldVariants, st.Variants)
+		if len(added) > 0 || len(removed) > 0 {
+			changes = append(changes, InventoryChange{
+				Type:            st.Type,
+				AddedVariants:   added,
+				RemovedVariants: removed,
+			})
+		}
+	}
+	for _, st := range old.SumTypes {
+		if _, ok := newTypes[st.Type]; !ok {
+			changes = append(changes, InventoryChange{
+				Type:            st.Type,
+				Removed:         true,
+				RemovedVariants: st.Variants,
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Type < changes[j].Type })
+	return changes
+}
+
+// difference returns the names in a that are not in b, in the order in which
+// they appear in a.
+func difference(a, b []string) []string {
+	inB := map[string]bool{}
+	for _, name := range b {
+		inB[name] = true
+	}
+	var diff []string
+	for _, name := range a {
+		if !inB[name] {
+			diff = append(diff, name)
+		}
+	}
+	return diff
+}
+
+// CheckLock adds a finding to the given packages for every sum type whose
+// variants differ from those recorded in lock, and for every switch over such
+// a sum type, since each of them must be revisited. New sum types that are
+// not in the lock are also reported, and so are sum types in the lock that
+// were removed: at the package clause of their package if it was loaded, and
+// at the lock file otherwise, e.g., if their package was deleted. The lock
+// must therefore be written for the same packages as those checked. lockName
+// is the name of the lock file used in messages and positions.
+func CheckLock(pkgs []*Package, lock *Inventory, lockName string) {
+	changes := map[string]InventoryChange{}
+	for _, c := range DiffInventories(lock, NewInventory(pkgs)) {
+		changes[c.Type] = c
+		if c.Removed {
+			reportRemoved(pkgs, c, lockName)
+		}
+	}
+	for _, pkg := range pkgs {
+		for _, st := range pkg.Result.SumTypes {
+			name := st.Type.Pkg().Path() + "." + st.Type.Name()
+			c, ok := changes[name]
+			if !ok {
+				continue
+			}
+			msg := fmt.Sprintf("variants of sum type '%s' changed since %s: %s",
+				st.Type.Name(), lockName, c)
+			if c.Added {
+				msg = fmt.Sprintf("sum type '%s' is not in %s", st.Type.Name(), lockName)
+			}
+			pkg.Findings = append(pkg.Findings, Finding{
+				Package:  pkg.PkgPath,
+				Position: newPosition(pkg.Fset, st.Decl),
+				Check:    LockCheck,
+				Message:  msg,
+				Type:     name,
+			})
+		}
+		for _, sw := range pkg.Result.Switches {
+			st := sw.SumType.Type
+			c, ok := changes[st.Pkg().Path()+"."+st.Name()]
+			if !ok || c.Added {
+				continue
+			}
+			pkg.Findings = append(pkg.Findings, Finding{
+				Package:  pkg.PkgPath,
+				Position: newPosition(pkg.Fset, sw.Stmt.Pos()),
+				Check:    LockCheck,
+				Message: fmt.Sprintf("switch over sum type '%s' must be revisited, "+
+					"its variants changed since %s: %s", st.Name(), lockName, c),
+				Type: c.Type,
+			})
+		}
+		sortFindings(pkg.Findings)
+	}
+}
+
+// reportRemoved adds a finding for the given removed sum type to the package
+// that declared it, or to the first package if it wasn't loaded.
+func reportRemoved(pkgs []*Package, c InventoryChange, lockName string) {
+	if len(pkgs) == 0 {
+		return
+	}
+	i := strings.LastIndex(c.Type, ".")
+	path, name := c.Type[:i], c.Type[i+1:]
+	f := Finding{
+		Package:  path,
+		Position: Position{File: lockName},
+		Check:    LockCheck,
+		Message:  fmt.Sprintf("sum type '%s' was removed since %s", name, lockName),
+		Type:     c.Type,
+	}
+	pkg := pkgs[0]
+	for _, p := range pkgs {
+		if p.PkgPath == path && len(p.Syntax) > 0 {
+			pkg = p
+			f.Position = newPosition(p.Fset, p.Syntax[0].Name.Pos())
+			break
+		}
+	}
+	pkg.Findings = append(pkg.Findings, f)
+}
@@ -0,0 +1,57 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffInventories(t *testing.T) {
+	old := &Inventory{SumTypes: []InventorySumType{
+		{Type: "p.Changed", Variants: []string{"A", "B"}},
+		{Type: "p.Removed", Variants: []string{"A"}},
+		{Type: "p.Same", Variants: []string{"A"}},
+	}}
+	new := &Inventory{SumTypes: []InventorySumType{
+		{Type: "p.Added", Variants: []string{"A"}},
+		{Type: "p.Changed", Variants: []string{"B", "C"}},
+		{Type: "p.Same", Variants: []string{"A"}},
+	}}
+	want := []InventoryChange{
+		{Type: "p.Added", Added: true, AddedVariants: []string{"A"}},
+		{Type: "p.Changed", AddedVariants: []string{"C"}, RemovedVariants: []string{"A"}},
+		{Type: "p.Removed", Removed: true, RemovedVariants: []string{"A"}},
+	}
+	if got := DiffInventories(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffInventories = %+v; want %+v", got, want)
+	}
+}
+
+func TestCheckLock(t *testing.T) {
+	pkgs, err := Run(nil, "./testdata/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := NewInventory(pkgs)
+	lock.SumTypes[0].Variants = []string{"A"}
+	lock.SumTypes = append(lock.SumTypes,
+		InventorySumType{Type: lock.SumTypes[0].Type + "Old", Variants: []string{"C"}},
+		InventorySumType{Type: "example.com/gone.U", Variants: []string{"D"}},
+	)
+	CheckLock(pkgs, lock, "sumtype.lock")
+
+	var got []string
+	for _, f := range Findings(pkgs) {
+		if f.Check == LockCheck {
+			got = append(got, f.Message)
+		}
+	}
+	want := []string{
+		"sum type 'TOld' was removed since sumtype.lock",
+		"variants of sum type 'T' changed since sumtype.lock: added B",
+		"switch over sum type 'T' must be revisited, its variants changed since sumtype.lock: added B",
+		"sum type 'U' was removed since sumtype.lock",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lock findings = %q; want %q", got, want)
+	}
+}
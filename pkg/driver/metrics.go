@@ -0,0 +1,101 @@
+package driver
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Metrics are aggregate counts describing a run of go-sumtype, meant to be
+// recorded per run so that the adoption and health of exhaustiveness checks
+// can be tracked over time.
+type Metrics struct {
+	// Packages is the number of packages analyzed.
+	Packages int `json:"packages"`
+	// SumTypes is the number of declared sum types.
+	SumTypes int `json:"sumTypes"`
+	// Variants is the total number of variants of all sum types.
+	Variants int `json:"variants"`
+	// Switches is the number of type switches over sum types.
+	Switches int `json:"switches"`
+	// ExhaustiveSwitches is the number of type switches over sum types that
+	// handle every variant.
+	ExhaustiveSwitches int `json:"exhaustiveSwitches"`
+	// Findings is the number of problems found.
+	Findings int `json:"findings"`
+	// FindingsByCheck is the number of problems found for each check, e.g.,
+	// "exhaustiveness".
+	FindingsByCheck map[string]int `json:"findingsByCheck"`
+}
+
+// NewMetrics computes metrics for the given packages. Sum types and switches
+// that appear in both a package and its test variant are only counted once.
+func NewMetrics(pkgs []*Package) *Metrics {
+	m := &Metrics{FindingsByCheck: map[string]int{}}
+	seenPkgs := map[string]bool{}
+	seen := map[Position]bool{}
+	for _, pkg := range pkgs {
+		if !seenPkgs[pkg.PkgPath] {
+			seenPkgs[pkg.PkgPath] = true
+			m.Packages++
+		}
+		for _, st := range pkg.Result.SumTypes {
+			pos := newPosition(pkg.Fset, st.Type.Pos())
+			if seen[pos] {
+				continue
+			}
+			seen[pos] = true
+			m.SumTypes++
+			m.Variants += len(st.Variants)
+		}
+		for _, sw := range pkg.Result.Switches {
+			pos := newPosition(pkg.Fset, sw.Stmt.Pos())
+			if seen[pos] {
+				continue
+			}
+			seen[pos] = true
+			m.Switches++
+			if len(sw.Missing) == 0 {
+				m.ExhaustiveSwitches++
+			}
+		}
+	}
+	for _, f := range Findings(pkgs) {
+		m.Findings++
+		m.FindingsByCheck[f.Check]++
+	}
+	return m
+}
+
+// WriteCSV writes the metrics as a CSV file with a header row naming each
+// metric and a single row of values. Counts of findings by check are named
+// "findings.<check>".
+func (m *Metrics) WriteCSV(w io.Writer) error {
+	header := []string{
+		"packages", "sumTypes", "variants",
+		"switches", "exhaustiveSwitches", "findings",
+	}
+	row := []int{
+		m.Packages, m.SumTypes, m.Variants,
+		m.Switches, m.ExhaustiveSwitches, m.Findings,
+	}
+	var checks []string
+	for check := range m.FindingsByCheck {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+	for _, check := range checks {
+		header = append(header, "findings."+check)
+		row = append(row, m.FindingsByCheck[check])
+	}
+	var values []string
+	for _, v := range row {
+		values = append(values, strconv.Itoa(v))
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.Write(values)
+	cw.Flush()
+	return cw.Error()
+}
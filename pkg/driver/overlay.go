@@ -0,0 +1,46 @@
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ReadOverlay reads an overlay file in the JSON format accepted by the
+// -overlay flag of go build and by gopls, i.e.,
+//
+//	{"Replace": {"path/to/x.go": "path/to/replacement.go"}}
+//
+// and returns the overlay it describes, for Config.Overlay: each replaced file
+// is mapped to the contents of its replacement. Relative paths are resolved
+// against the current directory, as go build does. Deleting a file, with an
+// empty replacement, is not supported.
+func ReadOverlay(path string) (map[string][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Replace map[string]string
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing overlay '%s': %v", path, err)
+	}
+	overlay := map[string][]byte{}
+	for from, to := range file.Replace {
+		if to == "" {
+			return nil, fmt.Errorf("overlay '%s' deletes '%s', which is not supported", path, from)
+		}
+		abs, err := filepath.Abs(from)
+		if err != nil {
+			return nil, err
+		}
+		contents, err := os.ReadFile(to)
+		if err != nil {
+			return nil, fmt.Errorf("reading overlay '%s': %v", path, err)
+		}
+		overlay[abs] = contents
+	}
+	return overlay, nil
+}
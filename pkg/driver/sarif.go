@@ -0,0 +1,216 @@
+package driver
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// sarifRules describes the checks of go-sumtype as SARIF rules, in the order
+// they are listed in reports.
+var sarifRules = []sarifRule{
+	{
+		ID:    sumtype.CategoryExhaustiveness,
+		Name:  "Exhaustiveness",
+		Short: "A switch does not handle every variant of a sum type or every member of an enum.",
+		Full: "Every type switch over a sum type declared with //go-sumtype:decl must have a case " +
+			"for each of its variants, or a default clause that panics, so that adding a variant " +
+			"can't leave a switch silently ignoring it. The same applies to switches over enums.",
+	},
+	{ID: sumtype.CategoryEnumDefault, Name: "EnumDefault",
+		Short: "An enum switch lacks a default clause handling values outside of the enum."},
+	{ID: sumtype.CategoryDefaultPanic, Name: "DefaultPanic",
+		Short: "The panicking default clause of a switch does not mention the switched value."},
+	{ID: sumtype.CategoryBinding, Name: "Binding",
+		Short: "A switch refers to the switched variable in its cases without binding it."},
+	{ID: sumtype.CategoryNilCase, Name: "NilCase",
+		Short: "A switch over a sum type lacks a required case for nil."},
+	{ID: sumtype.CategoryDefaultPolicy, Name: "DefaultPolicy",
+		Short: "A default clause of a switch over a sum type is forbidden or does not panic as required."},
+	{ID: sumtype.CategoryRegistry, Name: "Registry",
+		Short: "A handler registry does not register every variant of a sum type."},
+	{ID: sumtype.CategoryRequire, Name: "Require",
+		Short: "A variant does not implement an interface required of the variants of its sum type."},
+	{ID: sumtype.CategoryEmbedding, Name: "Embedding",
+		Short: "A variant embeds its sum type, which unseals it."},
+	{ID: sumtype.CategoryEscape, Name: "Escape",
+		Short: "A value of a sum type escapes into an empty interface in an exported API."},
+	{ID: sumtype.CategoryMatcher, Name: "Matcher",
+		Short: "A matcher lacks a field for a variant, or a literal of it leaves one nil."},
+	{ID: sumtype.CategoryConstructs, Name: "Constructs",
+		Short: "A factory function never constructs a variant."},
+	{ID: sumtype.CategoryWrappedError, Name: "WrappedError",
+		Short: "A switch over a sum type of errors may see wrapped variants that match no case."},
+	{ID: sumtype.CategoryUnhandledVariant, Name: "UnhandledVariant",
+		Short: "A variant is not handled by a switch over its sum type."},
+	{ID: sumtype.CategoryDispatchTable, Name: "DispatchTable",
+		Short: "A dispatch table keyed by a bitflag enum is missing flags."},
+	{ID: sumtype.CategoryLookupTable, Name: "LookupTable",
+		Short: "An array indexed by an enum is missing members."},
+	{ID: sumtype.CategoryDeclaration, Name: "Declaration",
+		Short: "A go-sumtype directive is invalid."},
+	{ID: LockCheck, Name: "Lock",
+		Short: "The variants of a sum type changed since the lock file was written."},
+	{ID: UpstreamGapCheck, Name: "UpstreamGap",
+		Short: "A switch in a dependency is not exhaustive over a sum type of the analyzed packages.",
+		Level: "note"},
+}
+
+// WriteSARIF writes the findings in the given packages as a SARIF 2.1.0 log,
+// e.g., for GitHub code scanning. Each finding is a result at the position
+// where it was found, with its related positions, such as the declaration of
+// its sum type, as related locations. Files under the current directory are
+// given relative to the %SRCROOT% base.
+func WriteSARIF(w io.Writer, pkgs []*Package) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	run := sarifRun{Results: []sarifResult{}}
+	run.Tool.Driver = sarifDriver{
+		Name:           "go-sumtype",
+		InformationURI: "https://github.com/BurntSushi/go-sumtype",
+	}
+	index := map[string]int{}
+	for _, rule := range sarifRules {
+		index[rule.ID] = len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+	}
+	for _, f := range Findings(pkgs) {
+		if _, ok := index[f.Check]; !ok {
+			index[f.Check] = len(run.Tool.Driver.Rules)
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: f.Check})
+		}
+		res := sarifResult{
+			RuleID:    f.Check,
+			RuleIndex: index[f.Check],
+			Level:     "error",
+			Message:   sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{sarifLocationOf(cwd, f.Position, "")},
+		}
+		if f.Informational {
+			res.Level = "note"
+		}
+		for i, r := range f.Related {
+			loc := sarifLocationOf(cwd, r.Position, r.Message)
+			loc.ID = i + 1
+			res.RelatedLocations = append(res.RelatedLocations, loc)
+		}
+		if f.Type != "" {
+			res.Properties = map[string]interface{}{"type": f.Type, "missing": f.Missing}
+		}
+		run.Results = append(run.Results, res)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifRule is the metadata of a check.
+type sarifRule struct {
+	ID, Name, Short, Full string
+	// Level is the default level of the results of the check, "error" if
+	// empty.
+	Level string
+}
+
+// MarshalJSON encodes the rule as a SARIF reporting descriptor.
+func (r sarifRule) MarshalJSON() ([]byte, error) {
+	level := r.Level
+	if level == "" {
+		level = "error"
+	}
+	desc := map[string]interface{}{
+		"id":                   r.ID,
+		"defaultConfiguration": map[string]string{"level": level},
+		"helpUri":              "https://github.com/BurntSushi/go-sumtype",
+	}
+	if r.Name != "" {
+		desc["name"] = r.Name
+	}
+	if r.Short != "" {
+		desc["shortDescription"] = sarifMessage{Text: r.Short}
+	}
+	if r.Full != "" {
+		desc["fullDescription"] = sarifMessage{Text: r.Full}
+	}
+	return json.Marshal(desc)
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver sarifDriver `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifResult struct {
+	RuleID           string                 `json:"ruleId"`
+	RuleIndex        int                    `json:"ruleIndex"`
+	Level            string                 `json:"level"`
+	Message          sarifMessage           `json:"message"`
+	Locations        []sarifLocation        `json:"locations"`
+	RelatedLocations []sarifLocation        `json:"relatedLocations,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               int                   `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	} `json:"artifactLocation"`
+	Region struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	} `json:"region"`
+}
+
+// sarifLocationOf returns the SARIF location of pos, with the given message
+// if it is not empty. Files under cwd are relative to %SRCROOT%, and others
+// are file URIs.
+func sarifLocationOf(cwd string, pos Position, msg string) sarifLocation {
+	var loc sarifLocation
+	art := &loc.PhysicalLocation.ArtifactLocation
+	if rel, err := filepath.Rel(cwd, pos.File); err == nil && !strings.HasPrefix(rel, "..") {
+		art.URI = filepath.ToSlash(rel)
+		art.URIBaseID = "%SRCROOT%"
+	} else {
+		art.URI = "file://" + filepath.ToSlash(pos.File)
+	}
+	loc.PhysicalLocation.Region.StartLine = pos.Line
+	loc.PhysicalLocation.Region.StartColumn = pos.Column
+	if msg != "" {
+		loc.Message = &sarifMessage{Text: msg}
+	}
+	return loc
+}
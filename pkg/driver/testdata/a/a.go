@@ -0,0 +1,19 @@
+package a
+
+//go-sumtype:decl T
+
+type T interface{ sealed() }
+
+type A struct{}
+
+func (*A) sealed() {}
+
+type B struct{}
+
+func (*B) sealed() {}
+
+func f(t T) {
+	switch t.(type) {
+	case *A:
+	}
+}
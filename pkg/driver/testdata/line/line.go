@@ -0,0 +1,20 @@
+package line
+
+//go-sumtype:decl T
+
+type T interface{ sealed() }
+
+type A struct{}
+
+func (*A) sealed() {}
+
+type B struct{}
+
+func (*B) sealed() {}
+
+func f(t T) {
+//line t.tmpl:7:1
+	switch t.(type) {
+	case *A:
+	}
+}
@@ -0,0 +1,5 @@
+package tags
+
+type C struct{}
+
+func (*C) sealed() {}
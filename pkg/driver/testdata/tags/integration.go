@@ -0,0 +1,7 @@
+//go:build integration
+
+package tags
+
+type B struct{}
+
+func (*B) sealed() {}
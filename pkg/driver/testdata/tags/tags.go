@@ -0,0 +1,15 @@
+package tags
+
+//go-sumtype:decl T
+
+type T interface{ sealed() }
+
+type A struct{}
+
+func (*A) sealed() {}
+
+func f(t T) {
+	switch t.(type) {
+	case *A:
+	}
+}
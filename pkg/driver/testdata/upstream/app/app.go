@@ -0,0 +1,10 @@
+package app
+
+import (
+	"github.com/BurntSushi/go-sumtype/pkg/driver/testdata/upstream/lib"
+	"github.com/BurntSushi/go-sumtype/pkg/driver/testdata/upstream/plugin"
+)
+
+func Describe(t lib.T) string {
+	return plugin.Name(t)
+}
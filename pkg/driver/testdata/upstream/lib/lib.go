@@ -0,0 +1,13 @@
+package lib
+
+//go-sumtype:decl T
+
+type T interface{ sealed() }
+
+type A struct{}
+
+func (*A) sealed() {}
+
+type B struct{}
+
+func (*B) sealed() {}
@@ -0,0 +1,13 @@
+// Package plugin stands in for a third-party package that switches over a
+// sum type of the packages being analyzed.
+package plugin
+
+import "github.com/BurntSushi/go-sumtype/pkg/driver/testdata/upstream/lib"
+
+func Name(t lib.T) string {
+	switch t.(type) {
+	case *lib.A:
+		return "a"
+	}
+	return ""
+}
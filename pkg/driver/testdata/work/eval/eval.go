@@ -0,0 +1,9 @@
+package eval
+
+import "example.com/ast"
+
+func Eval(e ast.Expr) {
+	switch e.(type) {
+	case *ast.Lit:
+	}
+}
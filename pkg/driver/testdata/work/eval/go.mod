@@ -0,0 +1,5 @@
+module example.com/eval
+
+go 1.26
+
+require example.com/ast v0.0.0
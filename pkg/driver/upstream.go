@@ -0,0 +1,112 @@
+package driver
+
+import (
+	"go/types"
+	"sync"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// UpstreamGapCheck is the check of the informational findings reported with
+// Config.WholeProgram for switches in dependencies.
+const UpstreamGapCheck = "upstream-gap"
+
+// dependencies records the results of the go-sumtype analyzer for the
+// dependencies of the root packages, which the checker does not retain.
+type dependencies struct {
+	mu      sync.Mutex
+	isRoot  map[*types.Package]bool
+	results map[*types.Package]*dependency
+}
+
+// dependency is the result of the go-sumtype analyzer for a dependency.
+type dependency struct {
+	res   *sumtype.Result
+	diags []analysis.Diagnostic
+}
+
+// recording returns a copy of the go-sumtype analyzer that records the
+// results and diagnostics of the packages other than the given roots in deps.
+func recording(roots []*packages.Package, deps *dependencies) *analysis.Analyzer {
+	deps.isRoot = map[*types.Package]bool{}
+	deps.results = map[*types.Package]*dependency{}
+	for _, pkg := range roots {
+		deps.isRoot[pkg.Types] = true
+	}
+	a := *sumtype.Analyzer
+	a.Run = func(pass *analysis.Pass) (interface{}, error) {
+		if deps.isRoot[pass.Pkg] {
+			return sumtype.Analyzer.Run(pass)
+		}
+		dep := &dependency{}
+		report := pass.Report
+		pass.Report = func(d analysis.Diagnostic) {
+			dep.diags = append(dep.diags, d)
+			report(d)
+		}
+		res, err := sumtype.Analyzer.Run(pass)
+		if err != nil {
+			return res, err
+		}
+		dep.res = res.(*sumtype.Result)
+		deps.mu.Lock()
+		deps.results[pass.Pkg] = dep
+		deps.mu.Unlock()
+		return res, nil
+	}
+	return &a
+}
+
+// upstreamGaps returns a package for each dependency of the given root
+// packages, e.g., a vendored or third-party package, with type switches over
+// sum types declared in the roots that are not exhaustive. Their findings are
+// informational, with the check UpstreamGapCheck, since they can't be fixed
+// in the code being analyzed: they point at the dependencies that break when
+// a variant is added.
+func upstreamGaps(cfg *Config, roots []*Package, deps *dependencies) ([]*Package, error) {
+	declared := map[string]bool{}
+	for _, pkg := range roots {
+		for _, st := range pkg.Result.SumTypes {
+			declared[st.Type.Pkg().Path()+"."+st.Type.Name()] = true
+		}
+	}
+	var gaps []*Package
+	var err error
+	var rootPkgs []*packages.Package
+	for _, pkg := range roots {
+		rootPkgs = append(rootPkgs, pkg.Package)
+	}
+	packages.Visit(rootPkgs, nil, func(p *packages.Package) {
+		dep := deps.results[p.Types]
+		if dep == nil || err != nil {
+			return
+		}
+		// The package is returned for its findings alone: its sum types and
+		// switches are not part of the analyzed code, e.g., for metrics.
+		pkg := &Package{Package: p, Result: &sumtype.Result{}}
+		for _, d := range dep.diags {
+			f := newFinding(p.Fset, p.PkgPath, dep.res, d)
+			if f.Check != sumtype.CategoryExhaustiveness || !declared[f.Type] {
+				continue
+			}
+			f.Check = UpstreamGapCheck
+			f.Informational = true
+			f.Message = "upstream gap in " + p.PkgPath + ": " + f.Message
+			var suppressed bool
+			if suppressed, err = cfg.suppressed(f); err != nil || suppressed {
+				continue
+			}
+			pkg.Findings = append(pkg.Findings, f)
+		}
+		if len(pkg.Findings) > 0 {
+			sortFindings(pkg.Findings)
+			gaps = append(gaps, pkg)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gaps, nil
+}
@@ -3,6 +3,9 @@ package sumtype
 import (
 	"go/ast"
 	"go/types"
+	"log"
+	"reflect"
+
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
@@ -10,9 +13,24 @@ import (
 
 var Analyzer = &analysis.Analyzer{
 	Name:     "gosumtype",
-	Doc:      "run exhaustiveness checks on type switch statements for sum types",
+	Doc:      "run exhaustiveness checks on type switch statements for sum types and switch statements for enums",
 	Requires: []*analysis.Analyzer{inspect.Analyzer},
 	Run:      run,
+	// Packages that don't type-check, e.g., while they are being edited, are
+	// analyzed on a best-effort basis: switches whose subject or cases could
+	// not be resolved are skipped.
+	RunDespiteErrors: true,
+	ResultType:       reflect.TypeOf(new(Result)),
+	FactTypes:        []analysis.Fact{new(sumTypeFact), new(bridgeFact)},
+}
+
+// maxFileSize is set with the -max-file-size flag. Files larger than this
+// many bytes are skipped entirely. Zero means there is no limit.
+var maxFileSize int
+
+func init() {
+	Analyzer.Flags.IntVar(&maxFileSize, "max-file-size", 0,
+		"skip files larger than this many bytes, e.g., huge generated files (0 means no limit)")
 }
 
 func run(pass *analysis.Pass) (interface{}, error) {
@@ -22,36 +40,157 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	nodeFilter := []ast.Node{
 		(*ast.File)(nil),
 		(*ast.TypeSwitchStmt)(nil),
+		(*ast.SwitchStmt)(nil),
+		(*ast.CompositeLit)(nil),
+		(*ast.IfStmt)(nil),
 	}
 
 	var (
-		filesToPkg = map[*ast.File]*types.Package{}
-		switches   []*ast.TypeSwitchStmt
+		filesToPkg   = map[*ast.File]*types.Package{}
+		switches     []*ast.TypeSwitchStmt
+		enumSwitches []*ast.SwitchStmt
+		lits         []*ast.CompositeLit
+		ifs          []*ast.IfStmt
+		elseIfs      = map[*ast.IfStmt]bool{}
 	)
 
-	inspector.Preorder(nodeFilter, func(node ast.Node) {
+	inspector.Nodes(nodeFilter, func(node ast.Node, push bool) bool {
+		if !push {
+			return true
+		}
 		switch v := node.(type) {
 		case *ast.File:
+			if tooLarge(pass, v) {
+				return false
+			}
 			filesToPkg[v] = pass.Pkg
 
 		case *ast.TypeSwitchStmt:
 			switches = append(switches, v)
+
+		case *ast.SwitchStmt:
+			enumSwitches = append(enumSwitches, v)
+
+		case *ast.CompositeLit:
+			lits = append(lits, v)
+
+		case *ast.IfStmt:
+			// Only the first if statement of an if/else chain begins an
+			// assertion chain.
+			if !elseIfs[v] {
+				ifs = append(ifs, v)
+			}
+			if elseIf, ok := v.Else.(*ast.IfStmt); ok {
+				elseIfs[elseIf] = true
+			}
 		}
+		return true
 	})
 
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	externalEnums, err := findExternalEnumDefs(pass.Pkg, cfg)
+	if err != nil {
+		return nil, err
+	}
+	ignored, err := ignorePatterns(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fixBodyTemplate(cfg); err != nil {
+		return nil, err
+	}
+	if err := checkScope(); err != nil {
+		return nil, err
+	}
+	if err := checkDefaultPolicyFlag(); err != nil {
+		return nil, err
+	}
+
 	decls := findSumTypeDecls(pass, filesToPkg)
-	if len(decls) == 0 {
-		return nil, nil
+	if autoSeal {
+		decls = append(decls, autoSealedDecls(pass, decls)...)
+	}
+	imported := importedSumTypeDefs(pass)
+	if len(decls) == 0 && len(externalEnums) == 0 && len(imported) == 0 {
+		return &Result{}, nil
 	}
 
-	defs := findSumTypeDefs(pass, decls)
-	if len(defs) == 0 {
-		return nil, nil
+	defs := append(findSumTypeDefs(pass, decls), imported...)
+	dropIgnoredVariants(defs, ignored)
+	checkEmbeddingVariants(pass, defs)
+	exportSumTypeFacts(pass, defs)
+	enums := append(findEnumDefs(pass, decls), externalEnums...)
+	if len(defs) == 0 && len(enums) == 0 {
+		return &Result{}, nil
 	}
 
+	bridges := append(findBridgeDefs(pass, decls, defs), importedBridgeDefs(pass, defs)...)
+
+	res := &Result{}
+	params := paramDefs(pass, defs)
+	var infos []*switchInfo
 	for _, swtch := range switches {
-		checkSwitch(pass, defs, swtch)
+		if sw := checkSwitch(pass, res, defs, params, swtch); sw != nil {
+			if requireDefaultValue {
+				checkDefaultPanic(pass, res, sw)
+			}
+			if requireBinding {
+				checkBinding(pass, res, sw)
+			}
+			checkNilCase(pass, res, sw)
+			checkDefaultPolicy(pass, res, sw)
+			if checkWrappedErrors {
+				checkWrappedError(pass, res, sw)
+			}
+			infos = append(infos, sw)
+		}
+	}
+	for _, swtch := range enumSwitches {
+		if checkBridgeSwitch(pass, res, bridges, swtch) {
+			continue
+		}
+		checkEnumSwitch(pass, res, enums, swtch)
+		checkFlagSwitch(pass, res, enums, swtch)
+		if checkReflectSwitches {
+			checkReflectSwitch(pass, res, defs, swtch)
+		}
+	}
+	if checkAssertionChains {
+		for _, stmt := range ifs {
+			checkAssertionChain(pass, res, defs, stmt)
+		}
+	}
+	checkRegistries(pass, res, defs)
+	checkRequirements(pass, res, decls, defs)
+	checkMatchers(pass, res, defs, lits)
+	checkFactories(pass, res, defs)
+	if reportEscapes {
+		checkEscapes(pass, res, defs)
+	}
+	for _, lit := range lits {
+		checkDispatchTable(pass, res, enums, lit)
+		checkLookupTable(pass, res, enums, lit)
 	}
 
-	return nil, nil
+	res.describe(pass.Pkg, defs, infos)
+	return res, nil
+}
+
+// tooLarge returns true if the given file exceeds the size given with the
+// -max-file-size flag, in which case it is neither scanned for declarations
+// nor checked. A note is logged for each file skipped.
+func tooLarge(pass *analysis.Pass, file *ast.File) bool {
+	if maxFileSize <= 0 {
+		return false
+	}
+	tokFile := pass.Fset.File(file.Pos())
+	if tokFile == nil || tokFile.Size() <= maxFileSize {
+		return false
+	}
+	log.Printf("skipping '%s': %d bytes exceeds -max-file-size of %d",
+		tokFile.Name(), tokFile.Size(), maxFileSize)
+	return true
 }
@@ -1,17 +1,362 @@
 package sumtype
 
 import (
+	"bytes"
+	"go/build"
+	"go/types"
+	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"golang.org/x/tools/go/analysis/analysistest"
 )
 
 func TestAll(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, testdata(t), Analyzer, "p")
+}
+
+func TestExternalEnums(t *testing.T) {
+	dir := testdata(t)
+	setFlag(t, "config", filepath.Join(dir, "config.json"))
+	analysistest.RunWithSuggestedFixes(t, dir, Analyzer, "external")
+}
+
+func TestMissingVariantFixes(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, testdata(t), Analyzer, "fixcases")
+}
+
+func TestGroupedFixes(t *testing.T) {
+	setFlag(t, "fix-grouped", "true")
+	analysistest.RunWithSuggestedFixes(t, testdata(t), Analyzer, "grouped")
+}
+
+func TestFixBody(t *testing.T) {
+	for _, body := range []string{"todo", "zero", "error"} {
+		t.Run(body, func(t *testing.T) {
+			setFlag(t, "fix-body", body)
+			pkg := "fixbody/" + body
+			if body == "error" {
+				pkg = "fixbody/returnerr"
+			}
+			analysistest.RunWithSuggestedFixes(t, testdata(t), Analyzer, pkg)
+		})
+	}
+}
+
+func TestLineDirectives(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, testdata(t), Analyzer, "linedirective")
+}
+
+func TestExpectImportedSumTypes(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "expectimport", "expectimport/local")
+}
+
+func TestEnumAliases(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "enumalias")
+}
+
+func TestEnumRequireDefault(t *testing.T) {
+	setFlag(t, "enum-require-default", "true")
+	analysistest.Run(t, testdata(t), Analyzer, "enumdefault")
+}
+
+func TestMaxFileSize(t *testing.T) {
+	setFlag(t, "max-file-size", "1000")
+	analysistest.Run(t, testdata(t), Analyzer, "maxsize")
+}
+
+func TestIgnoreVariants(t *testing.T) {
+	setFlag(t, "ignore-variants", "Mock$, Stub$")
+	analysistest.Run(t, testdata(t), Analyzer, "ignore")
+}
+
+func TestPreset(t *testing.T) {
+	for _, name := range []string{"default", "enum-require-default", "require-binding",
+		"require-default-value", "require-nil"} {
+		setFlag(t, name, Analyzer.Flags.Lookup(name).Value.String())
+	}
+	for _, tt := range []struct {
+		preset string
+		want   map[string]string
+	}{
+		{"strict", map[string]string{
+			"default":               "must-panic",
+			"enum-require-default":  "true",
+			"require-binding":       "true",
+			"require-default-value": "true",
+			"require-nil":           "true",
+		}},
+		{"standard", map[string]string{
+			"default":               "allow",
+			"enum-require-default":  "false",
+			"require-binding":       "false",
+			"require-default-value": "true",
+			"require-nil":           "false",
+		}},
+		{"lenient", map[string]string{
+			"default":               "allow",
+			"enum-require-default":  "false",
+			"require-binding":       "false",
+			"require-default-value": "false",
+			"require-nil":           "false",
+		}},
+	} {
+		setFlag(t, "preset", tt.preset)
+		for name, want := range tt.want {
+			if got := Analyzer.Flags.Lookup(name).Value.String(); got != want {
+				t.Errorf("-preset %s set -%s to %s, want %s", tt.preset, name, got, want)
+			}
+		}
+	}
+	if err := Analyzer.Flags.Set("preset", "paranoid"); err == nil {
+		t.Errorf("-preset paranoid did not fail")
+	}
+}
+
+func TestRequireBinding(t *testing.T) {
+	setFlag(t, "require-binding", "true")
+	analysistest.RunWithSuggestedFixes(t, testdata(t), Analyzer, "binding")
+}
+
+func TestExportedScope(t *testing.T) {
+	setFlag(t, "scope", "exported")
+	analysistest.Run(t, testdata(t), Analyzer, "scope")
+}
+
+func TestAutoSeal(t *testing.T) {
+	setFlag(t, "auto-seal", "true")
+	analysistest.Run(t, testdata(t), Analyzer, "autoseal")
+}
+
+func TestNilCase(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, testdata(t), Analyzer, "nilcase", "nilcaseuse")
+}
+
+func TestRequireNil(t *testing.T) {
+	setFlag(t, "require-nil", "true")
+	analysistest.Run(t, testdata(t), Analyzer, "nilcaserequired")
+}
+
+func TestDefaultPolicy(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "defaultpolicy")
+}
+
+func TestDefaultPolicyFlag(t *testing.T) {
+	setFlag(t, "default", "must-panic")
+	analysistest.Run(t, testdata(t), Analyzer, "defaultpolicyflag")
+}
+
+func TestTerminatingDefaults(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "terminate")
+}
+
+func TestReportAtVariants(t *testing.T) {
+	setFlag(t, "report-at-variants", "true")
+	analysistest.Run(t, testdata(t), Analyzer, "unhandled")
+}
+
+func TestSplitMissing(t *testing.T) {
+	setFlag(t, "split-missing", "true")
+	analysistest.Run(t, testdata(t), Analyzer, "split")
+}
+
+func TestTrace(t *testing.T) {
+	setFlag(t, "trace", "true")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	analysistest.Run(t, testdata(t), Analyzer, "dotimport")
+	for _, want := range []string{
+		"dotimport.go:9:2: switch matches sum type 'Expr' by the type of the switched value",
+		"dotimport.go:10:7: case *Lit covers Lit",
+		"dotimport.go:9:2: switch has no default clause",
+		"dotimport.go:9:2: switch is missing Add",
+		"dotimport.go:14:2: switch covers every variant of 'Expr'",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("trace does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestTraceDefaultPolicy(t *testing.T) {
+	setFlag(t, "trace", "true")
+	setFlag(t, "default", "must-panic")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	analysistest.Run(t, testdata(t), Analyzer, "defaultpolicyflag")
+	for _, want := range []string{
+		"defaultpolicyflag.go:30:2: default clause may not terminate, but the switch is still checked " +
+			"under the 'must-panic' policy for default clauses",
+		"defaultpolicyflag.go:38:2: default clause may not terminate, so the switch is not checked",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("trace does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestTraceStandardLibrary(t *testing.T) {
+	setFlag(t, "trace", "true")
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	// The package imports go/ast, whose sealed interfaces are analyzed for
+	// facts, but whose type switches over them must not be traced.
+	setFlag(t, "auto-seal", "true")
+	analysistest.Run(t, testdata(t), Analyzer, "tracestd")
+	if !strings.Contains(buf.String(), "tracestd.go:") {
+		t.Errorf("trace does not contain the switches of tracestd:\n%s", buf.String())
+	}
+	if goroot := filepath.Join(build.Default.GOROOT, "src"); strings.Contains(buf.String(), goroot) {
+		t.Errorf("trace contains files in %s:\n%s", goroot, buf.String())
+	}
+}
+
+func TestReflectSwitches(t *testing.T) {
+	setFlag(t, "check-reflect-switches", "true")
+	analysistest.Run(t, testdata(t), Analyzer, "reflectswitch")
+}
+
+func TestBridges(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, testdata(t), Analyzer, "bridge", "bridge/ast", "bridge/bad")
+}
+
+func TestRequireDefaultValue(t *testing.T) {
+	setFlag(t, "require-default-value", "true")
+	analysistest.RunWithSuggestedFixes(t, testdata(t), Analyzer, "defaultpanic")
+}
+
+func TestRegistries(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "registry")
+}
+
+func TestAssertionChains(t *testing.T) {
+	setFlag(t, "check-assertion-chains", "true")
+	analysistest.RunWithSuggestedFixes(t, testdata(t), Analyzer, "assertchain")
+}
+
+func TestShadowedNames(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "shadow")
+}
+
+func TestDotImport(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "dotimport")
+}
+
+func TestExternalTestPackage(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "xtest")
+}
+
+func TestGenericVariants(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "generic")
+}
+
+func TestReexport(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "reexport")
+}
+
+func TestRequire(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "require")
+}
+
+func TestEmbeddingVariants(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "embedding")
+}
+
+func TestExcludeEmbeddingVariants(t *testing.T) {
+	setFlag(t, "exclude-embedding-variants", "true")
+	analysistest.Run(t, testdata(t), Analyzer, "embedding/exclude")
+}
+
+func TestReportEscapes(t *testing.T) {
+	setFlag(t, "report-escapes", "true")
+	analysistest.Run(t, testdata(t), Analyzer, "escape")
+}
+
+func TestMatchers(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "matcher")
+}
+
+func TestFactories(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "constructs")
+}
+
+func TestWrappedErrors(t *testing.T) {
+	setFlag(t, "check-wrapped-errors", "true")
+	analysistest.Run(t, testdata(t), Analyzer, "wrapped")
+}
+
+func TestComposedSumTypes(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "compose", "compose/use")
+}
+
+func TestNarrowedSubInterfaces(t *testing.T) {
+	results := analysistest.Run(t, testdata(t), Analyzer, "narrow")
+	res := results[0].Result.(*Result)
+	type summary struct {
+		narrowed, handled, missing string
+	}
+	var got []summary
+	for _, sw := range res.Switches {
+		if sw.SumType.Type.Name() != "Node" {
+			t.Errorf("switch over %s; want Node", sw.SumType.Type.Name())
+		}
+		s := summary{handled: names(sw.Handled), missing: names(sw.Missing)}
+		if sw.Narrowed != nil {
+			s.narrowed = sw.Narrowed.Name()
+		}
+		got = append(got, s)
+	}
+	want := []summary{
+		{"", "Call, Ident, Leaf", ""},
+		{"TerminalNode", "Ident", "Leaf"},
+		{"", "Call, Ident, Leaf", ""},
+		{"TerminalNode", "Ident, Leaf", ""},
+		{"TerminalNode", "Leaf", "Ident"},
+		{"", "Call, Leaf", "Ident"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got switches %v; want %v", got, want)
+	}
+}
+
+// names returns the names of the given type names, separated by commas.
+func names(objs []*types.TypeName) string {
+	var names []string
+	for _, obj := range objs {
+		names = append(names, obj.Name())
+	}
+	return strings.Join(names, ", ")
+}
+
+func TestUnionConstraints(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, testdata(t), Analyzer, "union")
+}
+
+func TestBestEffort(t *testing.T) {
+	analysistest.Run(t, testdata(t), Analyzer, "besteffort")
+}
+
+// testdata returns the absolute path to the testdata directory.
+func testdata(t *testing.T) string {
 	wd, err := os.Getwd()
 	if err != nil {
 		t.Fatalf("could not get working directory: %v", err)
 	}
-	analysistest.Run(t, filepath.Join(wd, "testdata"), Analyzer, "p")
+	return filepath.Join(wd, "testdata")
+}
+
+// setFlag sets an analyzer flag for the duration of a test.
+func setFlag(t *testing.T, name, value string) {
+	f := Analyzer.Flags.Lookup(name)
+	old := f.Value.String()
+	if err := f.Value.Set(value); err != nil {
+		t.Fatalf("could not set flag -%s: %v", name, err)
+	}
+	t.Cleanup(func() { f.Value.Set(old) })
 }
@@ -0,0 +1,305 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkAssertionChains is set with the -check-assertion-chains flag. When
+// true, chains of if statements asserting a sum type value to its variants
+// are checked like type switches.
+var checkAssertionChains bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkAssertionChains, "check-assertion-chains", false,
+		"also check if/else chains of type assertions of a sum type value, like `if a, ok := v.(*A); ok {...} else if ...`")
+}
+
+// assertLink is an if statement of an assertion chain, of the form
+// `if x, ok := v.(T); ok {...}`.
+type assertLink struct {
+	Stmt   *ast.IfStmt
+	Assert *ast.TypeAssertExpr
+	// Bound is x, which may be _.
+	Bound *ast.Ident
+	OK    *ast.Ident
+}
+
+// checkAssertionChain performs an exhaustiveness check on the assertion
+// chain beginning with the given if statement, e.g.:
+//
+//	if a, ok := v.(*A); ok {
+//		...
+//	} else if b, ok := v.(*B); ok {
+//		...
+//	}
+//
+// Every link of the chain must assert the same variable of a sum type, and
+// there must be at least two of them, since a single assertion is usually
+// not meant to handle every variant. As with type switches, a final else
+// block that doesn't always panic disables the check. The finding suggests
+// rewriting the chain into a type switch with cases for the missing variants.
+func checkAssertionChain(pass *analysis.Pass, res *Result, defs []sumTypeDef, stmt *ast.IfStmt) {
+	links, els := assertionChain(pass, stmt)
+	if links == nil {
+		return
+	}
+	def := findDef(defs, pass.TypesInfo.TypeOf(links[0].Assert.X))
+	if def == nil || !inScope(def) {
+		return
+	}
+	if els != nil && terminatingCall(pass, &ast.CaseClause{Body: els.List}) == nil {
+		return
+	}
+	var tys []types.Type
+	for _, link := range links {
+		ty := pass.TypesInfo.TypeOf(link.Assert.Type)
+		if !resolved(ty) {
+			return
+		}
+		tys = append(tys, ty)
+	}
+	if allowsGenerics(pass, stmt.Pos()) {
+		tys = genericOrigins(tys)
+	}
+	missing := def.missing(tys)
+	if len(missing) == 0 {
+		return
+	}
+	for _, group := range groupMissing(missing) {
+		names := missingNames(group)
+		res.report(pass, &Finding{
+			Diagnostic: analysis.Diagnostic{
+				Pos:      stmt.Pos(),
+				Category: CategoryExhaustiveness,
+				Message: fmt.Sprintf(
+					"exhaustiveness check failed for sum type '%s': missing cases for %s",
+					def.Decl.TypeName, strings.Join(names, ", ")),
+				Related:        def.Decl.related(),
+				SuggestedFixes: assertionChainFix(pass, def, links, els, group),
+			},
+			Type:    def.qualifiedName(),
+			Missing: names,
+		})
+	}
+}
+
+// assertionChain returns the links of the assertion chain beginning with the
+// given if statement, and the else block ending it, if any. Nil is returned
+// if the statement doesn't begin a chain of at least two links asserting the
+// same variable, including when the chain ends with an if statement that
+// isn't a link.
+func assertionChain(pass *analysis.Pass, stmt *ast.IfStmt) ([]*assertLink, *ast.BlockStmt) {
+	var links []*assertLink
+	var els *ast.BlockStmt
+	for s := stmt; s != nil; {
+		link := parseAssertLink(pass, s)
+		if link == nil {
+			return nil, nil
+		}
+		obj := identObject(pass, link.Assert.X)
+		if obj == nil || len(links) > 0 && obj != identObject(pass, links[0].Assert.X) {
+			return nil, nil
+		}
+		links = append(links, link)
+		s = nil
+		switch e := link.Stmt.Else.(type) {
+		case *ast.IfStmt:
+			s = e
+		case *ast.BlockStmt:
+			els = e
+		}
+	}
+	if len(links) < 2 {
+		return nil, nil
+	}
+	return links, els
+}
+
+// parseAssertLink returns the given if statement as a link of an assertion
+// chain, or nil if it isn't one.
+func parseAssertLink(pass *analysis.Pass, stmt *ast.IfStmt) *assertLink {
+	assign, ok := stmt.Init.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+		return nil
+	}
+	assert, ok := assign.Rhs[0].(*ast.TypeAssertExpr)
+	if !ok || assert.Type == nil {
+		return nil
+	}
+	bound, ok := assign.Lhs[0].(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	okIdent, ok := assign.Lhs[1].(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	cond, ok := ast.Unparen(stmt.Cond).(*ast.Ident)
+	if !ok || pass.TypesInfo.Defs[okIdent] == nil || pass.TypesInfo.Uses[cond] != pass.TypesInfo.Defs[okIdent] {
+		return nil
+	}
+	return &assertLink{Stmt: stmt, Assert: assert, Bound: bound, OK: okIdent}
+}
+
+// assertionChainFix returns a suggested fix that rewrites the given assertion
+// chain into a type switch, with a case clause for each of its links and for
+// each of the given missing variants, and a default clause for its else
+// block. No fix is suggested if the links bind the asserted value to
+// different names, if the rewrite would change what an identifier or a break
+// statement in the chain refers to, or if the cases of the missing variants
+// can't be written (see variantCases).
+func assertionChainFix(
+	pass *analysis.Pass,
+	def *sumTypeDef,
+	links []*assertLink,
+	els *ast.BlockStmt,
+	missing []types.Object,
+) []analysis.SuggestedFix {
+	bound := ""
+	var exprs []ast.Expr
+	for _, link := range links {
+		exprs = append(exprs, link.Assert.Type)
+		if link.Bound.Name == "_" {
+			continue
+		}
+		if bound != "" && bound != link.Bound.Name {
+			return nil
+		}
+		bound = link.Bound.Name
+	}
+	if !switchPreservesChain(pass, links, els, bound) {
+		return nil
+	}
+	head := links[0].Stmt
+	cases, imports := variantCases(pass, def, head.Pos(), exprs, missing)
+	if cases == nil {
+		return nil
+	}
+
+	subject := types.ExprString(links[0].Assert.X)
+	if bound != "" {
+		subject = bound + " := " + subject
+	}
+	indent := strings.Repeat("\t", pass.Fset.PositionFor(head.Pos(), false).Column-1)
+	edits := []analysis.TextEdit{{
+		Pos: head.Pos(),
+		End: head.Body.Lbrace + 1,
+		NewText: []byte(fmt.Sprintf("switch %s.(type) {\n%scase %s:",
+			subject, indent, types.ExprString(links[0].Assert.Type))),
+	}}
+	for i, link := range links[1:] {
+		edits = append(edits, analysis.TextEdit{
+			Pos:     links[i].Stmt.Body.Rbrace,
+			End:     link.Stmt.Body.Lbrace + 1,
+			NewText: []byte("case " + types.ExprString(link.Assert.Type) + ":"),
+		})
+	}
+	// The closing brace of the last link becomes that of the switch, unless
+	// the else block's does.
+	last := links[len(links)-1].Stmt.Body.Rbrace
+	text, bodyImports := caseClauses(pass, last, cases)
+	end := last
+	if els != nil {
+		text += "default:"
+		end = els.Lbrace + 1
+	}
+	edits = append(edits, analysis.TextEdit{Pos: last, End: end, NewText: []byte(text)})
+	edits = append(edits, bodyImports...)
+	return []analysis.SuggestedFix{{
+		Message:   fmt.Sprintf("Rewrite as a type switch with cases for %s", strings.Join(cases, ", ")),
+		TextEdits: append(edits, imports...),
+	}}
+}
+
+// switchPreservesChain returns true if the given assertion chain keeps its
+// meaning once rewritten into a type switch binding the asserted value to
+// bound, if not empty. Each binding of a link must then only be used in its
+// body, and each ok variable only in its condition. No body may use another
+// variable named bound, which the switch would shadow, or declare one, which
+// would conflict with it once the body becomes a case clause. Nor may a body
+// contain a break statement, which would break out of the switch instead of
+// an enclosing statement.
+func switchPreservesChain(pass *analysis.Pass, links []*assertLink, els *ast.BlockStmt, bound string) bool {
+	owners := map[types.Object]*assertLink{}
+	bodies := []*ast.BlockStmt{els}
+	for _, link := range links {
+		owners[pass.TypesInfo.Defs[link.OK]] = link
+		if obj := pass.TypesInfo.Defs[link.Bound]; obj != nil {
+			owners[obj] = link
+		}
+		bodies = append(bodies, link.Stmt.Body)
+	}
+	head := links[0].Stmt
+	preserved := true
+	ast.Inspect(head, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			return preserved
+		}
+		if obj := pass.TypesInfo.Uses[id]; obj != nil {
+			if link := owners[obj]; link != nil {
+				if id.Name == link.OK.Name && obj == pass.TypesInfo.Defs[link.OK] {
+					preserved = id == ast.Unparen(link.Stmt.Cond)
+				} else {
+					preserved = within(id, link.Stmt.Body)
+				}
+			} else if bound != "" && id.Name == bound && !within(obj, head) && inBodies(id, bodies) {
+				preserved = false
+			}
+		}
+		if obj := pass.TypesInfo.Defs[id]; obj != nil && bound != "" && id.Name == bound {
+			for _, body := range bodies {
+				if body != nil && obj.Parent() == pass.TypesInfo.Scopes[body] {
+					preserved = false
+				}
+			}
+		}
+		return preserved
+	})
+	for _, body := range bodies {
+		if body != nil && breaksOut(body) {
+			return false
+		}
+	}
+	return preserved
+}
+
+// within returns true if the position of n is within the given node.
+func within(n interface{ Pos() token.Pos }, node ast.Node) bool {
+	return node.Pos() <= n.Pos() && n.Pos() < node.End()
+}
+
+// inBodies returns true if id is within one of the given blocks, which may
+// be nil.
+func inBodies(id *ast.Ident, bodies []*ast.BlockStmt) bool {
+	for _, body := range bodies {
+		if body != nil && within(id, body) {
+			return true
+		}
+	}
+	return false
+}
+
+// breaksOut returns true if the given block contains an unlabeled break
+// statement that breaks out of a statement enclosing the block.
+func breaksOut(block *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(block, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.FuncLit:
+			return false
+		case *ast.BranchStmt:
+			if n.Tok == token.BREAK && n.Label == nil {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,60 @@
+package sumtype
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// autoSeal is set with the -auto-seal flag. When true, every interface with
+// an unexported method is treated as a sum type, as if it were declared with
+// a `go-sumtype:decl` directive. This is sound, since such an interface can
+// only be implemented in its own package, where its variants are found.
+var autoSeal bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&autoSeal, "auto-seal", false,
+		"treat every interface with an unexported method as a sum type, without a go-sumtype:decl directive")
+}
+
+// autoSealedDecls returns a sum type declaration for each sealed interface
+// declared at the top level of the package that is not already declared as a
+// sum type in decls. Generic interfaces and constraints with type terms are
+// skipped. The position of each declaration is that of the interface, so
+// that diagnostics point at it instead of a directive.
+func autoSealedDecls(pass *analysis.Pass, decls []sumTypeDecl) []sumTypeDecl {
+	declared := map[string]bool{}
+	for _, decl := range decls {
+		if decl.Kind == declSumType {
+			declared[decl.TypeName] = true
+		}
+	}
+	var sealed []sumTypeDecl
+	scope := pass.Pkg.Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() || declared[name] {
+			continue
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+		iface, ok := named.Underlying().(*types.Interface)
+		if !ok || !iface.IsMethodSet() {
+			continue
+		}
+		for i := 0; i < iface.NumMethods(); i++ {
+			if !iface.Method(i).Exported() {
+				sealed = append(sealed, sumTypeDecl{
+					Kind:     declSumType,
+					Package:  pass.Pkg,
+					TypeName: name,
+					Pos:      obj.Pos(),
+				})
+				break
+			}
+		}
+	}
+	return sealed
+}
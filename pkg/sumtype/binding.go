@@ -0,0 +1,153 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// requireBinding is set with the -require-binding flag. When true, a type
+// switch over a sum type whose case clauses refer to the switched variable
+// must bind it, as in `switch x := x.(type)`, rather than asserting it again
+// in each clause.
+var requireBinding bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&requireBinding, "require-binding", false,
+		"require switches over sum types whose cases refer to the switched variable to bind it")
+}
+
+// checkBinding reports the given type switch over a sum type if it discards
+// the switched value, as in `switch x.(type)`, while its case clauses refer
+// to the variable x. When it is safe to do so, a fix is suggested that binds
+// x and drops the assertions of x to the type of a clause in its body.
+func checkBinding(pass *analysis.Pass, res *Result, sw *switchInfo) {
+	discarded, ok := sw.Stmt.Assign.(*ast.ExprStmt)
+	if !ok {
+		return
+	}
+	assert := discarded.X.(*ast.TypeAssertExpr)
+	id, ok := ast.Unparen(assert.X).(*ast.Ident)
+	if !ok {
+		return
+	}
+	obj, ok := pass.TypesInfo.Uses[id].(*types.Var)
+	if !ok {
+		return
+	}
+	b := &binder{pass: pass, obj: obj, name: id.Name, fixable: true}
+	for _, stmt := range sw.Stmt.Body.List {
+		b.clause(stmt.(*ast.CaseClause))
+	}
+	if !b.referenced {
+		return
+	}
+	d := analysis.Diagnostic{
+		Pos:      sw.Stmt.Pos(),
+		Category: CategoryBinding,
+		Message: fmt.Sprintf("switch over sum type '%s' refers to %s in its cases "+
+			"without binding it: use 'switch %s := %s.(type)'",
+			sw.Def.Decl.TypeName, id.Name, id.Name, types.ExprString(assert.X)),
+		Related: sw.Def.Decl.related(),
+	}
+	if b.fixable {
+		d.SuggestedFixes = []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("Bind %s in the switch", id.Name),
+			TextEdits: append([]analysis.TextEdit{{
+				Pos:     assert.Pos(),
+				NewText: []byte(id.Name + " := "),
+			}}, b.edits...),
+		}}
+	}
+	res.report(pass, &Finding{Diagnostic: d, Type: sw.Def.qualifiedName()})
+}
+
+// binder finds the references to the switched variable obj in the case
+// clauses of a type switch, along with the edits needed to bind it instead.
+type binder struct {
+	pass *analysis.Pass
+	obj  *types.Var
+	name string
+	// referenced is true if any clause refers to obj.
+	referenced bool
+	// fixable is false if binding obj would change the meaning of a clause
+	// or fail to compile, e.g., because the clause assigns to obj.
+	fixable bool
+	edits   []analysis.TextEdit
+}
+
+// clause records the references to the switched variable in the body of the
+// given case clause. In a clause with a single type, the bound variable has
+// that type, so the assertions of the variable to it become the variable
+// itself, and any other assertion of it would no longer compile unless the
+// type is an interface.
+func (b *binder) clause(clause *ast.CaseClause) {
+	var typ types.Type
+	if len(clause.List) == 1 {
+		typ = b.pass.TypesInfo.TypeOf(clause.List[0])
+		if types.Identical(typ, types.Typ[types.UntypedNil]) {
+			typ = nil
+		}
+	}
+	commaOk := map[ast.Expr]bool{}
+	for _, stmt := range clause.Body {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if len(n.Lhs) == 2 && len(n.Rhs) == 1 {
+					commaOk[ast.Unparen(n.Rhs[0])] = true
+				}
+				for _, lhs := range n.Lhs {
+					if b.isVar(lhs) {
+						b.fixable = false
+					}
+				}
+			case *ast.ValueSpec:
+				if len(n.Names) == 2 && len(n.Values) == 1 {
+					commaOk[ast.Unparen(n.Values[0])] = true
+				}
+			case *ast.IncDecStmt:
+				if b.isVar(n.X) {
+					b.fixable = false
+				}
+			case *ast.UnaryExpr:
+				if n.Op == token.AND && b.isVar(n.X) {
+					b.fixable = false
+				}
+			case *ast.TypeAssertExpr:
+				if typ == nil || !b.isVar(n.X) {
+					return true
+				}
+				// The type of a nested type switch on the variable
+				// is nil.
+				if n.Type == nil || commaOk[n] || !types.Identical(b.pass.TypesInfo.TypeOf(n.Type), typ) {
+					// The variable can still be asserted if it is
+					// bound to an interface.
+					if !types.IsInterface(typ) {
+						b.fixable = false
+					}
+					return true
+				}
+				b.edits = append(b.edits, analysis.TextEdit{
+					Pos:     n.Pos(),
+					End:     n.End(),
+					NewText: []byte(b.name),
+				})
+			case *ast.Ident:
+				if b.pass.TypesInfo.Uses[n] == b.obj {
+					b.referenced = true
+				}
+			}
+			return true
+		})
+	}
+}
+
+// isVar returns true if expr is the switched variable.
+func (b *binder) isVar(expr ast.Expr) bool {
+	id, ok := ast.Unparen(expr).(*ast.Ident)
+	return ok && b.pass.TypesInfo.Uses[id] == b.obj
+}
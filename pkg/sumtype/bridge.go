@@ -0,0 +1,302 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// bridgeDef corresponds to a `go-sumtype:bridge Enum Type` declaration, which
+// states that the members of an enum are the kinds of the variants of a sum
+// type, one for each variant. Every variant has a method without parameters
+// returning its kind, e.g., `func (*Lit) Kind() ExprKind { return KindLit }`,
+// so switches over the kind of a value are checked against the variants of
+// the sum type.
+type bridgeDef struct {
+	Decl sumTypeDecl
+	Enum *enumDef
+	Def  *sumTypeDef
+	// Kinds maps each variant of Def to its member of Enum.
+	Kinds map[types.Object]types.Object
+}
+
+// bridgeFact is exported for the enum of every bridge declared in a package,
+// so that switches over the enum in packages importing it are checked too.
+type bridgeFact struct {
+	// SumType is the name of the sum type, which is declared in the same
+	// package as the enum.
+	SumType string
+	// Members and Variants are the names of the members of the enum and of
+	// their variants, respectively.
+	Members, Variants []string
+}
+
+func (*bridgeFact) AFact() {}
+
+func (f *bridgeFact) String() string {
+	var kinds []string
+	for i := range f.Members {
+		kinds = append(kinds, f.Members[i]+"="+f.Variants[i])
+	}
+	return "bridge(" + f.SumType + ": " + strings.Join(kinds, ", ") + ")"
+}
+
+// findBridgeDefs builds the bridges declared in the current package, between
+// its enums and the sum types among defs, and exports a fact for each of
+// them. Bridges whose correspondence between members and variants is not one
+// to one are reported and skipped.
+func findBridgeDefs(pass *analysis.Pass, decls []sumTypeDecl, defs []sumTypeDef) []bridgeDef {
+	var bridges []bridgeDef
+	for _, decl := range decls {
+		if decl.Kind != declBridge {
+			continue
+		}
+		b := newBridgeDef(pass, decl, defs)
+		if b == nil {
+			continue
+		}
+		fact := &bridgeFact{SumType: b.Def.Decl.TypeName}
+		for _, m := range b.Enum.Members {
+			for v, kind := range b.Kinds {
+				if kind == m {
+					fact.Members = append(fact.Members, m.Name())
+					fact.Variants = append(fact.Variants, v.Name())
+				}
+			}
+		}
+		pass.ExportObjectFact(pass.Pkg.Scope().Lookup(decl.TypeName), fact)
+		bridges = append(bridges, *b)
+	}
+	return bridges
+}
+
+// newBridgeDef builds the bridge declared by decl. If the declaration is
+// invalid, then it is reported and nil is returned.
+func newBridgeDef(pass *analysis.Pass, decl sumTypeDecl, defs []sumTypeDef) *bridgeDef {
+	if len(decl.Options) != 1 {
+		reportDeclf(pass, decl.Pos, "bridge directive requires an enum name and a sum type name")
+		return nil
+	}
+	def := findDefByName(defs, decl.Options[0])
+	if def == nil || def.Decl.Package != pass.Pkg {
+		reportDeclf(pass, decl.Pos, "bridge directive names '%s', "+
+			"which is not a sum type declared in this package", decl.Options[0])
+		return nil
+	}
+	obj := pass.Pkg.Scope().Lookup(decl.TypeName)
+	if obj == nil {
+		reportDeclf(pass, decl.Pos, "type '%s' is not defined", decl.TypeName)
+		return nil
+	}
+	enum, err := buildEnumDef(obj, sumTypeDecl{
+		Kind:     declEnum,
+		Package:  decl.Package,
+		TypeName: decl.TypeName,
+		Pos:      decl.Pos,
+	}, false)
+	if err != nil {
+		reportDeclf(pass, decl.Pos, "%s", err)
+		return nil
+	}
+
+	b := &bridgeDef{Decl: decl, Enum: enum, Def: def, Kinds: map[types.Object]types.Object{}}
+	variants := map[types.Object]types.Object{}
+	ok := true
+	for _, v := range def.Variants {
+		m, err := variantKind(pass, v, enum)
+		if err != nil {
+			reportDeclf(pass, decl.Pos, "%s", err)
+			ok = false
+			continue
+		}
+		if other, dup := variants[m]; dup {
+			reportDeclf(pass, decl.Pos, "variants '%s' and '%s' of sum type '%s' "+
+				"have the same kind '%s'", other.Name(), v.Name(), def.Decl.TypeName, m.Name())
+			ok = false
+			continue
+		}
+		variants[m] = v
+		b.Kinds[v] = m
+	}
+	for _, m := range enum.Members {
+		if _, found := variants[m]; !found && ok {
+			reportDeclf(pass, decl.Pos, "member '%s' of enum '%s' is not the kind "+
+				"of any variant of sum type '%s'", m.Name(), decl.TypeName, def.Decl.TypeName)
+			ok = false
+		}
+	}
+	if !ok {
+		return nil
+	}
+	return b
+}
+
+// variantKind returns the member of enum returned by the method of the
+// variant v that has no parameters and returns a value of the enum's type.
+// The method must be declared in the current package, and consist of a
+// single return statement returning a constant.
+func variantKind(pass *analysis.Pass, v types.Object, enum *enumDef) (types.Object, error) {
+	var method *types.Func
+	mset := types.NewMethodSet(types.NewPointer(v.Type()))
+	for i := 0; i < mset.Len(); i++ {
+		fn, ok := mset.At(i).Obj().(*types.Func)
+		if !ok {
+			continue
+		}
+		sig := fn.Type().(*types.Signature)
+		if sig.Params().Len() != 0 || sig.Results().Len() != 1 ||
+			!types.Identical(sig.Results().At(0).Type(), enum.Ty) {
+			continue
+		}
+		if method != nil {
+			return nil, fmt.Errorf("variant '%s' has more than one method returning '%s'",
+				v.Name(), enum.Decl.TypeName)
+		}
+		method = fn
+	}
+	if method == nil {
+		return nil, fmt.Errorf("variant '%s' has no method returning '%s'",
+			v.Name(), enum.Decl.TypeName)
+	}
+
+	var body *ast.BlockStmt
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok && pass.TypesInfo.Defs[fd.Name] == method {
+				body = fd.Body
+			}
+		}
+	}
+	var val constant.Value
+	if body != nil && len(body.List) == 1 {
+		if ret, ok := body.List[0].(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+			val = pass.TypesInfo.Types[ret.Results[0]].Value
+		}
+	}
+	if val == nil {
+		return nil, fmt.Errorf("cannot determine the kind of variant '%s': "+
+			"method '%s' must only return a constant", v.Name(), method.Name())
+	}
+	for _, m := range enum.Members {
+		if constant.Compare(m.(*types.Const).Val(), token.EQL, val) {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("the kind of variant '%s' is not a member of enum '%s'",
+		v.Name(), enum.Decl.TypeName)
+}
+
+// importedBridgeDefs returns the bridges declared in the packages imported,
+// directly or indirectly, by the current package, whose sum types are among
+// the given (imported) definitions.
+func importedBridgeDefs(pass *analysis.Pass, defs []sumTypeDef) []bridgeDef {
+	var bridges []bridgeDef
+	for _, f := range pass.AllObjectFacts() {
+		fact, ok := f.Fact.(*bridgeFact)
+		if !ok || f.Object.Pkg() == pass.Pkg {
+			continue
+		}
+		var def *sumTypeDef
+		for i := range defs {
+			if defs[i].qualifiedName() == f.Object.Pkg().Path()+"."+fact.SumType {
+				def = &defs[i]
+			}
+		}
+		if def == nil {
+			continue
+		}
+		enum, err := buildEnumDef(f.Object, sumTypeDecl{
+			Kind:     declEnum,
+			Package:  f.Object.Pkg(),
+			TypeName: f.Object.Name(),
+		}, false)
+		if err != nil {
+			continue
+		}
+		b := bridgeDef{Decl: enum.Decl, Enum: enum, Def: def, Kinds: map[types.Object]types.Object{}}
+		for i, name := range fact.Members {
+			for _, m := range enum.Members {
+				if m.Name() != name {
+					continue
+				}
+				for _, v := range def.Variants {
+					if v.Name() == fact.Variants[i] {
+						b.Kinds[v] = m
+					}
+				}
+			}
+		}
+		bridges = append(bridges, b)
+	}
+	return bridges
+}
+
+// checkBridgeSwitch performs an exhaustiveness check on a switch over the
+// kind of a sum type value, i.e., over an enum bridged to a sum type. Missing
+// cases are reported in terms of the variants of the sum type, along with
+// their kinds. It returns true if the switch is over a bridged enum, in which
+// case it must not be checked as an ordinary enum switch.
+//
+// As with type switches, a default clause that doesn't always panic disables
+// the check.
+func checkBridgeSwitch(pass *analysis.Pass, res *Result, bridges []bridgeDef, swtch *ast.SwitchStmt) bool {
+	if swtch.Tag == nil {
+		return false
+	}
+	ty := pass.TypesInfo.TypeOf(swtch.Tag)
+	var b *bridgeDef
+	for i := range bridges {
+		if ty != nil && types.Identical(ty, bridges[i].Enum.Ty) {
+			b = &bridges[i]
+		}
+	}
+	if b == nil {
+		return false
+	}
+	exprs, hasDefault := caseExprs(swtch.Body)
+	if hasDefault && !defaultClauseTerminates(pass, swtch.Body) {
+		return true
+	}
+	missingKinds := map[types.Object]bool{}
+	for _, m := range missingMembers(b.Enum.Members, constValues(pass, exprs)) {
+		missingKinds[m] = true
+	}
+	var missing []types.Object
+	for _, v := range b.Def.Variants {
+		if missingKinds[b.Kinds[v]] {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) == 0 {
+		return true
+	}
+	for _, group := range groupMissing(missing) {
+		var cases, descs []string
+		for _, v := range group {
+			kind := qualifiedName(pass, swtch.Pos(), b.Kinds[v])
+			cases = append(cases, kind)
+			descs = append(descs, fmt.Sprintf("%s (%s)", v.Name(), kind))
+		}
+		res.report(pass, &Finding{
+			Diagnostic: analysis.Diagnostic{
+				Pos:      swtch.Pos(),
+				Category: CategoryExhaustiveness,
+				Message: fmt.Sprintf(
+					"exhaustiveness check failed for sum type '%s' by kind: missing cases for %s",
+					b.Def.Decl.TypeName, strings.Join(descs, ", ")),
+				Related: b.Def.Decl.related(),
+				SuggestedFixes: []analysis.SuggestedFix{
+					missingCasesFix(pass, swtch.Body, cases),
+				},
+			},
+			Type:    b.Def.qualifiedName(),
+			Missing: missingNames(group),
+		})
+	}
+	return true
+}
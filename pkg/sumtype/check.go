@@ -1,7 +1,9 @@
 package sumtype
 
 import (
+	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 	"sort"
 	"strings"
@@ -9,6 +11,30 @@ import (
 	"golang.org/x/tools/go/analysis"
 )
 
+// splitMissing is set with the -split-missing flag. When true, each variant
+// or member missing from a switch is reported as its own diagnostic, which
+// suits tools that track findings individually, e.g., code scanning.
+var splitMissing bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&splitMissing, "split-missing", false,
+		"report each variant or member missing from a switch as its own diagnostic")
+}
+
+// groupMissing returns the groups of missing variants or members that are
+// reported together: a single group with all of them, or one group for each
+// of them with the -split-missing flag.
+func groupMissing(missing []types.Object) [][]types.Object {
+	if !splitMissing {
+		return [][]types.Object{missing}
+	}
+	var groups [][]types.Object
+	for _, m := range missing {
+		groups = append(groups, []types.Object{m})
+	}
+	return groups
+}
+
 func missingNames(objs []types.Object) []string {
 	var list []string
 	for _, o := range objs {
@@ -18,61 +44,232 @@ func missingNames(objs []types.Object) []string {
 	return list
 }
 
+// switchInfo describes a type switch over a sum type.
+type switchInfo struct {
+	Stmt *ast.TypeSwitchStmt
+	Def  *sumTypeDef
+	// Missing are the variants without a case clause in the switch,
+	// regardless of whether the switch has a default clause.
+	Missing    []types.Object
+	HasDefault bool
+	// Checked is false when the switch has a default clause that doesn't
+	// terminate, e.g., by panicking, which disables exhaustiveness checks
+	// unless the policy for default clauses of the sum type disallows it.
+	Checked bool
+}
+
 // checkSwitch performs an exhaustiveness check on the given type switch
 // statement. If the type switch is used on a sum type and does not cover
 // all variants of that sum type, then an error is returned indicating which
 // variants were missed.
 //
-// Note that if the type switch contains a non-panicing default case, then
-// exhaustiveness checks are disabled.
+// Note that if the type switch contains a non-panicing default case, or if
+// the sum type is out of the scope given with -scope, then exhaustiveness
+// checks are disabled.
+//
+// If the type switch is used on a sum type, then a description of the switch
+// is returned. Otherwise, nil is returned.
 func checkSwitch(
 	pass *analysis.Pass,
+	res *Result,
 	defs []sumTypeDef,
+	params map[types.Object]*sumTypeDef,
 	swtch *ast.TypeSwitchStmt,
-) {
-	def, missing := missingVariantsInSwitch(pass, defs, swtch)
-	if len(missing) > 0 {
-		pass.Reportf(
-			swtch.Pos(),
-			"exhaustiveness check failed for sum type '%s': missing cases for %s",
-			def.Decl.TypeName, strings.Join(missingNames(missing), ", "))
+) *switchInfo {
+	sw := analyzeSwitch(pass, res, defs, params, swtch)
+	if sw == nil {
+		return nil
+	}
+	if sw.Checked && len(sw.Missing) > 0 && inScope(sw.Def) {
+		for _, group := range groupMissing(sw.Missing) {
+			missing := missingNames(group)
+			res.report(pass, &Finding{
+				Diagnostic: analysis.Diagnostic{
+					Pos:      swtch.Pos(),
+					Category: CategoryExhaustiveness,
+					Message: fmt.Sprintf(
+						"exhaustiveness check failed for sum type '%s': missing cases for %s",
+						sw.Def.Decl.TypeName, strings.Join(missing, ", ")),
+					Related:        sw.Def.Decl.related(),
+					SuggestedFixes: missingVariantsFix(pass, sw, group),
+				},
+				Type:    sw.Def.qualifiedName(),
+				Missing: missing,
+			})
+		}
+		if reportAtVariants {
+			reportUnhandledVariants(pass, res, sw)
+		}
+	}
+	return sw
+}
+
+// missingVariantsFix returns a suggested fix that inserts case clauses for the
+// given variants missing from a switch, or nil if their cases can't be
+// written (see variantCases).
+func missingVariantsFix(pass *analysis.Pass, sw *switchInfo, missing []types.Object) []analysis.SuggestedFix {
+	exprs, _ := caseExprs(sw.Stmt.Body)
+	cases, imports := variantCases(pass, sw.Def, sw.Stmt.Pos(), exprs, missing)
+	if cases == nil {
+		return nil
+	}
+	fix := missingCasesFix(pass, sw.Stmt.Body, cases)
+	fix.TextEdits = append(fix.TextEdits, imports...)
+	return []analysis.SuggestedFix{fix}
+}
+
+// variantCases returns the case expressions matching the given variants in a
+// type switch at pos whose other cases are exprs, and the edits adding any
+// imports they need. A variant is matched by a pointer to it if only its
+// pointer implements the sum type, or if exprs already match variants by
+// pointers. No cases are returned if a variant can't be named at pos, e.g.,
+// because it is unexported or generic.
+func variantCases(
+	pass *analysis.Pass,
+	def *sumTypeDef,
+	pos token.Pos,
+	exprs []ast.Expr,
+	missing []types.Object,
+) ([]string, []analysis.TextEdit) {
+	pointers := false
+	for _, expr := range exprs {
+		if _, ok := ast.Unparen(expr).(*ast.StarExpr); ok {
+			pointers = true
+		}
+	}
+	scope := pass.Pkg.Scope().Innermost(pos)
+	var imports []analysis.TextEdit
+	var cases []string
+	for _, v := range missing {
+		if _, ok := v.Type().(*types.Basic); ok {
+			// A predeclared type in the union of a constraint.
+			if !namesVariant(scope, pos, v.Name(), v) {
+				return nil, nil
+			}
+			cases = append(cases, v.Name())
+			continue
+		}
+		named, ok := v.Type().(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 || v.Pkg() != pass.Pkg && !v.Exported() {
+			return nil, nil
+		}
+		name := v.Name()
+		if v.Pkg() != pass.Pkg {
+			var qual string
+			qual, imports = importEdits(pass, pos, v.Pkg().Path(), v.Pkg().Name())
+			name = qual + name
+		}
+		if !namesVariant(scope, pos, name, v) {
+			return nil, nil
+		}
+		ptr := types.NewPointer(v.Type())
+		if !types.Implements(v.Type(), def.Ty) || pointers && types.Implements(ptr, def.Ty) {
+			name = "*" + name
+		}
+		cases = append(cases, name)
+	}
+	return cases, imports
+}
+
+// namesVariant returns true if name, possibly qualified by a package name,
+// refers to the variant v at pos in scope rather than to a local declaration
+// shadowing it.
+func namesVariant(scope *types.Scope, pos token.Pos, name string, v types.Object) bool {
+	if scope == nil {
+		return false
 	}
+	if i := strings.Index(name, "."); i >= 0 {
+		_, obj := scope.LookupParent(name[:i], pos)
+		pkgName, ok := obj.(*types.PkgName)
+		// A package that isn't imported yet can't be shadowed.
+		return obj == nil || ok && pkgName.Imported() == v.Pkg()
+	}
+	_, obj := scope.LookupParent(name, pos)
+	return obj == v || obj == nil
 }
 
-// missingVariantsInSwitch returns a list of missing variants corresponding to
-// the given switch statement. The corresponding sum type definition is also
-// returned. (If no sum type definition could be found, then no exhaustiveness
-// checks are performed, and therefore, no missing variants are returned.)
-func missingVariantsInSwitch(
+// analyzeSwitch finds the sum type definition corresponding to the given
+// switch statement and the variants it is missing. The sum type is either the
+// type of the switched value, the constraint of its type parameter when it is
+// converted to an interface, as in `any(v)`, the variants of a sum type
+// implementing it when it is an interface variant of that sum type, the sum
+// type bound to it when it is a parameter in params, or the one named by a
+// `go-sumtype:expect ...` directive. If no sum type definition could be
+// found, then nil is returned.
+func analyzeSwitch(
 	pass *analysis.Pass,
+	res *Result,
 	defs []sumTypeDef,
+	params map[types.Object]*sumTypeDef,
 	swtch *ast.TypeSwitchStmt,
-) (*sumTypeDef, []types.Object) {
+) *switchInfo {
 	asserted := findTypeAssertExpr(swtch)
 	ty := pass.TypesInfo.TypeOf(asserted)
+	if !resolved(ty) {
+		tracef(pass, res, swtch.Pos(), "switch is not checked: the type of the switched value could not be resolved")
+		return nil
+	}
 	def := findDef(defs, ty)
+	how := "the type of the switched value"
+	if tp := convertedTypeParam(pass, asserted); def == nil && tp != nil {
+		def = findDef(defs, tp)
+		how = "the constraint of the type parameter of the switched value"
+	}
 	if def == nil {
-		return nil, nil
+		def = narrowedDef(defs, ty)
+		how = "narrowing the switched value to an interface variant"
 	}
-
-	variantExprs, hasDefault := switchVariants(swtch)
-	if hasDefault && !defaultClauseAlwaysPanics(swtch) {
-		// A catch-all case defeats all exhaustiveness checks.
-		return def, nil
+	if def == nil {
+		def = boundDef(pass, params, swtch)
+		how = "a go-sumtype:param directive"
+	}
+	if def == nil {
+		def = expectedDef(pass, defs, swtch)
+		how = "a go-sumtype:expect directive"
+	}
+	if def == nil {
+		tracef(pass, res, swtch.Pos(), "switch is not checked: the switched value has type %s, "+
+			"which is not a sum type, and no directive names one",
+			types.TypeString(ty, types.RelativeTo(pass.Pkg)))
+		return nil
 	}
+	tracef(pass, res, swtch.Pos(), "switch matches sum type '%s' by %s", def.Decl.TypeName, how)
 
+	variantExprs, hasDefault := caseExprs(swtch.Body)
 	var variantTypes []types.Type
 	for _, expr := range variantExprs {
-		variantTypes = append(variantTypes, pass.TypesInfo.TypeOf(expr))
+		ty := pass.TypesInfo.TypeOf(expr)
+		if !resolved(ty) {
+			tracef(pass, res, expr.Pos(), "switch is not checked: the type of this case could not be resolved")
+			return nil
+		}
+		variantTypes = append(variantTypes, ty)
 	}
-
-	return def, def.missing(variantTypes)
+	if allowsGenerics(pass, swtch.Pos()) {
+		variantTypes = genericOrigins(variantTypes)
+	}
+	sw := &switchInfo{
+		Stmt:       swtch,
+		Def:        def,
+		Missing:    def.missing(variantTypes),
+		HasDefault: hasDefault,
+		// A catch-all case defeats all exhaustiveness checks, unless
+		// the policy for default clauses forbids it.
+		Checked: !hasDefault || defaultClauseTerminates(pass, swtch.Body) || def.defaultPolicy() != defaultAllow,
+	}
+	traceSwitch(pass, res, def, swtch, variantTypes, sw.Checked)
+	if len(sw.Missing) == 0 {
+		tracef(pass, res, swtch.Pos(), "switch covers every variant of '%s'", def.Decl.TypeName)
+	} else {
+		tracef(pass, res, swtch.Pos(), "switch is missing %s", strings.Join(missingNames(sw.Missing), ", "))
+	}
+	return sw
 }
 
-// switchVariants returns all case expressions found in a type switch. This
+// caseExprs returns all case expressions found in the body of a switch. This
 // includes expressions from cases that have a list of expressions.
-func switchVariants(swtch *ast.TypeSwitchStmt) (exprs []ast.Expr, hasDefault bool) {
-	for _, stmt := range swtch.Body.List {
+func caseExprs(body *ast.BlockStmt) (exprs []ast.Expr, hasDefault bool) {
+	for _, stmt := range body.List {
 		clause := stmt.(*ast.CaseClause)
 		if clause.List == nil {
 			hasDefault = true
@@ -83,41 +280,30 @@ func switchVariants(swtch *ast.TypeSwitchStmt) (exprs []ast.Expr, hasDefault boo
 	return
 }
 
-// defaultClauseAlwaysPanics returns true if the given switch statement has a
-// default clause that always panics. Note that this is done on a best-effort
-// basis. While there will never be any false positives, there may be false
-// negatives.
+// defaultClauseTerminates returns true if the given switch statement body
+// has a default clause that always panics or otherwise terminates, e.g., with
+// log.Fatal or os.Exit. Note that this is done on a best-effort basis. While
+// there will never be any false positives, there may be false negatives.
 //
-// If the given switch statement has no default clause, then this function
-// panics.
-func defaultClauseAlwaysPanics(swtch *ast.TypeSwitchStmt) bool {
-	var clause *ast.CaseClause
-	for _, stmt := range swtch.Body.List {
-		c := stmt.(*ast.CaseClause)
-		if c.List == nil {
-			clause = c
-			break
-		}
-	}
+// If the given switch statement body has no default clause, then this
+// function panics.
+func defaultClauseTerminates(pass *analysis.Pass, body *ast.BlockStmt) bool {
+	clause := defaultClause(body)
 	if clause == nil {
 		panic("switch statement has no default clause")
 	}
-	if len(clause.Body) != 1 {
-		return false
-	}
-	exprStmt, ok := clause.Body[0].(*ast.ExprStmt)
-	if !ok {
-		return false
-	}
-	callExpr, ok := exprStmt.X.(*ast.CallExpr)
-	if !ok {
-		return false
-	}
-	fun, ok := callExpr.Fun.(*ast.Ident)
-	if !ok {
-		return false
+	return terminatingCall(pass, clause) != nil
+}
+
+// defaultClause returns the default clause in the given switch statement
+// body, or nil if there is none.
+func defaultClause(body *ast.BlockStmt) *ast.CaseClause {
+	for _, stmt := range body.List {
+		if c := stmt.(*ast.CaseClause); c.List == nil {
+			return c
+		}
 	}
-	return fun.Name == "panic"
+	return nil
 }
 
 // findTypeAssertExpr extracts the expression that is being type asserted from a
@@ -132,6 +318,15 @@ func findTypeAssertExpr(swtch *ast.TypeSwitchStmt) ast.Expr {
 	return expr.(*ast.TypeAssertExpr).X
 }
 
+// resolved returns true if ty is known, which it is not for expressions that
+// could not be type-checked, e.g., in a package being edited.
+func resolved(ty types.Type) bool {
+	if ptr, ok := ty.(*types.Pointer); ok {
+		ty = ptr.Elem()
+	}
+	return ty != nil && ty != types.Typ[types.Invalid]
+}
+
 // findDef returns the sum type definition corresponding to the given type. If
 // no such sum type definition exists, then nil is returned.
 func findDef(defs []sumTypeDef, needle types.Type) *sumTypeDef {
@@ -0,0 +1,90 @@
+package sumtype
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// isComposed returns true if this decl declares a composed sum type, e.g.,
+// `go-sumtype:decl Node = Expr | Stmt`.
+func (decl sumTypeDecl) isComposed() bool {
+	return decl.Kind == declSumType && len(decl.Options) > 0 && decl.Options[0] == "="
+}
+
+// partNames returns the names of the sum types making up a composed sum type,
+// which follow the `=` and are separated by `|`, with or without white space.
+func (decl sumTypeDecl) partNames() []string {
+	var names []string
+	for _, name := range strings.Split(strings.Join(decl.Options[1:], " "), "|") {
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
+
+// composeSumTypeDef makes def, declared as a composed sum type, up of the
+// sum types it names among the given ones, which must be declared in the same
+// package and implement def. Its variants become the union of theirs. If the
+// declaration is invalid, then this is reported and false is returned.
+func composeSumTypeDef(pass *analysis.Pass, def *sumTypeDef, defs []sumTypeDef) bool {
+	names := def.Decl.partNames()
+	for _, name := range names {
+		if name == "" {
+			reportDeclf(pass, def.Decl.Pos, "composed sum type '%s' requires the names of "+
+				"sum types separated by '|'", def.Decl.TypeName)
+			return false
+		}
+	}
+	seen := map[types.Object]bool{}
+	def.Variants = nil
+	for _, name := range names {
+		part := findDefByName(defs, name)
+		if part == nil || part.Decl.Package != def.Decl.Package {
+			reportDeclf(pass, def.Decl.Pos, "'%s' in the declaration of '%s' is not a sum type "+
+				"declared in this package", name, def.Decl.TypeName)
+			return false
+		}
+		partTy := part.Decl.Package.Scope().Lookup(name).Type()
+		if !types.Implements(partTy, def.Ty) {
+			reportDeclf(pass, def.Decl.Pos, "sum type '%s' does not implement '%s'",
+				name, def.Decl.TypeName)
+			return false
+		}
+		def.Parts = append(def.Parts, *part)
+		for _, v := range part.Variants {
+			if !seen[v] {
+				seen[v] = true
+				def.Variants = append(def.Variants, v)
+			}
+		}
+	}
+	sort.Slice(def.Variants, func(i, j int) bool {
+		return def.Variants[i].Name() < def.Variants[j].Name()
+	})
+	return true
+}
+
+// expandParts returns the given types of case clauses with the variants of
+// each part of this sum type that is among them added, so that a case naming
+// a part covers all of its variants.
+func (def *sumTypeDef) expandParts(tys []types.Type) []types.Type {
+	if len(def.Parts) == 0 {
+		return tys
+	}
+	expanded := append([]types.Type(nil), tys...)
+	for _, ty := range tys {
+		if ty == nil {
+			continue
+		}
+		part := findDef(def.Parts, ty)
+		if part == nil {
+			continue
+		}
+		for _, v := range part.Variants {
+			expanded = append(expanded, v.Type())
+		}
+	}
+	return expanded
+}
@@ -0,0 +1,149 @@
+package sumtype
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"os"
+	"strings"
+	"sync"
+)
+
+// configPath is the path to a configuration file, set with the -config flag.
+var configPath string
+
+func init() {
+	Analyzer.Flags.StringVar(&configPath, "config", "",
+		"path to a JSON configuration file")
+}
+
+// config is the contents of a go-sumtype configuration file.
+type config struct {
+	// Enums declares enums whose types are defined outside of the packages
+	// being analyzed, e.g., in the standard library or a dependency.
+	Enums []externalEnum `json:"enums"`
+	// IgnoreVariants are regular expressions matching the names of types
+	// that are never variants, like the -ignore-variants flag.
+	IgnoreVariants []string `json:"ignoreVariants"`
+	// FixBody is the template of the body of case clauses inserted by
+	// suggested fixes, like the -fix-body flag.
+	FixBody string `json:"fixBody"`
+}
+
+// externalEnum is an enum declared in a configuration file rather than with
+// a `go-sumtype:enum ...` directive.
+type externalEnum struct {
+	// Type is the fully qualified name of the enum type, e.g., time.Weekday
+	// or github.com/foo/bar.Kind.
+	Type string `json:"type"`
+	// Members, when non-empty, restricts the members of the enum to the
+	// constants with these names.
+	Members []string `json:"members"`
+	// Options are the same options accepted by `go-sumtype:enum ...`.
+	Options []string `json:"options"`
+}
+
+var (
+	configMu    sync.Mutex
+	configCache = map[string]*config{}
+)
+
+// loadConfig reads the configuration file given by the -config flag. The
+// file is only read once per process. If no configuration file was given,
+// then an empty configuration is returned.
+func loadConfig() (*config, error) {
+	if configPath == "" {
+		return &config{}, nil
+	}
+	configMu.Lock()
+	defer configMu.Unlock()
+	if cfg, ok := configCache[configPath]; ok {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %v", err)
+	}
+	cfg := &config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config '%s': %v", configPath, err)
+	}
+	configCache[configPath] = cfg
+	return cfg, nil
+}
+
+// findExternalEnumDefs builds an enum definition for each enum in the
+// configuration whose package is the given package or one of its (transitive)
+// imports. Enums in packages that are not reachable are skipped, since they
+// cannot be used by the package.
+func findExternalEnumDefs(pkg *types.Package, cfg *config) ([]enumDef, error) {
+	var defs []enumDef
+	for _, enum := range cfg.Enums {
+		i := strings.LastIndex(enum.Type, ".")
+		if i <= 0 || i == len(enum.Type)-1 {
+			return nil, fmt.Errorf("config: invalid enum type '%s' "+
+				"(expected a qualified name like time.Weekday)", enum.Type)
+		}
+		path, name := enum.Type[:i], enum.Type[i+1:]
+		depPkg := findImport(pkg, path)
+		if depPkg == nil {
+			continue
+		}
+		obj := depPkg.Scope().Lookup(name)
+		if obj == nil {
+			return nil, fmt.Errorf("config: type '%s' is not defined", enum.Type)
+		}
+		decl := sumTypeDecl{
+			Kind:     declEnum,
+			Package:  depPkg,
+			TypeName: enum.Type,
+			Options:  enum.Options,
+		}
+		def, err := buildEnumDef(obj, decl, depPkg != pkg)
+		if err != nil {
+			return nil, fmt.Errorf("config: %v", err)
+		}
+		if len(enum.Members) > 0 {
+			byName := map[string]types.Object{}
+			for _, m := range def.Members {
+				byName[m.Name()] = m
+			}
+			var members []types.Object
+			for _, name := range enum.Members {
+				m, ok := byName[name]
+				if !ok {
+					return nil, fmt.Errorf("config: enum '%s' has no member '%s'",
+						enum.Type, name)
+				}
+				members = append(members, m)
+			}
+			def.Members = members
+		}
+		defs = append(defs, *def)
+	}
+	return defs, nil
+}
+
+// findImport returns the package with the given path if it is pkg itself or
+// any package transitively imported by pkg. If no such package exists, then
+// nil is returned.
+func findImport(pkg *types.Package, path string) *types.Package {
+	seen := map[*types.Package]bool{}
+	var find func(p *types.Package) *types.Package
+	find = func(p *types.Package) *types.Package {
+		if seen[p] {
+			return nil
+		}
+		seen[p] = true
+		if p.Path() == path {
+			return p
+		}
+		for _, imp := range p.Imports() {
+			if found := find(imp); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return find(pkg)
+}
@@ -0,0 +1,149 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype/directive"
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkFactories checks the factory functions declared with a
+// `go-sumtype:constructs Type [except Variant...]` directive, written in or
+// above the function, e.g., a parser. Every variant of the sum type that is
+// not listed after `except` must be constructed by the function, which catches
+// factories that can never produce a newly added variant.
+//
+// A variant is constructed if it is the static type of an expression returned
+// by the function, with or without a pointer, or of a composite literal in its
+// body. Function literals in the body are not considered.
+func checkFactories(pass *analysis.Pass, res *Result, defs []sumTypeDef) {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			for _, d := range declDirectives(file, fn, fn.Doc) {
+				if d.Kind != directive.Constructs {
+					continue
+				}
+				def, exempt, ok := factoryDef(pass, defs, d)
+				if !ok {
+					continue
+				}
+				reportFactory(pass, res, def, fn, exempt, constructedTypes(pass, fn.Body))
+			}
+		}
+	}
+}
+
+// factoryDef returns the sum type named by the given `go-sumtype:constructs`
+// directive and the variants it exempts. If the directive is invalid, then
+// this is reported and false is returned.
+func factoryDef(
+	pass *analysis.Pass,
+	defs []sumTypeDef,
+	d directive.Directive,
+) (*sumTypeDef, map[types.Object]bool, bool) {
+	if len(d.Args) == 0 || len(d.Args) == 2 || len(d.Args) > 2 && d.Args[1] != "except" {
+		reportDeclf(pass, d.Pos, "constructs directive requires a sum type name, "+
+			"optionally followed by 'except' and the names of variants")
+		return nil, nil, false
+	}
+	def, err := lookupDef(pass, defs, d.Pos, d.Args[0])
+	if err != nil {
+		reportDeclf(pass, d.Pos, "constructs directive: %v", err)
+		return nil, nil, false
+	}
+	if def == nil {
+		reportDeclf(pass, d.Pos, "constructs directive names '%s', which is not a sum type",
+			d.Args[0])
+		return nil, nil, false
+	}
+	exempt := map[types.Object]bool{}
+	ok := true
+	for _, name := range d.Args[min(len(d.Args), 2):] {
+		v := findVariantByName(def, name)
+		if v == nil {
+			reportDeclf(pass, d.Pos, "'%s' is not a variant of sum type '%s'",
+				name, def.Decl.TypeName)
+			ok = false
+			continue
+		}
+		exempt[v] = true
+	}
+	return def, exempt, ok
+}
+
+// findVariantByName returns the variant of def with the given name, or nil.
+func findVariantByName(def *sumTypeDef, name string) types.Object {
+	for _, v := range def.Variants {
+		if v.Name() == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// constructedTypes returns the static types of the expressions returned in
+// body and of the composite literals in it, outside of function literals.
+func constructedTypes(pass *analysis.Pass, body *ast.BlockStmt) []types.Type {
+	var tys []types.Type
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			for _, result := range n.Results {
+				if ty := pass.TypesInfo.TypeOf(result); ty != nil {
+					tys = append(tys, ty)
+				}
+			}
+		case *ast.CompositeLit:
+			if ty := pass.TypesInfo.TypeOf(n); ty != nil {
+				tys = append(tys, ty)
+			}
+		}
+		return true
+	})
+	return tys
+}
+
+// reportFactory reports the variants of def that are neither constructed by
+// the factory function fn, as given by the constructed types, nor exempt.
+func reportFactory(
+	pass *analysis.Pass,
+	res *Result,
+	def *sumTypeDef,
+	fn *ast.FuncDecl,
+	exempt map[types.Object]bool,
+	constructed []types.Type,
+) {
+	if allowsGenerics(pass, fn.Pos()) {
+		constructed = genericOrigins(constructed)
+	}
+	var missing []types.Object
+	for _, v := range def.missing(constructed) {
+		if !exempt[v] {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+	names := missingNames(missing)
+	res.report(pass, &Finding{
+		Diagnostic: analysis.Diagnostic{
+			Pos:      fn.Name.Pos(),
+			Category: CategoryConstructs,
+			Message: fmt.Sprintf("factory '%s' for sum type '%s' never constructs %s",
+				fn.Name.Name, def.Decl.TypeName, strings.Join(names, ", ")),
+			Related: def.Decl.related(),
+		},
+		Type:    def.qualifiedName(),
+		Missing: names,
+	})
+}
@@ -1,117 +1,154 @@
 package sumtype
 
 import (
-	"bufio"
-	"bytes"
+	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
-	"log"
-	"os"
 	"path/filepath"
-	"regexp"
+	"sort"
+	"strings"
 
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype/directive"
 	"golang.org/x/tools/go/analysis"
 )
 
-// sumTypeDecl is a declaration of a sum type in a Go source file.
+// declKind distinguishes the kinds of declarations go-sumtype understands.
+type declKind int
+
+const (
+	// declSumType is a `go-sumtype:decl ...` declaration.
+	declSumType declKind = iota
+	// declEnum is a `go-sumtype:enum ...` declaration.
+	declEnum
+	// declBridge is a `go-sumtype:bridge ...` declaration, whose TypeName is
+	// the enum and whose only option is the sum type.
+	declBridge
+	// declRequire is a `go-sumtype:require ...` declaration, whose TypeName
+	// is the sum type and whose options are the interfaces its variants must
+	// implement.
+	declRequire
+)
+
+// sumTypeDecl is a declaration of a sum type (or an enum) in a Go source file.
 type sumTypeDecl struct {
+	// The kind of this decl.
+	Kind declKind
 	// The package path that contains this decl.
 	Package *types.Package
 	// The type named by this decl.
 	TypeName string
-	// Position of the declaration
+	// Any options given after the type name, e.g., `bitflag`.
+	Options []string
+	// Position of the directive declaring this decl.
 	Pos token.Pos
 }
 
+// hasOption returns true if and only if the given option was provided
+// with this decl.
+func (decl sumTypeDecl) hasOption(name string) bool {
+	for _, opt := range decl.Options {
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+// related returns information pointing at the directive declaring this decl,
+// for use in diagnostics about its uses. Decls without a directive, e.g.,
+// enums declared in a configuration file, have no related information.
+func (decl sumTypeDecl) related() []analysis.RelatedInformation {
+	if !decl.Pos.IsValid() {
+		return nil
+	}
+	what := "sum type"
+	if decl.Kind == declEnum {
+		what = "enum"
+	}
+	return []analysis.RelatedInformation{{
+		Pos:     decl.Pos,
+		Message: fmt.Sprintf("%s '%s' declared here", what, decl.TypeName),
+	}}
+}
+
 type filesToPkg map[*ast.File]*types.Package
 
 // findSumTypeDecls searches every package given for sum type declarations of
-// the form `go-sumtype:decl ...`.
+// the form `go-sumtype:decl ...`, enum declarations of the form
+// `go-sumtype:enum ...`, bridge declarations of the form
+// `go-sumtype:bridge ...` and requirements of the form
+// `go-sumtype:require ...`.
+//
+// Declarations are read from the comments of the parsed files rather than
+// from the files on disk, so that they always agree with the code being
+// checked, e.g., when unsaved editor buffers are analyzed through an overlay.
+// Like other Go directives, a declaration must start at the beginning of a
+// line.
 func findSumTypeDecls(pass *analysis.Pass, ftp filesToPkg) []sumTypeDecl {
-	var decls []sumTypeDecl
-	for file, pkg := range ftp {
-		pos := pass.Fset.Position(file.Pos())
-		filename := pos.Filename
-		if filepath.Base(filename) == "C" {
+	var files []*ast.File
+	for file := range ftp {
+		if filepath.Base(pass.Fset.Position(file.Pos()).Filename) == "C" {
 			// ignore (fake?) cgo files
 			continue
 		}
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Pos() < files[j].Pos() })
 
-		fileDecls, err := sumTypeDeclSearch(filename)
-		if err != nil {
-			pass.Reportf(
-				file.Pos(),
-				"unknown error reading file '%s': %v",
-				file.Name.String(), err)
-			return nil
-		}
-		for i := range fileDecls {
-			fileDecls[i].Package = pkg
-			obj := pkg.Scope().Lookup(fileDecls[i].TypeName)
-			if obj == nil {
-				// TODO(ifross89): need to figure out how to create a more accurate position
-				fileDecls[i].Pos = file.Pos()
-			} else {
-				fileDecls[i].Pos = obj.Pos()
+	var decls []sumTypeDecl
+	for _, file := range files {
+		for _, cg := range file.Comments {
+			for _, c := range cg.List {
+				if !strings.HasPrefix(c.Text, directive.Prefix) {
+					continue
+				}
+				if pass.Fset.PositionFor(c.Slash, false).Column != 1 {
+					continue
+				}
+				decl, ok := parseSumTypeDecl(c.Text)
+				if !ok {
+					continue
+				}
+				decl.Package = ftp[file]
+				decl.Pos = c.Slash
+				decls = append(decls, decl)
 			}
 		}
-		decls = append(decls, fileDecls...)
 	}
 	return decls
 }
 
-// sumTypeDeclSearch searches the given file for sum type declarations of the
-// form `go-sumtype:decl ...`.
-func sumTypeDeclSearch(path string) ([]sumTypeDecl, error) {
-	var decls []sumTypeDecl
-
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer f.Close()
-	lineNum := 0
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		lineNum++
-		line := scanner.Bytes()
-		if !isSumTypeDecl(line) {
-			continue
-		}
-		ty := parseSumTypeDecl(line)
-		if len(ty) == 0 {
-			continue
-		}
-		decls = append(decls, sumTypeDecl{
-			TypeName: ty,
-		})
-	}
-	if err := scanner.Err(); err != nil {
-		// A scanner can puke if it hits a line that is too long.
-		// We assume such files won't contain any future decls and
-		// otherwise move on.
-		log.Printf("scan error reading '%s': %s", path, err)
-	}
-	return decls, f.Close()
+// reportDeclf reports a problem with a declaration.
+func reportDeclf(pass *analysis.Pass, pos token.Pos, format string, args ...interface{}) {
+	pass.Report(analysis.Diagnostic{
+		Pos:      pos,
+		Category: CategoryDeclaration,
+		Message:  fmt.Sprintf(format, args...),
+	})
 }
 
-var reParseSumTypeDecl = regexp.MustCompile(`^//go-sumtype:decl\s+(\S+)\s*$`)
-
-// parseSumTypeDecl parses the type name out of a sum type decl.
+// parseSumTypeDecl parses the kind, type name and options out of a sum type
+// or enum decl.
 //
-// If no such decl could be found, then this returns an empty string.
-func parseSumTypeDecl(line []byte) string {
-	caps := reParseSumTypeDecl.FindSubmatch(line)
-	if len(caps) < 2 {
-		return ""
+// If no such decl could be found, then this returns false.
+func parseSumTypeDecl(line string) (sumTypeDecl, bool) {
+	d, ok := directive.ParseLine(line)
+	if !ok || len(d.Args) == 0 {
+		return sumTypeDecl{}, false
 	}
-	return string(caps[1])
-}
-
-// isSumTypeDecl returns true if and only if this line in a Go source file
-// is a sum type decl.
-func isSumTypeDecl(line []byte) bool {
-	variant1, variant2 := []byte("//go-sumtype:decl "), []byte("//go-sumtype:decl\t")
-	return bytes.HasPrefix(line, variant1) || bytes.HasPrefix(line, variant2)
+	decl := sumTypeDecl{TypeName: d.Args[0], Options: d.Args[1:]}
+	switch d.Kind {
+	case directive.Decl:
+		decl.Kind = declSumType
+	case directive.Enum:
+		decl.Kind = declEnum
+	case directive.Bridge:
+		decl.Kind = declBridge
+	case directive.Require:
+		decl.Kind = declRequire
+	default:
+		return sumTypeDecl{}, false
+	}
+	return decl, true
 }
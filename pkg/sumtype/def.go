@@ -2,6 +2,7 @@ package sumtype
 
 import (
 	"go/types"
+	"strings"
 
 	"golang.org/x/tools/go/analysis"
 )
@@ -13,21 +14,45 @@ type sumTypeDef struct {
 	Decl     sumTypeDecl
 	Ty       *types.Interface
 	Variants []types.Object
+	// Parts are the sum types making up a composed sum type, declared with
+	// `go-sumtype:decl Node = Expr | Stmt`, whose variants are the union of
+	// theirs. A case naming a part covers all of its variants.
+	Parts []sumTypeDef
+	// Parent is the sum type this one was narrowed from, if it is made up
+	// of the variants implementing an interface variant of Parent. See
+	// subInterface.
+	Parent *sumTypeDef
 }
 
 // findSumTypeDefs attempts to find a Go type definition for each of the given
 // sum type declarations. If no such sum type definition could be found for
 // any of the given declarations an error is reported and it is not added to
 // the returned slice
+//
+// Composed sum types are defined last, since they are made up of the others.
 func findSumTypeDefs(pass *analysis.Pass, decls []sumTypeDecl) []sumTypeDef {
 	var defs []sumTypeDef
 	for _, decl := range decls {
+		if decl.Kind != declSumType || decl.isComposed() {
+			continue
+		}
 		def := newSumTypeDef(pass, decl.Package, decl)
 		if def == nil {
 			continue
 		}
 		defs = append(defs, *def)
 	}
+	parts := defs
+	for _, decl := range decls {
+		if decl.Kind != declSumType || !decl.isComposed() {
+			continue
+		}
+		def := newSumTypeDef(pass, decl.Package, decl)
+		if def == nil || !composeSumTypeDef(pass, def, parts) {
+			continue
+		}
+		defs = append(defs, *def)
+	}
 	return defs
 }
 
@@ -36,18 +61,43 @@ func findSumTypeDefs(pass *analysis.Pass, decls []sumTypeDecl) []sumTypeDef {
 // returns a nil def and an error is reported.
 //
 // If the decl corresponds to a type that isn't an interface containing at
-// least one unexported method, an error is reported.
+// least one unexported method, or a constraint with a union of named types,
+// an error is reported.
 func newSumTypeDef(pass *analysis.Pass, pkg *types.Package, decl sumTypeDecl) *sumTypeDef {
 	obj := pkg.Scope().Lookup(decl.TypeName)
 	if obj == nil {
-		pass.Reportf(decl.Pos, "type '%s' is not defined", decl.TypeName)
+		reportDeclf(pass, decl.Pos, "type '%s' is not defined", decl.TypeName)
 		return nil
 	}
 	iface, ok := obj.Type().Underlying().(*types.Interface)
 	if !ok {
-		pass.Reportf(decl.Pos, "type '%s' is not an interface", decl.TypeName)
+		reportDeclf(pass, decl.Pos, "type '%s' is not an interface", decl.TypeName)
 		return nil
 	}
+	if !decl.isComposed() {
+		for _, opt := range decl.Options {
+			if policy, ok := strings.CutPrefix(opt, defaultOptionPrefix); ok {
+				if !validDefaultPolicy(policy) {
+					reportDeclf(pass, decl.Pos, "invalid default clause policy '%s' for sum type '%s' "+
+						"(expected allow, must-panic or forbid)", policy, decl.TypeName)
+					return nil
+				}
+				continue
+			}
+			if opt != nilOption {
+				reportDeclf(pass, decl.Pos, "unknown option '%s' for sum type '%s'", opt, decl.TypeName)
+				return nil
+			}
+		}
+	}
+	if !iface.IsMethodSet() {
+		variants, err := unionVariants(iface)
+		if err != nil {
+			reportDeclf(pass, decl.Pos, "constraint '%s' is not a sum type: %v", decl.TypeName, err)
+			return nil
+		}
+		return &sumTypeDef{Decl: decl, Ty: iface, Variants: variants}
+	}
 	hasUnexported := false
 	for i := 0; i < iface.NumMethods(); i++ {
 		if !iface.Method(i).Exported() {
@@ -56,18 +106,52 @@ func newSumTypeDef(pass *analysis.Pass, pkg *types.Package, decl sumTypeDecl) *s
 		}
 	}
 	if !hasUnexported {
-		pass.Reportf(decl.Pos, "interface '%s' is not sealed "+
+		reportDeclf(pass, decl.Pos, "interface '%s' is not sealed "+
 			"(sealing requires at least one unexported method)",
 			decl.TypeName)
 		return nil
 	}
-	def := &sumTypeDef{
-		Decl: decl,
-		Ty:   iface,
+	return &sumTypeDef{
+		Decl:     decl,
+		Ty:       iface,
+		Variants: variantsOf(pkg, iface),
 	}
+}
+
+// VariantsOf returns the variants of the sum type iface, using the same rules
+// as the Analyzer: a variant is any type declared at the top level of pkg,
+// other than iface itself, that implements iface either directly or through
+// a pointer to it. If iface is a constraint with a union of types, e.g.,
+// `interface{ Circle | Square }`, then its variants are instead the types of
+// the union, which may be declared in any package. The variants are sorted by
+// name.
+//
+// If iface is not an interface, or is a constraint whose union isn't a sum
+// type, e.g., because it has a term like ~int, then nil is returned. Note that
+// VariantsOf does not check whether iface is sealed or declared as a sum type.
+func VariantsOf(pkg *types.Package, iface *types.Named) []types.Object {
+	it, ok := iface.Underlying().(*types.Interface)
+	if !ok {
+		return nil
+	}
+	if !it.IsMethodSet() {
+		variants, err := unionVariants(it)
+		if err != nil {
+			return nil
+		}
+		return variants
+	}
+	return variantsOf(pkg, it)
+}
+
+// variantsOf implements VariantsOf for the underlying interface type of a sum
+// type.
+func variantsOf(pkg *types.Package, iface *types.Interface) []types.Object {
+	var variants []types.Object
 	for _, name := range pkg.Scope().Names() {
 		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
-		if !ok {
+		if !ok || obj.IsAlias() {
+			// An alias of a variant is not a variant of its own.
 			continue
 		}
 		ty := obj.Type()
@@ -75,21 +159,28 @@ func newSumTypeDef(pass *analysis.Pass, pkg *types.Package, decl sumTypeDecl) *s
 			continue
 		}
 		if types.Implements(ty, iface) || types.Implements(types.NewPointer(ty), iface) {
-			def.Variants = append(def.Variants, obj)
+			variants = append(variants, obj)
 		}
 	}
-	return def
+	return variants
 }
 
 func (def *sumTypeDef) String() string {
 	return def.Decl.TypeName
 }
 
+// qualifiedName returns the name of this sum type qualified by the path of
+// the package declaring it.
+func (def *sumTypeDef) qualifiedName() string {
+	return def.Decl.Package.Path() + "." + def.Decl.TypeName
+}
+
 // missing returns a list of variants in this sum type that are not in the
 // given list of types.
 func (def *sumTypeDef) missing(tys []types.Type) []types.Object {
 	// TODO(ag): This is O(n^2). Fix that. /shrug
 	var missing []types.Object
+	tys = def.expandSubInterfaces(def.expandParts(tys))
 	for _, v := range def.Variants {
 		found := false
 		varty := indirect(v.Type())
@@ -103,13 +194,29 @@ func (def *sumTypeDef) missing(tys []types.Type) []types.Object {
 			missing = append(missing, v)
 		}
 	}
-	return missing
+	return def.withoutSubInterfaces(missing)
 }
 
-// indirect dereferences through an arbitrary number of pointer types.
+// indirect dereferences through an arbitrary number of pointer types,
+// resolving any aliases along the way.
 func indirect(ty types.Type) types.Type {
+	ty = types.Unalias(ty)
 	if ty, ok := ty.(*types.Pointer); ok {
 		return indirect(ty.Elem())
 	}
 	return ty
 }
+
+// genericOrigins replaces each instantiation of a generic type among the
+// given types, e.g., `*Box[int]`, with the generic type itself, so that a case
+// clause for any instantiation of a generic variant covers the variant.
+func genericOrigins(tys []types.Type) []types.Type {
+	origins := make([]types.Type, len(tys))
+	for i, ty := range tys {
+		origins[i] = ty
+		if named, ok := indirect(ty).(*types.Named); ok && named.TypeArgs().Len() > 0 {
+			origins[i] = named.Origin()
+		}
+	}
+	return origins
+}
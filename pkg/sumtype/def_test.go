@@ -0,0 +1,70 @@
+package sumtype
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestVariantsOf(t *testing.T) {
+	const src = `package p
+
+type T interface{ sealed() }
+
+type A struct{}
+
+func (*A) sealed() {}
+
+type B int
+
+func (B) sealed() {}
+
+type NotVariant struct{}
+
+type Shape interface{ Square | Circle | int }
+
+type Square struct{}
+
+type Circle struct{}
+
+type Approx interface{ ~int }
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iface := pkg.Scope().Lookup("T").Type().(*types.Named)
+	var got []string
+	for _, v := range VariantsOf(pkg, iface) {
+		got = append(got, v.Name())
+	}
+	if len(got) != 2 || got[0] != "A" || got[1] != "B" {
+		t.Errorf("VariantsOf(T) = %v; want [A B]", got)
+	}
+
+	shape := pkg.Scope().Lookup("Shape").Type().(*types.Named)
+	got = nil
+	for _, v := range VariantsOf(pkg, shape) {
+		got = append(got, v.Name())
+	}
+	if len(got) != 3 || got[0] != "Circle" || got[1] != "Square" || got[2] != "int" {
+		t.Errorf("VariantsOf(Shape) = %v; want [Circle Square int]", got)
+	}
+
+	approx := pkg.Scope().Lookup("Approx").Type().(*types.Named)
+	if vs := VariantsOf(pkg, approx); vs != nil {
+		t.Errorf("VariantsOf(Approx) = %v; want nil", vs)
+	}
+
+	notIface := pkg.Scope().Lookup("A").Type().(*types.Named)
+	if vs := VariantsOf(pkg, notIface); vs != nil {
+		t.Errorf("VariantsOf(A) = %v; want nil", vs)
+	}
+}
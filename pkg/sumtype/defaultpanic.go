@@ -0,0 +1,140 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// requireDefaultValue is set with the -require-default-value flag. When true,
+// a panicking default clause of a type switch over a sum type must mention
+// the switched value in its call to panic, so that the unexpected variant can
+// be identified from the panic message.
+var requireDefaultValue bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&requireDefaultValue, "require-default-value", false,
+		"require panicking default clauses of switches over sum types to mention the switched value")
+}
+
+// checkDefaultPanic reports the given type switch over a sum type if it has
+// a default clause that panics, or otherwise terminates, without mentioning
+// the switched value, e.g., in the message of a call to log.Fatalf or in a
+// statement before a call to os.Exit. When the argument of a panic is a string
+// literal and the switched value can be named, a fix is suggested that
+// includes the value's type in the message.
+func checkDefaultPanic(pass *analysis.Pass, res *Result, sw *switchInfo) {
+	if !sw.HasDefault {
+		return
+	}
+	clause := defaultClause(sw.Stmt.Body)
+	call := terminatingCall(pass, clause)
+	if call == nil {
+		return
+	}
+	value, name := switchedValue(pass, sw.Stmt, clause)
+	if mentions(pass, clause.Body, value) {
+		return
+	}
+	verb := "terminates"
+	if isPanic(call) {
+		verb = "panics"
+	}
+	d := analysis.Diagnostic{
+		Pos:      clause.Pos(),
+		Category: CategoryDefaultPanic,
+		Message: fmt.Sprintf("default clause of switch over sum type '%s' "+
+			"%s without mentioning the switched value", sw.Def.Decl.TypeName, verb),
+		Related: sw.Def.Decl.related(),
+	}
+	if lit, ok := panicLiteral(call); ok && isPanic(call) && name != "" {
+		d.SuggestedFixes = []analysis.SuggestedFix{
+			panicValueFix(pass, call, lit, name),
+		}
+	}
+	res.report(pass, &Finding{Diagnostic: d, Type: sw.Def.qualifiedName()})
+}
+
+// switchedValue returns the object holding the value switched on in the
+// given clause of a type switch, along with the expression naming it. This is
+// the variable bound by the switch, e.g., `x` in `switch x := v.(type)`, or
+// the variable being asserted otherwise. If the switched value is not held
+// by a variable, e.g., in `switch f().(type)`, then this returns nil and an
+// empty name.
+func switchedValue(
+	pass *analysis.Pass,
+	swtch *ast.TypeSwitchStmt,
+	clause *ast.CaseClause,
+) (types.Object, string) {
+	if assign, ok := swtch.Assign.(*ast.AssignStmt); ok {
+		if obj := pass.TypesInfo.Implicits[clause]; obj != nil {
+			return obj, assign.Lhs[0].(*ast.Ident).Name
+		}
+	}
+	switch x := ast.Unparen(findTypeAssertExpr(swtch)).(type) {
+	case *ast.Ident:
+		return pass.TypesInfo.Uses[x], x.Name
+	case *ast.SelectorExpr:
+		if _, ok := x.X.(*ast.Ident); ok {
+			return pass.TypesInfo.Uses[x.Sel], types.ExprString(x)
+		}
+	}
+	return nil, ""
+}
+
+// mentions returns true if any of the given statements refers to obj.
+func mentions(pass *analysis.Pass, stmts []ast.Stmt, obj types.Object) bool {
+	if obj == nil {
+		return false
+	}
+	found := false
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && pass.TypesInfo.Uses[id] == obj {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// panicLiteral returns the string literal passed to the given call to panic,
+// if its argument is a string literal.
+func panicLiteral(call *ast.CallExpr) (*ast.BasicLit, bool) {
+	if len(call.Args) != 1 {
+		return nil, false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	return lit, ok && lit.Kind == token.STRING
+}
+
+// panicValueFix returns a suggested fix that rewrites `panic("msg")` into
+// `panic(fmt.Sprintf("msg: %T", name))`, importing fmt if necessary.
+func panicValueFix(
+	pass *analysis.Pass,
+	call *ast.CallExpr,
+	lit *ast.BasicLit,
+	name string,
+) analysis.SuggestedFix {
+	msg, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		msg = ""
+	}
+	format := strconv.Quote(strings.ReplaceAll(msg, "%", "%%") + ": %T")
+	qual, edits := importEdits(pass, call.Pos(), "fmt", "fmt")
+	edits = append(edits, analysis.TextEdit{
+		Pos:     lit.Pos(),
+		End:     lit.End(),
+		NewText: []byte(fmt.Sprintf("%sSprintf(%s, %s)", qual, format, name)),
+	})
+	return analysis.SuggestedFix{
+		Message:   fmt.Sprintf("Include the type of %s in the panic message", name),
+		TextEdits: edits,
+	}
+}
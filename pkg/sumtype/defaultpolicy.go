@@ -0,0 +1,96 @@
+package sumtype
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// defaultPolicy is set with the -default flag. It is the policy for default
+// clauses of type switches over sum types, unless the declaration of a sum
+// type gives its own with a `default=...` option.
+var defaultPolicy string
+
+func init() {
+	Analyzer.Flags.StringVar(&defaultPolicy, "default", defaultAllow,
+		"the policy for default clauses of switches over sum types: allow, must-panic or forbid")
+}
+
+// The policies for default clauses accepted by the -default flag and the
+// `default=...` option of sum type declarations.
+const (
+	// defaultAllow allows default clauses. One that doesn't panic disables
+	// the exhaustiveness check of its switch.
+	defaultAllow = "allow"
+	// defaultMustPanic requires default clauses to panic.
+	defaultMustPanic = "must-panic"
+	// defaultForbid forbids default clauses.
+	defaultForbid = "forbid"
+)
+
+// defaultOptionPrefix is the prefix of the option of a sum type declaration
+// giving its policy for default clauses, e.g., `default=forbid`.
+const defaultOptionPrefix = "default="
+
+// checkDefaultPolicyFlag returns an error if the -default flag is invalid.
+func checkDefaultPolicyFlag() error {
+	if !validDefaultPolicy(defaultPolicy) {
+		return fmt.Errorf("invalid default clause policy '%s' (expected allow, must-panic or forbid)",
+			defaultPolicy)
+	}
+	return nil
+}
+
+// validDefaultPolicy returns true if policy is a policy for default clauses.
+func validDefaultPolicy(policy string) bool {
+	switch policy {
+	case defaultAllow, defaultMustPanic, defaultForbid:
+		return true
+	}
+	return false
+}
+
+// defaultPolicy returns the policy for default clauses of switches over this
+// sum type: the one given by its declaration, if any, or the one given by the
+// -default flag otherwise.
+func (def *sumTypeDef) defaultPolicy() string {
+	for _, opt := range def.Decl.Options {
+		if policy, ok := strings.CutPrefix(opt, defaultOptionPrefix); ok {
+			return policy
+		}
+	}
+	return defaultPolicy
+}
+
+// checkDefaultPolicy reports the default clause of the given type switch over
+// a sum type if it violates the policy for the sum type: if default clauses
+// are forbidden, or if they must panic and it doesn't. Under either policy, a
+// default clause doesn't disable the exhaustiveness check of the switch.
+func checkDefaultPolicy(pass *analysis.Pass, res *Result, sw *switchInfo) {
+	if !sw.HasDefault || !inScope(sw.Def) {
+		return
+	}
+	clause := defaultClause(sw.Stmt.Body)
+	var msg string
+	switch sw.Def.defaultPolicy() {
+	case defaultForbid:
+		msg = "switch over sum type '%s' has a default clause, which is forbidden"
+	case defaultMustPanic:
+		if terminatingCall(pass, clause) != nil {
+			return
+		}
+		msg = "default clause of switch over sum type '%s' must panic"
+	default:
+		return
+	}
+	res.report(pass, &Finding{
+		Diagnostic: analysis.Diagnostic{
+			Pos:      clause.Pos(),
+			Category: CategoryDefaultPolicy,
+			Message:  fmt.Sprintf(msg, sw.Def.Decl.TypeName),
+			Related:  sw.Def.Decl.related(),
+		},
+		Type: sw.Def.qualifiedName(),
+	})
+}
@@ -0,0 +1,138 @@
+// Package directive parses go-sumtype directives from Go comments.
+//
+// A directive is a line comment of the form
+//
+//	//go-sumtype:kind arg1 arg2 ...
+//
+// with no space between the comment marker and `go-sumtype:`, following the
+// convention of other Go directives such as `//go:generate`. The kind is a
+// single word, and the arguments are separated by white space. For example,
+// `//go-sumtype:decl Expr` is a directive of kind "decl" with the single
+// argument "Expr". A trailing comment may follow the arguments, as in
+// `//go-sumtype:decl Expr // the AST`; it is not part of the arguments.
+//
+// This package is the single source of truth for the directive grammar used
+// by go-sumtype, so that other analyzers and code generators can recognize
+// exactly the same directives.
+package directive
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// Prefix is the prefix of every go-sumtype directive.
+const Prefix = "//go-sumtype:"
+
+// Kind is the kind of a directive.
+type Kind string
+
+const (
+	// Decl declares a sum type: `//go-sumtype:decl Type`.
+	Decl Kind = "decl"
+	// Enum declares an enum: `//go-sumtype:enum Type [options...]`.
+	Enum Kind = "enum"
+	// Expect forces the type switch immediately below it to be checked
+	// against a sum type: `//go-sumtype:expect Type`.
+	Expect Kind = "expect"
+	// Param binds a parameter of the function it documents to a sum type,
+	// so that type switches over the parameter are checked against the sum
+	// type: `//go-sumtype:param name Type`.
+	Param Kind = "param"
+	// Registry declares that the map variable or registration function it
+	// documents must register every variant of a sum type:
+	// `//go-sumtype:registry Type`.
+	Registry Kind = "registry"
+	// Bridge declares that the members of an enum are the kinds of the
+	// variants of a sum type, each variant having a method returning its
+	// kind: `//go-sumtype:bridge Enum Type`.
+	Bridge Kind = "bridge"
+	// Require declares interfaces that every variant of a sum type must
+	// implement: `//go-sumtype:require Type Interface...`.
+	Require Kind = "require"
+	// Matcher declares that the struct type it documents has a function
+	// field for each variant of a sum type: `//go-sumtype:matcher Type`.
+	Matcher Kind = "matcher"
+	// Constructs declares that the function it documents constructs every
+	// variant of a sum type, except those listed after `except`:
+	// `//go-sumtype:constructs Type [except Variant...]`.
+	Constructs Kind = "constructs"
+)
+
+// Directive is a single go-sumtype directive.
+type Directive struct {
+	// Pos is the position of the comment containing the directive, or
+	// token.NoPos if the directive was not parsed from an AST.
+	Pos token.Pos
+	// Kind is the kind of the directive, e.g., Decl.
+	Kind Kind
+	// Args are the white space separated arguments following the kind, up
+	// to any trailing comment.
+	Args []string
+}
+
+// ParseLine parses a directive from a single line of Go source code, which
+// must begin with the comment marker. If the line is not a directive, then
+// this returns false.
+func ParseLine(line string) (Directive, bool) {
+	if !strings.HasPrefix(line, Prefix) {
+		return Directive{}, false
+	}
+	fields := strings.Fields(line[len(Prefix):])
+	if len(fields) == 0 || !isKind(fields[0]) {
+		return Directive{}, false
+	}
+	// The kind must be followed by white space or nothing at all.
+	rest := line[len(Prefix)+len(fields[0]):]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return Directive{}, false
+	}
+	args := fields[1:]
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "//") {
+			args = args[:i]
+			break
+		}
+	}
+	return Directive{Kind: Kind(fields[0]), Args: args}, true
+}
+
+// Parse returns the directives in the given comment group, in the order in
+// which they appear.
+func Parse(cg *ast.CommentGroup) []Directive {
+	if cg == nil {
+		return nil
+	}
+	var dirs []Directive
+	for _, c := range cg.List {
+		d, ok := ParseLine(c.Text)
+		if !ok {
+			continue
+		}
+		d.Pos = c.Slash
+		dirs = append(dirs, d)
+	}
+	return dirs
+}
+
+// ParseFile returns every directive in the comments of the given file, in the
+// order in which they appear. The file must have been parsed with comments.
+func ParseFile(f *ast.File) []Directive {
+	var dirs []Directive
+	for _, cg := range f.Comments {
+		dirs = append(dirs, Parse(cg)...)
+	}
+	return dirs
+}
+
+// isKind returns true if s is a valid directive kind, which consists only of
+// lower case ASCII letters and dashes.
+func isKind(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && r != '-' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,32 @@
+package directive
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want Directive
+		ok   bool
+	}{
+		{"//go-sumtype:decl Expr", Directive{Kind: Decl, Args: []string{"Expr"}}, true},
+		{"//go-sumtype:decl\tExpr  ", Directive{Kind: Decl, Args: []string{"Expr"}}, true},
+		{"//go-sumtype:enum Perm bitflag", Directive{Kind: Enum, Args: []string{"Perm", "bitflag"}}, true},
+		{"//go-sumtype:decl", Directive{Kind: Decl, Args: []string{}}, true},
+		{"//go-sumtype:enum Perm bitflag // flags", Directive{Kind: Enum, Args: []string{"Perm", "bitflag"}}, true},
+		{"//go-sumtype:decl Expr //nolint", Directive{Kind: Decl, Args: []string{"Expr"}}, true},
+		{"// go-sumtype:decl Expr", Directive{}, false},
+		{"//go-sumtype:declExpr", Directive{}, false},
+		{"//go-sumtype: decl Expr", Directive{}, false},
+		{"/*go-sumtype:decl Expr*/", Directive{}, false},
+	}
+	for _, test := range tests {
+		got, ok := ParseLine(test.line)
+		if ok != test.ok || (ok && !reflect.DeepEqual(got, test.want)) {
+			t.Errorf("ParseLine(%q) = %#v, %v; want %#v, %v",
+				test.line, got, ok, test.want, test.ok)
+		}
+	}
+}
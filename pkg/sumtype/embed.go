@@ -0,0 +1,76 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// excludeEmbeddingVariants is set with the -exclude-embedding-variants flag.
+// When true, variants that embed their sum type are silently removed from it
+// instead of being reported.
+var excludeEmbeddingVariants bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&excludeEmbeddingVariants, "exclude-embedding-variants", false,
+		"exclude variants that embed their sum type from it instead of reporting them")
+}
+
+// checkEmbeddingVariants finds the variants of the sum types declared in the
+// current package that are structs embedding an interface that implements
+// the sum type, e.g., the sum type itself. Such a variant satisfies the sum
+// type trivially, whatever value it wraps, which effectively unseals it.
+//
+// Each such variant is reported, or removed from the variants of its sum type
+// with the -exclude-embedding-variants flag.
+func checkEmbeddingVariants(pass *analysis.Pass, defs []sumTypeDef) {
+	for i := range defs {
+		def := &defs[i]
+		if def.Decl.Package != pass.Pkg {
+			continue
+		}
+		variants := def.Variants[:0:0]
+		for _, v := range def.Variants {
+			field := embeddedSumType(v, def.Ty)
+			if field == nil {
+				variants = append(variants, v)
+				continue
+			}
+			if excludeEmbeddingVariants {
+				continue
+			}
+			variants = append(variants, v)
+			pass.Report(analysis.Diagnostic{
+				Pos:      field.Pos(),
+				Category: CategoryEmbedding,
+				Message: fmt.Sprintf(
+					"variant '%s' of sum type '%s' embeds %s, which unseals the sum type",
+					v.Name(), def.Decl.TypeName,
+					types.TypeString(field.Type(), types.RelativeTo(pass.Pkg))),
+				Related: def.Decl.related(),
+			})
+		}
+		def.Variants = variants
+	}
+}
+
+// embeddedSumType returns the field of the variant v that embeds an interface
+// implementing the sum type iface, or nil if v is not a struct with such a
+// field.
+func embeddedSumType(v types.Object, iface *types.Interface) *types.Var {
+	st, ok := v.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Embedded() || !types.IsInterface(f.Type()) {
+			continue
+		}
+		if types.Implements(f.Type(), iface) {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,560 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// enumRequireDefault is set with the -enum-require-default flag. When true,
+// every switch over an enum must have a default clause, since a value of an
+// enum type may hold a value that is not one of its members.
+var enumRequireDefault bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&enumRequireDefault, "enum-require-default", false,
+		"require a default clause in every switch over an enum")
+}
+
+// enumDef corresponds to the definition of a named Go type that is
+// interpreted as an enum. Its members are determined by finding all
+// constants of said type that are declared in the same package.
+type enumDef struct {
+	Decl sumTypeDecl
+	// Name is the type name declared as an enum. It may be an alias, e.g.,
+	// of an enum of another package or of a basic type.
+	Name    *types.TypeName
+	Ty      types.Type
+	Members []types.Object
+	// Bitflag is true when the members of this enum are flags meant to be
+	// combined with one another. Switches over a bitflag enum are not
+	// required to enumerate every combination, so they are never checked.
+	Bitflag bool
+}
+
+// findEnumDefs attempts to find a Go type definition for each of the given
+// enum declarations. If no such enum definition could be found for any of the
+// given declarations an error is reported and it is not added to the returned
+// slice.
+func findEnumDefs(pass *analysis.Pass, decls []sumTypeDecl) []enumDef {
+	var defs []enumDef
+	var lengths map[types.Object]bool
+	for _, decl := range decls {
+		if decl.Kind != declEnum {
+			continue
+		}
+		if lengths == nil {
+			lengths = arrayLengths(pass)
+		}
+		def := newEnumDef(pass, decl.Package, decl, lengths)
+		if def == nil {
+			continue
+		}
+		defs = append(defs, *def)
+	}
+	return defs
+}
+
+// newEnumDef attempts to extract an enum definition from a single package.
+// If no such type corresponds to the given decl, then this function returns a
+// nil def and an error is reported.
+//
+// If the decl corresponds to a type whose underlying type is not an integer
+// or a string, or if the type has no constants declared, an error is
+// reported.
+//
+// Constants in lengths are not members: a constant used as the length of an
+// array type, such as a trailing `NumColors`, counts the members of the enum
+// rather than being one of them.
+func newEnumDef(pass *analysis.Pass, pkg *types.Package, decl sumTypeDecl, lengths map[types.Object]bool) *enumDef {
+	obj := pkg.Scope().Lookup(decl.TypeName)
+	if obj == nil {
+		reportDeclf(pass, decl.Pos, "type '%s' is not defined", decl.TypeName)
+		return nil
+	}
+	def, err := buildEnumDef(obj, decl, false)
+	if err != nil {
+		reportDeclf(pass, decl.Pos, "%s", err)
+		return nil
+	}
+	var members []types.Object
+	for _, m := range def.Members {
+		if !lengths[m] {
+			members = append(members, m)
+		}
+	}
+	if len(members) == 0 {
+		reportDeclf(pass, decl.Pos, "enum '%s' has no members", decl.TypeName)
+		return nil
+	}
+	def.Members = members
+	return def
+}
+
+// arrayLengths returns the constants used as the length of an array type in
+// the files of the given pass, e.g., NumColors in `[NumColors]string`.
+func arrayLengths(pass *analysis.Pass) map[types.Object]bool {
+	lengths := make(map[types.Object]bool)
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			arr, ok := node.(*ast.ArrayType)
+			if !ok || arr.Len == nil {
+				return true
+			}
+			switch n := ast.Unparen(arr.Len).(type) {
+			case *ast.Ident:
+				lengths[pass.TypesInfo.Uses[n]] = true
+			case *ast.SelectorExpr:
+				lengths[pass.TypesInfo.Uses[n.Sel]] = true
+			}
+			return true
+		})
+	}
+	return lengths
+}
+
+// buildEnumDef builds an enum definition for the given type object, whose
+// members are the constants of that type declared in the same package. When
+// exportedOnly is true, unexported constants are not considered members.
+func buildEnumDef(obj types.Object, decl sumTypeDecl, exportedOnly bool) (*enumDef, error) {
+	basic, ok := obj.Type().Underlying().(*types.Basic)
+	name, isTypeName := obj.(*types.TypeName)
+	if !isTypeName || !ok || basic.Info()&(types.IsInteger|types.IsString) == 0 {
+		return nil, fmt.Errorf("type '%s' is not an enum "+
+			"(enums must have an integer or string underlying type)",
+			decl.TypeName)
+	}
+	def := &enumDef{
+		Decl: decl,
+		Name: name,
+		Ty:   obj.Type(),
+	}
+	for _, opt := range decl.Options {
+		switch opt {
+		case "bitflag":
+			if basic.Info()&types.IsInteger == 0 {
+				return nil, fmt.Errorf("bitflag enum '%s' must have an "+
+					"integer underlying type", decl.TypeName)
+			}
+			def.Bitflag = true
+		default:
+			return nil, fmt.Errorf("unknown option '%s' for enum '%s'",
+				opt, decl.TypeName)
+		}
+	}
+	scope := obj.Pkg().Scope()
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || !types.Identical(c.Type(), def.Ty) {
+			continue
+		}
+		if exportedOnly && !c.Exported() {
+			continue
+		}
+		def.Members = append(def.Members, c)
+	}
+	// Keep members in declaration order, which is the order in which
+	// suggested fixes insert missing cases.
+	sort.SliceStable(def.Members, func(i, j int) bool {
+		return def.Members[i].Pos() < def.Members[j].Pos()
+	})
+	if len(def.Members) == 0 {
+		return nil, fmt.Errorf("enum '%s' has no members", decl.TypeName)
+	}
+	return def, nil
+}
+
+func (def *enumDef) String() string {
+	return def.Decl.TypeName
+}
+
+// qualifiedName returns the name of this enum qualified by the path of the
+// package declaring it. An enum declared as an alias is named by the alias,
+// since its type may have no name of its own.
+func (def *enumDef) qualifiedName() string {
+	return def.Name.Pkg().Path() + "." + def.Name.Name()
+}
+
+// missing returns a list of members in this enum whose values are not in the
+// given list of values.
+func (def *enumDef) missing(vals []constant.Value) []types.Object {
+	return missingMembers(def.Members, vals)
+}
+
+// flags returns the members of this enum whose value has exactly one bit set.
+// Combinations of flags (and the zero value) are excluded.
+func (def *enumDef) flags() []types.Object {
+	var flags []types.Object
+	one := constant.MakeInt64(1)
+	zero := constant.MakeInt64(0)
+	for _, m := range def.Members {
+		v := constant.ToInt(m.(*types.Const).Val())
+		if v.Kind() != constant.Int || constant.Compare(v, token.LEQ, zero) {
+			continue
+		}
+		lower := constant.BinaryOp(v, token.AND, constant.BinaryOp(v, token.SUB, one))
+		if constant.Compare(lower, token.EQL, zero) {
+			flags = append(flags, m)
+		}
+	}
+	return flags
+}
+
+// missingMembers returns the constants in members whose values are not in
+// the given list of values.
+func missingMembers(members []types.Object, vals []constant.Value) []types.Object {
+	var missing []types.Object
+	for _, m := range members {
+		found := false
+		for _, v := range vals {
+			if constant.Compare(m.(*types.Const).Val(), token.EQL, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, m)
+		}
+	}
+	return missing
+}
+
+// checkEnumSwitch performs an exhaustiveness check on the given expression
+// switch statement. If the switch is used on an enum and does not cover all
+// members of that enum, then an error is reported indicating which members
+// were missed.
+//
+// As with type switches, a non-panicing default case disables
+// exhaustiveness checks. Switches over bitflag enums are never checked.
+//
+// If -enum-require-default is set, then an error is also reported for a
+// switch without a default clause, even if it covers all members.
+func checkEnumSwitch(
+	pass *analysis.Pass,
+	res *Result,
+	defs []enumDef,
+	swtch *ast.SwitchStmt,
+) {
+	if swtch.Tag == nil {
+		return
+	}
+	def := findEnumDef(defs, pass.TypesInfo.TypeOf(swtch.Tag))
+	if def == nil || def.Bitflag {
+		return
+	}
+	exprs, hasDefault := caseExprs(swtch.Body)
+	if enumRequireDefault && !hasDefault {
+		res.report(pass, &Finding{
+			Diagnostic: analysis.Diagnostic{
+				Pos:      swtch.Pos(),
+				Category: CategoryEnumDefault,
+				Message: fmt.Sprintf("switch over enum '%s' has no default clause "+
+					"(enum values may be out of range)", def.Decl.TypeName),
+				Related: def.Decl.related(),
+			},
+			Type: def.qualifiedName(),
+		})
+	}
+	if hasDefault && !defaultClauseTerminates(pass, swtch.Body) {
+		return
+	}
+	missing := def.missing(constValues(pass, exprs))
+	if len(missing) == 0 {
+		return
+	}
+	for _, group := range groupMissing(missing) {
+		var cases []string
+		var imports []analysis.TextEdit
+		for _, m := range group {
+			name := m.Name()
+			if m.Pkg() != pass.Pkg {
+				var qual string
+				qual, imports = importEdits(pass, swtch.Pos(), m.Pkg().Path(), m.Pkg().Name())
+				name = qual + name
+			}
+			cases = append(cases, name)
+		}
+		fix := missingCasesFix(pass, swtch.Body, cases)
+		fix.TextEdits = append(fix.TextEdits, imports...)
+		names := missingNames(group)
+		res.report(pass, &Finding{
+			Diagnostic: analysis.Diagnostic{
+				Pos:      swtch.Pos(),
+				Category: CategoryExhaustiveness,
+				Message: fmt.Sprintf(
+					"exhaustiveness check failed for enum '%s': missing cases for %s",
+					def.Decl.TypeName, strings.Join(names, ", ")),
+				Related:        def.Decl.related(),
+				SuggestedFixes: []analysis.SuggestedFix{fix},
+			},
+			Type:    def.qualifiedName(),
+			Missing: names,
+		})
+	}
+}
+
+// checkDispatchTable checks that a map literal keyed by a bitflag enum has an
+// entry for every individual flag of that enum. Combinations of flags are not
+// required.
+func checkDispatchTable(
+	pass *analysis.Pass,
+	res *Result,
+	defs []enumDef,
+	lit *ast.CompositeLit,
+) {
+	ty := pass.TypesInfo.TypeOf(lit)
+	if ty == nil {
+		return
+	}
+	m, ok := ty.Underlying().(*types.Map)
+	if !ok {
+		return
+	}
+	def := findEnumDef(defs, m.Key())
+	if def == nil || !def.Bitflag {
+		return
+	}
+	var keys []ast.Expr
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			keys = append(keys, kv.Key)
+		}
+	}
+	missing := missingMembers(def.flags(), constValues(pass, keys))
+	if len(missing) > 0 {
+		names := missingNames(missing)
+		res.report(pass, &Finding{
+			Diagnostic: analysis.Diagnostic{
+				Pos:      lit.Pos(),
+				Category: CategoryDispatchTable,
+				Message: fmt.Sprintf(
+					"dispatch table for bitflag enum '%s' is missing entries for %s",
+					def.Decl.TypeName, strings.Join(names, ", ")),
+				Related: def.Decl.related(),
+			},
+			Type:    def.qualifiedName(),
+			Missing: names,
+		})
+	}
+}
+
+// checkFlagSwitch performs an exhaustiveness check on the given switch
+// statement without a tag if each of its cases tests the same value for a
+// flag of a bitflag enum, e.g.:
+//
+//	switch {
+//	case p&Read != 0:
+//		...
+//	case p&Write != 0:
+//		...
+//	}
+//
+// Every flag of the enum must then be tested, but combinations of flags are
+// not required. There must be at least two tests, since a single one is
+// usually not meant to handle every flag. As with other switches, a
+// non-panicing default case disables the check.
+func checkFlagSwitch(
+	pass *analysis.Pass,
+	res *Result,
+	defs []enumDef,
+	swtch *ast.SwitchStmt,
+) {
+	if swtch.Tag != nil || swtch.Init != nil {
+		return
+	}
+	exprs, hasDefault := caseExprs(swtch.Body)
+	if len(exprs) < 2 {
+		return
+	}
+	var def *enumDef
+	var subject string
+	var vals []constant.Value
+	for _, expr := range exprs {
+		x, val := flagTest(pass, expr)
+		if x == nil {
+			return
+		}
+		if def == nil {
+			def = findEnumDef(defs, pass.TypesInfo.TypeOf(x))
+			if def == nil || !def.Bitflag {
+				return
+			}
+			subject = types.ExprString(x)
+		} else if types.ExprString(x) != subject {
+			return
+		}
+		vals = append(vals, val)
+	}
+	if hasDefault && !defaultClauseTerminates(pass, swtch.Body) {
+		return
+	}
+	missing := missingMembers(def.flags(), vals)
+	if len(missing) == 0 {
+		return
+	}
+	var cases []string
+	var imports []analysis.TextEdit
+	for _, m := range missing {
+		name := m.Name()
+		if m.Pkg() != pass.Pkg {
+			var qual string
+			qual, imports = importEdits(pass, swtch.Pos(), m.Pkg().Path(), m.Pkg().Name())
+			name = qual + name
+		}
+		cases = append(cases, subject+"&"+name+" != 0")
+	}
+	fix := missingCasesFix(pass, swtch.Body, cases)
+	fix.TextEdits = append(fix.TextEdits, imports...)
+	names := missingNames(missing)
+	res.report(pass, &Finding{
+		Diagnostic: analysis.Diagnostic{
+			Pos:      swtch.Pos(),
+			Category: CategoryExhaustiveness,
+			Message: fmt.Sprintf(
+				"exhaustiveness check failed for bitflag enum '%s': missing tests for %s",
+				def.Decl.TypeName, strings.Join(names, ", ")),
+			Related:        def.Decl.related(),
+			SuggestedFixes: []analysis.SuggestedFix{fix},
+		},
+		Type:    def.qualifiedName(),
+		Missing: names,
+	})
+}
+
+// flagTest returns the value x tested by expr and the constant flag it is
+// tested for, if expr has one of the forms `x&F != 0`, `x&F > 0` or
+// `x&F == F`, where the operands of & may be swapped. Otherwise, nil is
+// returned.
+func flagTest(pass *analysis.Pass, expr ast.Expr) (ast.Expr, constant.Value) {
+	cmp, ok := ast.Unparen(expr).(*ast.BinaryExpr)
+	if !ok {
+		return nil, nil
+	}
+	and, ok := ast.Unparen(cmp.X).(*ast.BinaryExpr)
+	if !ok || and.Op != token.AND {
+		return nil, nil
+	}
+	x, flag := and.X, pass.TypesInfo.Types[and.Y].Value
+	if flag == nil {
+		x, flag = and.Y, pass.TypesInfo.Types[and.X].Value
+	}
+	other := pass.TypesInfo.Types[cmp.Y].Value
+	if flag == nil || other == nil || pass.TypesInfo.Types[x].Value != nil {
+		return nil, nil
+	}
+	zero := constant.MakeInt64(0)
+	switch {
+	case (cmp.Op == token.NEQ || cmp.Op == token.GTR) && constant.Compare(other, token.EQL, zero):
+	case cmp.Op == token.EQL && constant.Compare(other, token.EQL, flag):
+	default:
+		return nil, nil
+	}
+	return x, flag
+}
+
+// checkLookupTable checks that an array literal indexed by an enum has an
+// element for every member of that enum. An array literal is considered to be
+// indexed by an enum if any of its keys is an enum constant or if its length
+// is a constant whose type is an enum (e.g., `[NumColors]string{...}`).
+//
+// Members whose value is out of bounds for the array are not required. A
+// `NumColors` sentinel used as the length is not a member to begin with (see
+// newEnumDef).
+func checkLookupTable(
+	pass *analysis.Pass,
+	res *Result,
+	defs []enumDef,
+	lit *ast.CompositeLit,
+) {
+	ty := pass.TypesInfo.TypeOf(lit)
+	if ty == nil {
+		return
+	}
+	arr, ok := ty.Underlying().(*types.Array)
+	if !ok {
+		return
+	}
+	var def *enumDef
+	if arrTy, ok := lit.Type.(*ast.ArrayType); ok && arrTy.Len != nil {
+		def = findEnumDef(defs, pass.TypesInfo.TypeOf(arrTy.Len))
+	}
+	var indices []constant.Value
+	idx := constant.MakeInt64(0)
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			tv, ok := pass.TypesInfo.Types[kv.Key]
+			if !ok || tv.Value == nil {
+				return
+			}
+			if def == nil {
+				def = findEnumDef(defs, tv.Type)
+			}
+			idx = constant.ToInt(tv.Value)
+		}
+		indices = append(indices, idx)
+		idx = constant.BinaryOp(idx, token.ADD, constant.MakeInt64(1))
+	}
+	if def == nil || def.Bitflag {
+		return
+	}
+	var inBounds []types.Object
+	zero, length := constant.MakeInt64(0), constant.MakeInt64(arr.Len())
+	for _, m := range def.Members {
+		v := constant.ToInt(m.(*types.Const).Val())
+		if v.Kind() != constant.Int {
+			return
+		}
+		if constant.Compare(v, token.GEQ, zero) && constant.Compare(v, token.LSS, length) {
+			inBounds = append(inBounds, m)
+		}
+	}
+	missing := missingMembers(inBounds, indices)
+	if len(missing) > 0 {
+		names := missingNames(missing)
+		res.report(pass, &Finding{
+			Diagnostic: analysis.Diagnostic{
+				Pos:      lit.Pos(),
+				Category: CategoryLookupTable,
+				Message: fmt.Sprintf(
+					"lookup table indexed by enum '%s' is missing entries for %s",
+					def.Decl.TypeName, strings.Join(names, ", ")),
+				Related: def.Decl.related(),
+			},
+			Type:    def.qualifiedName(),
+			Missing: names,
+		})
+	}
+}
+
+// constValues returns the constant values of the given expressions.
+// Expressions that are not constant are skipped.
+func constValues(pass *analysis.Pass, exprs []ast.Expr) []constant.Value {
+	var vals []constant.Value
+	for _, expr := range exprs {
+		if tv, ok := pass.TypesInfo.Types[expr]; ok && tv.Value != nil {
+			vals = append(vals, tv.Value)
+		}
+	}
+	return vals
+}
+
+// findEnumDef returns the enum definition corresponding to the given type. If
+// no such enum definition exists, then nil is returned.
+func findEnumDef(defs []enumDef, needle types.Type) *enumDef {
+	if needle == nil {
+		return nil
+	}
+	for i := range defs {
+		def := &defs[i]
+		if types.Identical(types.Unalias(needle), def.Ty) {
+			return def
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// reportEscapes is set with the -report-escapes flag. When true, values of
+// sum types that are converted to an empty interface at the boundary of an
+// exported API are reported, since switches over them can then only be
+// checked with directives.
+var reportEscapes bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&reportEscapes, "report-escapes", false,
+		"report values of sum types that escape into any in exported APIs")
+}
+
+// checkEscapes reports values of sum types that escape into an empty
+// interface in the exported API of the current package: values returned as
+// `any` from exported functions and methods, and values assigned to exported
+// variables or fields of type `any`. These are the places where a
+// `go-sumtype:expect ...` or `go-sumtype:param ...` directive is needed to
+// keep checking switches over the values.
+func checkEscapes(pass *analysis.Pass, res *Result, defs []sumTypeDef) {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			exported := isExportedFunc(fn)
+			sig, _ := pass.TypesInfo.Defs[fn.Name].Type().(*types.Signature)
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.FuncLit:
+					// Returns in a function literal are not returns from fn.
+					ast.Inspect(n.Body, func(n ast.Node) bool {
+						if assign, ok := n.(*ast.AssignStmt); ok {
+							checkAssignEscape(pass, res, defs, assign)
+						}
+						return true
+					})
+					return false
+				case *ast.ReturnStmt:
+					if exported && sig != nil && len(n.Results) == sig.Results().Len() {
+						for i, result := range n.Results {
+							if isEmptyInterface(sig.Results().At(i).Type()) {
+								reportEscape(pass, res, defs, result,
+									fmt.Sprintf("returned from exported function '%s'", fn.Name.Name))
+							}
+						}
+					}
+				case *ast.AssignStmt:
+					checkAssignEscape(pass, res, defs, n)
+				}
+				return true
+			})
+		}
+	}
+}
+
+// checkAssignEscape reports the values of sum types in the given assignment
+// that are assigned to an exported variable or field of type `any`.
+func checkAssignEscape(pass *analysis.Pass, res *Result, defs []sumTypeDef, assign *ast.AssignStmt) {
+	if len(assign.Lhs) != len(assign.Rhs) {
+		return
+	}
+	for i, lhs := range assign.Lhs {
+		var id *ast.Ident
+		switch lhs := ast.Unparen(lhs).(type) {
+		case *ast.Ident:
+			id = lhs
+		case *ast.SelectorExpr:
+			id = lhs.Sel
+		default:
+			continue
+		}
+		v, ok := pass.TypesInfo.ObjectOf(id).(*types.Var)
+		if !ok || !v.Exported() || !isEmptyInterface(v.Type()) {
+			continue
+		}
+		if !v.IsField() && v.Parent() != pass.Pkg.Scope() {
+			continue
+		}
+		what := "variable"
+		if v.IsField() {
+			what = "field"
+		}
+		reportEscape(pass, res, defs, assign.Rhs[i],
+			fmt.Sprintf("assigned to exported %s '%s'", what, v.Name()))
+	}
+}
+
+// reportEscape reports expr, which is converted to an empty interface in the
+// way described by how, if its type is a sum type.
+func reportEscape(pass *analysis.Pass, res *Result, defs []sumTypeDef, expr ast.Expr, how string) {
+	ty := pass.TypesInfo.TypeOf(expr)
+	if ty == nil {
+		return
+	}
+	def := findDef(defs, ty)
+	if def == nil {
+		return
+	}
+	res.report(pass, &Finding{
+		Diagnostic: analysis.Diagnostic{
+			Pos:      expr.Pos(),
+			Category: CategoryEscape,
+			Message: fmt.Sprintf("value of sum type '%s' escapes into any when %s",
+				def.Decl.TypeName, how),
+			Related: def.Decl.related(),
+		},
+		Type: def.qualifiedName(),
+	})
+}
+
+// isExportedFunc returns true if fn is an exported function, or an exported
+// method of an exported type.
+func isExportedFunc(fn *ast.FuncDecl) bool {
+	if !fn.Name.IsExported() {
+		return false
+	}
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return true
+	}
+	recv := fn.Recv.List[0].Type
+	for {
+		switch t := recv.(type) {
+		case *ast.StarExpr:
+			recv = t.X
+		case *ast.IndexExpr:
+			recv = t.X
+		case *ast.IndexListExpr:
+			recv = t.X
+		case *ast.ParenExpr:
+			recv = t.X
+		case *ast.Ident:
+			return t.IsExported()
+		default:
+			return false
+		}
+	}
+}
+
+// isEmptyInterface returns true if ty is an interface without any methods,
+// e.g., `any`.
+func isEmptyInterface(ty types.Type) bool {
+	iface, ok := ty.Underlying().(*types.Interface)
+	return ok && iface.Empty()
+}
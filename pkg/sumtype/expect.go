@@ -0,0 +1,211 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype/directive"
+	"golang.org/x/tools/go/analysis"
+)
+
+// paramDefs returns the sum type definitions bound to function parameters
+// with `go-sumtype:param ...` directives in functions, keyed by the
+// parameters' objects. Directives that do not name a parameter of their
+// function or a sum type are reported.
+func paramDefs(pass *analysis.Pass, defs []sumTypeDef) map[types.Object]*sumTypeDef {
+	params := map[types.Object]*sumTypeDef{}
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			for _, d := range declDirectives(file, fn, fn.Doc) {
+				if d.Kind != directive.Param {
+					continue
+				}
+				if len(d.Args) != 2 {
+					reportDeclf(pass, d.Pos,
+						"param directive requires a parameter name and a sum type name")
+					continue
+				}
+				param := findParam(pass, fn, d.Args[0])
+				if param == nil {
+					reportDeclf(pass, d.Pos, "param directive names '%s', "+
+						"which is not a parameter of '%s'", d.Args[0], fn.Name.Name)
+					continue
+				}
+				def, err := lookupDef(pass, defs, d.Pos, d.Args[1])
+				if err != nil {
+					reportDeclf(pass, d.Pos, "param directive: %v", err)
+					continue
+				}
+				if def == nil {
+					reportDeclf(pass, d.Pos, "param directive names '%s', "+
+						"which is not a sum type", d.Args[1])
+					continue
+				}
+				params[param] = def
+			}
+		}
+	}
+	return params
+}
+
+// declDirectives returns the directives in the documentation of a
+// declaration and in any comment within it, e.g., in the body of a function.
+//
+// Directives in documentation are accepted, but gofmt reformats them into
+// ordinary comments (as it only preserves directives without a dash, like
+// `//go:generate`), so they are usually written inside the declaration.
+func declDirectives(file *ast.File, node ast.Node, doc *ast.CommentGroup) []directive.Directive {
+	dirs := directive.Parse(doc)
+	for _, cg := range file.Comments {
+		if node.Pos() <= cg.Pos() && cg.End() <= node.End() {
+			dirs = append(dirs, directive.Parse(cg)...)
+		}
+	}
+	return dirs
+}
+
+// findParam returns the object of the parameter of fn with the given name, or
+// nil if there is no such parameter.
+func findParam(pass *analysis.Pass, fn *ast.FuncDecl, name string) types.Object {
+	for _, field := range fn.Type.Params.List {
+		for _, id := range field.Names {
+			if id.Name == name {
+				return pass.TypesInfo.Defs[id]
+			}
+		}
+	}
+	return nil
+}
+
+// boundDef returns the sum type definition bound to the value asserted by the
+// given type switch with a `go-sumtype:param ...` directive, or nil if the
+// value is not a parameter bound to a sum type.
+func boundDef(
+	pass *analysis.Pass,
+	params map[types.Object]*sumTypeDef,
+	swtch *ast.TypeSwitchStmt,
+) *sumTypeDef {
+	id, ok := ast.Unparen(findTypeAssertExpr(swtch)).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	return params[pass.TypesInfo.Uses[id]]
+}
+
+// findDefByName returns the sum type definition with the given name, or nil
+// if there is none.
+func findDefByName(defs []sumTypeDef, name string) *sumTypeDef {
+	for i := range defs {
+		if defs[i].Decl.TypeName == name {
+			return &defs[i]
+		}
+	}
+	return nil
+}
+
+// lookupDef returns the sum type definition named by a directive at pos. A
+// name qualified by the name of a package imported by the directive's file,
+// e.g., ast.Expr, names a sum type of that package. An unqualified name names
+// a sum type of the package being analyzed or, if there is none, the only sum
+// type of an imported package with that name.
+//
+// If there is no such sum type, then nil is returned. If an unqualified name
+// could name the sum types of several imported packages, then an error is
+// returned as well.
+func lookupDef(pass *analysis.Pass, defs []sumTypeDef, pos token.Pos, name string) (*sumTypeDef, error) {
+	qual, typeName, qualified := strings.Cut(name, ".")
+	if !qualified {
+		typeName = name
+	}
+	var paths []string
+	if file := enclosingFile(pass, pos); qualified && file != nil {
+		for _, spec := range file.Imports {
+			if pkgName := pass.TypesInfo.PkgNameOf(spec); pkgName != nil && pkgName.Name() == qual {
+				paths = append(paths, pkgName.Imported().Path())
+			}
+		}
+	}
+	var found []*sumTypeDef
+	for i := range defs {
+		def := &defs[i]
+		if def.Decl.TypeName != typeName {
+			continue
+		}
+		path := def.Decl.Package.Path()
+		switch {
+		case qualified:
+			if slices.Contains(paths, path) {
+				return def, nil
+			}
+		case def.Decl.Package == pass.Pkg:
+			return def, nil
+		default:
+			found = append(found, def)
+		}
+	}
+	if len(found) > 1 {
+		var names []string
+		for _, def := range found {
+			names = append(names, def.Decl.Package.Name()+"."+def.Decl.TypeName)
+		}
+		return nil, fmt.Errorf("'%s' is ambiguous, qualify it with its package as in %s",
+			name, strings.Join(names, " or "))
+	}
+	if len(found) == 1 {
+		return found[0], nil
+	}
+	return nil, nil
+}
+
+// expectedDef returns the sum type definition named by a
+// `go-sumtype:expect ...` directive on the line immediately above the given
+// type switch. This lets switches over values whose static type is not a sum
+// type, e.g., `any`, be checked anyway.
+//
+// If there is no such directive, or if it names a type that is not a sum
+// type, then nil is returned. The latter is also reported.
+func expectedDef(
+	pass *analysis.Pass,
+	defs []sumTypeDef,
+	swtch *ast.TypeSwitchStmt,
+) *sumTypeDef {
+	file := enclosingFile(pass, swtch.Pos())
+	if file == nil {
+		return nil
+	}
+	// The directive must be on the line above the switch in the file itself,
+	// whichever lines a line directive maps them to.
+	line := pass.Fset.PositionFor(swtch.Pos(), false).Line
+	for _, cg := range file.Comments {
+		if pass.Fset.PositionFor(cg.End(), false).Line != line-1 {
+			continue
+		}
+		for _, d := range directive.Parse(cg) {
+			if d.Kind != directive.Expect {
+				continue
+			}
+			if len(d.Args) != 1 {
+				reportDeclf(pass, d.Pos, "expect directive requires a single sum type name")
+				return nil
+			}
+			def, err := lookupDef(pass, defs, d.Pos, d.Args[0])
+			switch {
+			case err != nil:
+				reportDeclf(pass, d.Pos, "expect directive: %v", err)
+			case def == nil:
+				reportDeclf(pass, d.Pos, "expect directive names '%s', which is not a sum type",
+					d.Args[0])
+			}
+			return def
+		}
+	}
+	return nil
+}
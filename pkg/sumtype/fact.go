@@ -0,0 +1,127 @@
+package sumtype
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// sumTypeFact is exported for the type name of every sum type declared in a
+// package, so that switches over the sum type in packages importing it, even
+// indirectly, are checked too.
+type sumTypeFact struct {
+	// Variants are the names of the variants of the sum type, which are
+	// declared in the same package as the sum type.
+	Variants []string
+	// Parts are the names of the sum types making up a composed sum type,
+	// which are declared in the same package.
+	Parts []string
+	// Options are the options of the declaration of the sum type, e.g.,
+	// `nil`, which apply to switches over it in other packages too.
+	Options []string
+}
+
+func (*sumTypeFact) AFact() {}
+
+func (f *sumTypeFact) String() string {
+	s := "sumtype(" + strings.Join(f.Variants, ", ") + ")"
+	if len(f.Parts) > 0 {
+		s += " = " + strings.Join(f.Parts, " | ")
+	}
+	if len(f.Options) > 0 {
+		s += " " + strings.Join(f.Options, " ")
+	}
+	return s
+}
+
+// exportSumTypeFacts exports a fact for each of the given sum types declared
+// in the current package.
+func exportSumTypeFacts(pass *analysis.Pass, defs []sumTypeDef) {
+	for _, def := range defs {
+		obj := pass.Pkg.Scope().Lookup(def.Decl.TypeName)
+		if obj == nil || def.Decl.Package != pass.Pkg {
+			continue
+		}
+		var names []string
+		for _, v := range def.Variants {
+			names = append(names, v.Name())
+		}
+		fact := &sumTypeFact{Variants: names}
+		if !def.Decl.isComposed() {
+			fact.Options = def.Decl.Options
+		}
+		for _, part := range def.Parts {
+			fact.Parts = append(fact.Parts, part.Decl.TypeName)
+		}
+		pass.ExportObjectFact(obj, fact)
+	}
+}
+
+// importedSumTypeDefs returns the definitions of the sum types declared in
+// the packages imported, directly or indirectly, by the current package.
+//
+// Since facts are attached to the type name declaring a sum type, a sum type
+// re-exported with an alias, e.g., `type Expr = internal.Expr`, resolves to
+// the same definition.
+func importedSumTypeDefs(pass *analysis.Pass) []sumTypeDef {
+	var defs []sumTypeDef
+	parts := map[string][]string{}
+	for _, f := range pass.AllObjectFacts() {
+		fact, ok := f.Fact.(*sumTypeFact)
+		if !ok || f.Object.Pkg() == pass.Pkg {
+			continue
+		}
+		iface, ok := f.Object.Type().Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		pkg := f.Object.Pkg()
+		def := sumTypeDef{
+			Decl: sumTypeDecl{
+				Kind:     declSumType,
+				Package:  pkg,
+				TypeName: f.Object.Name(),
+			},
+			Ty: iface,
+		}
+		def.Decl.Options = fact.Options
+		if !iface.IsMethodSet() {
+			// The variants of a union constraint may be declared in
+			// other packages, so they are found from its type set.
+			def.Variants, _ = unionVariants(iface)
+			defs = append(defs, def)
+			continue
+		}
+		for _, name := range fact.Variants {
+			v, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+			if !ok {
+				// Packages loaded from export data may omit unexported
+				// types. Such variants cannot be named in a case clause
+				// outside of their package, but still need to be reported
+				// as missing, so stand in a type that matches no case.
+				v = types.NewTypeName(f.Object.Pos(), pkg, name, nil)
+				types.NewNamed(v, types.NewStruct(nil, nil), nil)
+			}
+			def.Variants = append(def.Variants, v)
+		}
+		if len(fact.Parts) > 0 {
+			parts[def.qualifiedName()] = fact.Parts
+		}
+		defs = append(defs, def)
+	}
+	for i := range defs {
+		for _, name := range parts[defs[i].qualifiedName()] {
+			for _, part := range defs {
+				if part.Decl.Package == defs[i].Decl.Package && part.Decl.TypeName == name {
+					defs[i].Parts = append(defs[i].Parts, part)
+				}
+			}
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].qualifiedName() < defs[j].qualifiedName()
+	})
+	return defs
+}
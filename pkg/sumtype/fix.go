@@ -0,0 +1,197 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// fixGrouped is set with the -fix-grouped flag. When true, suggested fixes
+// insert a single case clause listing every missing case instead of one
+// clause per missing case.
+var fixGrouped bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&fixGrouped, "fix-grouped", false,
+		"insert missing cases as a single grouped case clause in suggested fixes")
+}
+
+// missingCasesFix returns a suggested fix that inserts case clauses for each
+// of the given case expressions into the body of a switch statement. Clauses
+// are inserted before the default clause if one exists, or at the end of the
+// switch otherwise.
+func missingCasesFix(
+	pass *analysis.Pass,
+	body *ast.BlockStmt,
+	cases []string,
+) analysis.SuggestedFix {
+	pos := body.Rbrace
+	for _, stmt := range body.List {
+		if clause := stmt.(*ast.CaseClause); clause.List == nil {
+			pos = clause.Pos()
+			break
+		}
+	}
+	text, imports := caseClauses(pass, pos, cases)
+	return analysis.SuggestedFix{
+		Message: fmt.Sprintf("Add missing cases for %s", strings.Join(cases, ", ")),
+		TextEdits: append([]analysis.TextEdit{{
+			Pos:     pos,
+			End:     pos,
+			NewText: []byte(text),
+		}}, imports...),
+	}
+}
+
+// caseClauses returns the text of case clauses for each of the given case
+// expressions, to be inserted at pos, where a clause or the closing brace of
+// a switch begins, and the edits adding any imports their bodies need. Their
+// bodies follow the template given by the -fix-body flag or the
+// configuration.
+func caseClauses(pass *analysis.Pass, pos token.Pos, cases []string) (string, []analysis.TextEdit) {
+	// gofmt aligns case clauses with the closing brace of the switch, so
+	// this works whether we are inserting before `default` or `}`. The
+	// column is read from the file itself, since a line directive may map
+	// the switch to a template with unknown columns.
+	indent := strings.Repeat("\t", pass.Fset.PositionFor(pos, false).Column-1)
+
+	groups := [][]string{cases}
+	if !fixGrouped {
+		groups = nil
+		for _, c := range cases {
+			groups = append(groups, []string{c})
+		}
+	}
+	// The configuration was already loaded, and checked, by run.
+	cfg, _ := loadConfig()
+	tmpl, _ := fixBodyTemplate(cfg)
+	var buf strings.Builder
+	var imports []analysis.TextEdit
+	for _, group := range groups {
+		body, edits := caseBody(pass, pos, tmpl, group)
+		fmt.Fprintf(&buf, "case %s:\n", strings.Join(group, ", "))
+		fmt.Fprintf(&buf, "%s\t%s\n%s", indent, body, indent)
+		if imports == nil {
+			imports = edits
+		}
+	}
+	return buf.String(), imports
+}
+
+// fileQualifier returns a qualifier that names objects the way they must be
+// written in the file containing pos, taking into account renamed and dot
+// imports.
+func fileQualifier(pass *analysis.Pass, pos token.Pos) types.Qualifier {
+	file := enclosingFile(pass, pos)
+	return func(pkg *types.Package) string {
+		if pkg == pass.Pkg {
+			return ""
+		}
+		if file != nil {
+			for _, imp := range file.Imports {
+				path, err := strconv.Unquote(imp.Path.Value)
+				if err != nil || path != pkg.Path() {
+					continue
+				}
+				if imp.Name == nil {
+					return pkg.Name()
+				}
+				if imp.Name.Name == "." {
+					return ""
+				}
+				return imp.Name.Name
+			}
+		}
+		return pkg.Name()
+	}
+}
+
+// importEdits returns the prefix with which members of the package with the
+// given path and name must be qualified in the file containing pos, e.g.,
+// "fmt.", and the edits that add an import of the package to the file if it
+// doesn't import it already. An existing import is referred to by the name it
+// declares, and a new one is named explicitly if its name differs from the
+// last element of its path, e.g., for example.com/shapes/v2.
+func importEdits(pass *analysis.Pass, pos token.Pos, path, name string) (string, []analysis.TextEdit) {
+	file := enclosingFile(pass, pos)
+	for _, imp := range file.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err != nil || p != path {
+			continue
+		}
+		var obj types.Object
+		if imp.Name != nil {
+			obj = pass.TypesInfo.Defs[imp.Name]
+		} else {
+			obj = pass.TypesInfo.Implicits[imp]
+		}
+		switch {
+		case imp.Name != nil && imp.Name.Name == ".":
+			return "", nil
+		case imp.Name != nil && imp.Name.Name == "_":
+		case obj != nil:
+			return obj.Name() + ".", nil
+		case imp.Name != nil:
+			return imp.Name.Name + ".", nil
+		default:
+			return name + ".", nil
+		}
+	}
+	spec := strconv.Quote(path)
+	if path[strings.LastIndex(path, "/")+1:] != name {
+		spec = name + " " + spec
+	}
+	// Add the import to the first import declaration, or after the package
+	// clause if there is none.
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			break
+		}
+		if gen.Lparen.IsValid() {
+			return name + ".", []analysis.TextEdit{{
+				Pos:     gen.Lparen + 1,
+				End:     gen.Lparen + 1,
+				NewText: []byte("\n\t" + spec),
+			}}
+		}
+		// Turn `import "x"` into a parenthesized import declaration.
+		first := gen.Specs[0]
+		return name + ".", []analysis.TextEdit{
+			{
+				Pos:     first.Pos(),
+				End:     first.Pos(),
+				NewText: []byte("(\n\t" + spec + "\n\t"),
+			},
+			{Pos: first.End(), End: first.End(), NewText: []byte("\n)")},
+		}
+	}
+	return name + ".", []analysis.TextEdit{{
+		Pos:     file.Name.End(),
+		End:     file.Name.End(),
+		NewText: []byte("\n\nimport " + spec),
+	}}
+}
+
+// enclosingFile returns the file in the current package containing pos, or
+// nil if no such file exists.
+func enclosingFile(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, file := range pass.Files {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return file
+		}
+	}
+	return nil
+}
+
+// qualifiedName returns the name of obj as it must be written at pos.
+func qualifiedName(pass *analysis.Pass, pos token.Pos, obj types.Object) string {
+	if qual := fileQualifier(pass, pos)(obj.Pkg()); qual != "" {
+		return qual + "." + obj.Name()
+	}
+	return obj.Name()
+}
@@ -0,0 +1,134 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// fixBody is set with the -fix-body flag. It names the template of the body
+// of the case clauses inserted by suggested fixes, and takes precedence over
+// fixBody in the configuration.
+var fixBody string
+
+func init() {
+	Analyzer.Flags.StringVar(&fixBody, "fix-body", "",
+		"the body of case clauses inserted by suggested fixes: panic (the default), todo, zero or error")
+}
+
+// The templates of the bodies of inserted case clauses.
+const (
+	// fixBodyPanic panics, so that a case left unimplemented fails loudly.
+	fixBodyPanic = "panic"
+	// fixBodyTODO is a TODO comment naming the cases.
+	fixBodyTODO = "todo"
+	// fixBodyZero returns the zero values of the results of the enclosing
+	// function.
+	fixBodyZero = "zero"
+	// fixBodyError is like fixBodyZero, but returns an error as the last
+	// result if it is an error.
+	fixBodyError = "error"
+)
+
+// fixBodyTemplate returns the template of the body of inserted case clauses
+// given by the -fix-body flag or by the configuration, or fixBodyPanic if
+// neither gives one.
+func fixBodyTemplate(cfg *config) (string, error) {
+	tmpl := fixBody
+	if tmpl == "" {
+		tmpl = cfg.FixBody
+	}
+	switch tmpl {
+	case "":
+		return fixBodyPanic, nil
+	case fixBodyPanic, fixBodyTODO, fixBodyZero, fixBodyError:
+		return tmpl, nil
+	}
+	return "", fmt.Errorf("invalid fix body '%s' (expected panic, todo, zero or error)", tmpl)
+}
+
+// caseBody returns the statement making up the body of a case clause for the
+// given cases inserted at pos, following the template tmpl, along with the
+// edits adding any import it needs.
+func caseBody(pass *analysis.Pass, pos token.Pos, tmpl string, cases []string) (string, []analysis.TextEdit) {
+	switch tmpl {
+	case fixBodyTODO:
+		return "// TODO: handle " + strings.Join(cases, ", "), nil
+	case fixBodyZero, fixBodyError:
+		sig := enclosingSignature(pass, pos)
+		if sig == nil {
+			break
+		}
+		var results []string
+		var edits []analysis.TextEdit
+		for i := 0; i < sig.Results().Len(); i++ {
+			ty := sig.Results().At(i).Type()
+			if tmpl == fixBodyError && i == sig.Results().Len()-1 && isErrorType(ty) {
+				var prefix string
+				prefix, edits = importEdits(pass, pos, "errors", "errors")
+				results = append(results, prefix+`New("unhandled")`)
+				continue
+			}
+			results = append(results, zeroValue(pass, pos, ty))
+		}
+		if len(results) == 0 {
+			return "return", nil
+		}
+		return "return " + strings.Join(results, ", "), edits
+	}
+	return `panic("unhandled")`, nil
+}
+
+// enclosingSignature returns the signature of the innermost function or
+// function literal containing pos, or nil if there is none.
+func enclosingSignature(pass *analysis.Pass, pos token.Pos) *types.Signature {
+	file := enclosingFile(pass, pos)
+	if file == nil {
+		return nil
+	}
+	path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if fn, ok := pass.TypesInfo.Defs[n.Name].(*types.Func); ok {
+				return fn.Type().(*types.Signature)
+			}
+			return nil
+		case *ast.FuncLit:
+			sig, _ := pass.TypesInfo.TypeOf(n).(*types.Signature)
+			return sig
+		}
+	}
+	return nil
+}
+
+// isErrorType returns true if ty is the predeclared error type.
+func isErrorType(ty types.Type) bool {
+	return types.Identical(ty, types.Universe.Lookup("error").Type())
+}
+
+// zeroValue returns an expression for the zero value of ty, as written at pos.
+func zeroValue(pass *analysis.Pass, pos token.Pos, ty types.Type) string {
+	if _, ok := types.Unalias(ty).(*types.TypeParam); ok {
+		return "*new(" + types.TypeString(ty, fileQualifier(pass, pos)) + ")"
+	}
+	switch u := ty.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		}
+	case *types.Struct, *types.Array:
+		return types.TypeString(ty, fileQualifier(pass, pos)) + "{}"
+	}
+	return "nil"
+}
@@ -0,0 +1,66 @@
+package sumtype
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ignoreVariants is set with the -ignore-variants flag. It is a comma
+// separated list of regular expressions, and types whose names match any of
+// them are not variants of any sum type, e.g., generated test doubles.
+var ignoreVariants string
+
+func init() {
+	Analyzer.Flags.StringVar(&ignoreVariants, "ignore-variants", "",
+		"comma separated regular expressions matching names of types that are never variants, e.g., 'Mock$,Stub$'")
+}
+
+// ignorePatterns compiles the patterns given by the -ignore-variants flag
+// and by the configuration.
+func ignorePatterns(cfg *config) ([]*regexp.Regexp, error) {
+	exprs := cfg.IgnoreVariants
+	if ignoreVariants != "" {
+		exprs = append(strings.Split(ignoreVariants, ","), exprs...)
+	}
+	var pats []*regexp.Regexp
+	for _, expr := range exprs {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		pat, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s' for ignored variants: %v", expr, err)
+		}
+		pats = append(pats, pat)
+	}
+	return pats, nil
+}
+
+// dropIgnoredVariants removes the variants whose names match any of the
+// given patterns from the given sum type definitions.
+func dropIgnoredVariants(defs []sumTypeDef, pats []*regexp.Regexp) {
+	if len(pats) == 0 {
+		return
+	}
+	for i := range defs {
+		variants := defs[i].Variants[:0:0]
+		for _, v := range defs[i].Variants {
+			if !matchesAny(pats, v.Name()) {
+				variants = append(variants, v)
+			}
+		}
+		defs[i].Variants = variants
+	}
+}
+
+// matchesAny returns true if name matches any of the given patterns.
+func matchesAny(pats []*regexp.Regexp, name string) bool {
+	for _, pat := range pats {
+		if pat.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package sumtype
+
+import (
+	"go/token"
+	"go/version"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// goVersion returns the Go language version, e.g., "go1.21", that applies to
+// the file containing pos. This is the version given by a `//go:build`
+// constraint in the file if there is one, or the version of the module
+// declaring the package otherwise. If the version is unknown, then the empty
+// string is returned.
+func goVersion(pass *analysis.Pass, pos token.Pos) string {
+	if file := enclosingFile(pass, pos); file != nil && pass.TypesInfo.FileVersions != nil {
+		if v, ok := pass.TypesInfo.FileVersions[file]; ok && v != "" {
+			return v
+		}
+	}
+	return pass.Pkg.GoVersion()
+}
+
+// allowsGenerics returns true if the file containing pos may use generics,
+// which were introduced in Go 1.18. When the version is unknown, generics are
+// assumed to be allowed.
+func allowsGenerics(pass *analysis.Pass, pos token.Pos) bool {
+	v := goVersion(pass, pos)
+	return v == "" || version.Compare(v, "go1.18") >= 0
+}
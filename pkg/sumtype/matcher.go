@@ -0,0 +1,199 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype/directive"
+	"golang.org/x/tools/go/analysis"
+)
+
+// matcherDef is a struct type declared as a matcher of a sum type with a
+// `go-sumtype:matcher ...` directive. A matcher is an alternative to a type
+// switch with a function field for each variant, e.g.,
+//
+//	type ExprMatcher struct {
+//		//go-sumtype:matcher Expr
+//		OnLit func(*Lit) int
+//		OnAdd func(*Add) int
+//	}
+//
+// A field handles the variant that is the type of its first parameter, with
+// or without a pointer. Fields whose first parameter is the sum type itself,
+// e.g., a fallback, are allowed but handle no variant.
+type matcherDef struct {
+	Type *types.TypeName
+	Def  *sumTypeDef
+	// Fields maps each variant of Def to the field handling it.
+	Fields map[types.Object]*types.Var
+}
+
+// checkMatchers checks the matchers declared in the current package: each
+// matcher must have a field for every variant and no field for anything else,
+// and every composite literal of a matcher in the package must set every one
+// of these fields to a value other than nil.
+func checkMatchers(pass *analysis.Pass, res *Result, defs []sumTypeDef, lits []*ast.CompositeLit) {
+	var matchers []*matcherDef
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				tspec := spec.(*ast.TypeSpec)
+				doc := tspec.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				def, pos := directiveDef(pass, defs, declDirectives(file, tspec, doc), directive.Matcher)
+				if def == nil {
+					continue
+				}
+				if m := newMatcherDef(pass, res, def, tspec, pos); m != nil {
+					matchers = append(matchers, m)
+				}
+			}
+		}
+	}
+	if len(matchers) == 0 {
+		return
+	}
+	for _, lit := range lits {
+		for _, m := range matchers {
+			if types.Identical(types.Unalias(pass.TypesInfo.TypeOf(lit)), m.Type.Type()) {
+				checkMatcherLit(pass, res, m, lit)
+			}
+		}
+	}
+}
+
+// newMatcherDef builds the matcher of def declared by tspec and reports the
+// variants it has no field for and the fields that handle no variant. If the
+// type is not a struct, then this is reported and nil is returned.
+func newMatcherDef(
+	pass *analysis.Pass,
+	res *Result,
+	def *sumTypeDef,
+	tspec *ast.TypeSpec,
+	pos token.Pos,
+) *matcherDef {
+	obj, ok := pass.TypesInfo.Defs[tspec.Name].(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		reportDeclf(pass, pos, "matcher '%s' is not a struct", obj.Name())
+		return nil
+	}
+	m := &matcherDef{Type: obj, Def: def, Fields: map[types.Object]*types.Var{}}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		sig, ok := f.Type().Underlying().(*types.Signature)
+		if !ok {
+			continue
+		}
+		if sig.Params().Len() > 0 && findDef([]sumTypeDef{*def}, sig.Params().At(0).Type()) != nil {
+			continue
+		}
+		v := matchedVariant(def, sig)
+		if v == nil || m.Fields[v] != nil {
+			pass.Report(analysis.Diagnostic{
+				Pos:      f.Pos(),
+				Category: CategoryMatcher,
+				Message: fmt.Sprintf("field '%s' of matcher '%s' does not handle a variant of sum type '%s'",
+					f.Name(), obj.Name(), def.Decl.TypeName),
+				Related: def.Decl.related(),
+			})
+			continue
+		}
+		m.Fields[v] = f
+	}
+
+	var missing []types.Object
+	for _, v := range def.Variants {
+		if m.Fields[v] == nil {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		names := missingNames(missing)
+		res.report(pass, &Finding{
+			Diagnostic: analysis.Diagnostic{
+				Pos:      tspec.Pos(),
+				Category: CategoryMatcher,
+				Message: fmt.Sprintf("matcher '%s' for sum type '%s' is missing fields for %s",
+					obj.Name(), def.Decl.TypeName, strings.Join(names, ", ")),
+				Related: def.Decl.related(),
+			},
+			Type:    def.qualifiedName(),
+			Missing: names,
+		})
+	}
+	return m
+}
+
+// matchedVariant returns the variant of def that is the type of the first
+// parameter of sig, with or without a pointer, or nil if there is none.
+func matchedVariant(def *sumTypeDef, sig *types.Signature) types.Object {
+	if sig.Params().Len() == 0 {
+		return nil
+	}
+	param := indirect(sig.Params().At(0).Type())
+	for _, v := range def.Variants {
+		if types.Identical(indirect(v.Type()), param) {
+			return v
+		}
+	}
+	return nil
+}
+
+// checkMatcherLit reports the fields handling variants that the given
+// composite literal of the matcher m leaves nil.
+func checkMatcherLit(pass *analysis.Pass, res *Result, m *matcherDef, lit *ast.CompositeLit) {
+	set := map[string]bool{}
+	for i, elt := range lit.Elts {
+		var name string
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			id, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			name, elt = id.Name, kv.Value
+		} else {
+			name = m.Type.Type().Underlying().(*types.Struct).Field(i).Name()
+		}
+		if tv, ok := pass.TypesInfo.Types[elt]; !ok || !tv.IsNil() {
+			set[name] = true
+		}
+	}
+	var unset []string
+	var variants []string
+	for v, f := range m.Fields {
+		if !set[f.Name()] {
+			unset = append(unset, f.Name())
+			variants = append(variants, v.Name())
+		}
+	}
+	if len(unset) == 0 {
+		return
+	}
+	sort.Strings(unset)
+	sort.Strings(variants)
+	res.report(pass, &Finding{
+		Diagnostic: analysis.Diagnostic{
+			Pos:      lit.Pos(),
+			Category: CategoryMatcher,
+			Message: fmt.Sprintf("matcher '%s' leaves %s nil",
+				m.Type.Name(), strings.Join(unset, ", ")),
+			Related: m.Def.Decl.related(),
+		},
+		Type:    m.Def.qualifiedName(),
+		Missing: variants,
+	})
+}
@@ -0,0 +1,93 @@
+package sumtype
+
+import (
+	"go/types"
+)
+
+// subInterface returns the sum type made up of the variants of this sum type
+// that implement ty, if ty is an interface that is itself a variant, e.g.,
+// `TerminalNode` embedding `Node`. A value of type ty is narrowed to these
+// variants, so a case naming ty covers all of them, and a switch on such a
+// value is checked against them. Otherwise, nil is returned.
+func (def *sumTypeDef) subInterface(ty types.Type) *sumTypeDef {
+	ty = indirect(ty)
+	iface, ok := ty.Underlying().(*types.Interface)
+	if !ok {
+		return nil
+	}
+	var obj types.Object
+	for _, v := range def.Variants {
+		if types.Identical(indirect(v.Type()), ty) {
+			obj = v
+			break
+		}
+	}
+	if obj == nil {
+		return nil
+	}
+	sub := &sumTypeDef{
+		// The sub-interface has no directive of its own.
+		Decl:   sumTypeDecl{Kind: declSumType, Package: obj.Pkg(), TypeName: obj.Name()},
+		Ty:     iface,
+		Parent: def,
+	}
+	if !def.Decl.isComposed() {
+		// Switches over the sub-interface follow the options of the
+		// sum type.
+		sub.Decl.Options = def.Decl.Options
+	}
+	for _, v := range def.Variants {
+		if v == obj {
+			continue
+		}
+		if types.Implements(v.Type(), iface) || types.Implements(types.NewPointer(v.Type()), iface) {
+			sub.Variants = append(sub.Variants, v)
+		}
+	}
+	return sub
+}
+
+// narrowedDef returns the sum type a value of type ty is narrowed to, when ty
+// is an interface variant of one of the given sum types. If there is no such
+// sum type, then nil is returned.
+func narrowedDef(defs []sumTypeDef, ty types.Type) *sumTypeDef {
+	for i := range defs {
+		if sub := defs[i].subInterface(ty); sub != nil {
+			return sub
+		}
+	}
+	return nil
+}
+
+// expandSubInterfaces returns the given types of case clauses with the
+// variants implementing each interface variant among them added, so that a
+// case naming an interface variant covers all of them.
+func (def *sumTypeDef) expandSubInterfaces(tys []types.Type) []types.Type {
+	expanded := append([]types.Type(nil), tys...)
+	for _, ty := range tys {
+		if ty == nil {
+			continue
+		}
+		sub := def.subInterface(ty)
+		if sub == nil {
+			continue
+		}
+		for _, v := range sub.Variants {
+			expanded = append(expanded, v.Type())
+		}
+	}
+	return expanded
+}
+
+// withoutSubInterfaces returns the given missing variants without the
+// interface variants. A value of such an interface always has the type of one
+// of its own variants, which are listed instead when they are missing.
+func (def *sumTypeDef) withoutSubInterfaces(missing []types.Object) []types.Object {
+	var concrete []types.Object
+	for _, v := range missing {
+		if def.subInterface(v.Type()) == nil {
+			concrete = append(concrete, v)
+		}
+	}
+	return concrete
+}
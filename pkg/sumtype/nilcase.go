@@ -0,0 +1,68 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// requireNil is set with the -require-nil flag. When true, every type switch
+// over a sum type must handle a nil value explicitly with a `case nil:`
+// clause. A single sum type can require it with the `nil` option of its
+// declaration instead, as in `go-sumtype:decl Shape nil`.
+var requireNil bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&requireNil, "require-nil", false,
+		"require switches over sum types to have a 'case nil' clause")
+}
+
+// nilOption is the option of a sum type declaration requiring switches over
+// the sum type to have a `case nil:` clause.
+const nilOption = "nil"
+
+// checkNilCase reports the given type switch over a sum type if it has no
+// `case nil:` clause while one is required, either by the -require-nil flag
+// or by the declaration of the sum type. A fix is suggested that adds one.
+func checkNilCase(pass *analysis.Pass, res *Result, sw *switchInfo) {
+	if !requireNil && !sw.Def.Decl.hasOption(nilOption) || !sw.Def.nilable() || !inScope(sw.Def) {
+		return
+	}
+	exprs, _ := caseExprs(sw.Stmt.Body)
+	for _, expr := range exprs {
+		if types.Identical(pass.TypesInfo.TypeOf(expr), types.Typ[types.UntypedNil]) {
+			return
+		}
+	}
+	fix := missingCasesFix(pass, sw.Stmt.Body, []string{"nil"})
+	fix.Message = "Add a case for nil"
+	res.report(pass, &Finding{
+		Diagnostic: analysis.Diagnostic{
+			Pos:            sw.Stmt.Pos(),
+			Category:       CategoryNilCase,
+			Message:        fmt.Sprintf("switch over sum type '%s' has no case for nil", sw.Def.Decl.TypeName),
+			Related:        sw.Def.Decl.related(),
+			SuggestedFixes: []analysis.SuggestedFix{fix},
+		},
+		Type: sw.Def.qualifiedName(),
+	})
+}
+
+// nilable returns true if a value of this sum type can be nil. This is always
+// the case for an interface, but a value of a type parameter constrained by a
+// union of types can only be nil if one of them can be, e.g., a pointer.
+func (def *sumTypeDef) nilable() bool {
+	if def.Ty.IsMethodSet() {
+		return true
+	}
+	var terms []*types.Term
+	collectTerms(def.Ty, &terms)
+	for _, term := range terms {
+		switch term.Type().Underlying().(type) {
+		case *types.Pointer, *types.Map, *types.Slice, *types.Chan, *types.Signature, *types.Interface:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package sumtype
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// presets are the strictness presets accepted by the -preset flag. Each maps
+// the names of other flags to the values the preset sets them to.
+var presets = map[string]map[string]string{
+	// lenient only checks exhaustiveness, which is also the behavior when no
+	// preset is given.
+	"lenient": {
+		"default":               defaultAllow,
+		"enum-require-default":  "false",
+		"require-binding":       "false",
+		"require-default-value": "false",
+		"require-nil":           "false",
+	},
+	// standard also requires panicking default clauses to report the
+	// unexpected variant.
+	"standard": {
+		"default":               defaultAllow,
+		"enum-require-default":  "false",
+		"require-binding":       "false",
+		"require-default-value": "true",
+		"require-nil":           "false",
+	},
+	// strict also requires default clauses to panic, enum switches to handle
+	// values outside of the enum, type switches to handle nil and to bind the
+	// switched variable their cases refer to.
+	"strict": {
+		"default":               defaultMustPanic,
+		"enum-require-default":  "true",
+		"require-binding":       "true",
+		"require-default-value": "true",
+		"require-nil":           "true",
+	},
+}
+
+// presetFlag is the value of the -preset flag. Setting it sets the flags of
+// the named preset, so flags given after it override the preset.
+type presetFlag string
+
+func init() {
+	Analyzer.Flags.Var(new(presetFlag), "preset",
+		"set options to a strictness preset: "+strings.Join(presetNames(), ", ")+
+			" (flags given after -preset override it)")
+}
+
+func (p *presetFlag) String() string { return string(*p) }
+
+func (p *presetFlag) Set(name string) error {
+	opts, ok := presets[name]
+	if !ok {
+		return fmt.Errorf("unknown preset '%s' (expected one of %s)",
+			name, strings.Join(presetNames(), ", "))
+	}
+	for flag, value := range opts {
+		if err := Analyzer.Flags.Set(flag, value); err != nil {
+			return err
+		}
+	}
+	*p = presetFlag(name)
+	return nil
+}
+
+// presetNames returns the names of all presets, sorted.
+func presetNames() []string {
+	var names []string
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,80 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// checkReflectSwitches is set with the -check-reflect-switches flag. When
+// true, switches over the reflect.Type of a sum type value are checked like
+// type switches.
+var checkReflectSwitches bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkReflectSwitches, "check-reflect-switches", false,
+		"also check switches over reflect.TypeOf of a sum type value, with cases like reflect.TypeOf(&Variant{})")
+}
+
+// checkReflectSwitch performs an exhaustiveness check on a switch over the
+// reflect.Type of a sum type value, e.g.:
+//
+//	switch reflect.TypeOf(v) {
+//	case reflect.TypeOf(&Lit{}):
+//	case reflect.TypeFor[*Add]():
+//	}
+//
+// Each case identifies a variant the same way as a registration in a registry
+// does (see registeredType). As with type switches, a default clause that
+// doesn't always panic disables the check.
+func checkReflectSwitch(pass *analysis.Pass, res *Result, defs []sumTypeDef, swtch *ast.SwitchStmt) {
+	call, ok := ast.Unparen(swtch.Tag).(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || !isReflectFunc(pass, call, "TypeOf") {
+		return
+	}
+	def := findDef(defs, pass.TypesInfo.TypeOf(call.Args[0]))
+	if def == nil {
+		return
+	}
+	exprs, hasDefault := caseExprs(swtch.Body)
+	if hasDefault && !defaultClauseTerminates(pass, swtch.Body) {
+		return
+	}
+	var tys []types.Type
+	for _, expr := range exprs {
+		tys = append(tys, registeredType(pass, expr))
+	}
+	if allowsGenerics(pass, swtch.Pos()) {
+		tys = genericOrigins(tys)
+	}
+	missing := def.missing(tys)
+	if len(missing) == 0 {
+		return
+	}
+	for _, group := range groupMissing(missing) {
+		names := missingNames(group)
+		res.report(pass, &Finding{
+			Diagnostic: analysis.Diagnostic{
+				Pos:      swtch.Pos(),
+				Category: CategoryExhaustiveness,
+				Message: fmt.Sprintf(
+					"exhaustiveness check failed for sum type '%s': missing cases for %s",
+					def.Decl.TypeName, strings.Join(names, ", ")),
+				Related: def.Decl.related(),
+			},
+			Type:    def.qualifiedName(),
+			Missing: names,
+		})
+	}
+}
+
+// isReflectFunc returns true if the given call is a call to the function
+// with the given name in the reflect package.
+func isReflectFunc(pass *analysis.Pass, call *ast.CallExpr, name string) bool {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == "reflect" && fn.Name() == name
+}
@@ -0,0 +1,200 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype/directive"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// checkRegistries checks handler registries declared with
+// `go-sumtype:registry ...` directives, written in or above the declaration
+// of a registry (see declDirectives). A registry is either a map variable,
+// whose keys identify variants, or a registration function, whose first
+// argument identifies a variant in every call to it within the package. In
+// both cases, every variant of the sum type must be registered.
+//
+// A variant is identified by an expression of the variant's type, e.g.,
+// `&Lit{}`, or by its reflect.Type, e.g., `reflect.TypeOf(&Lit{})` or
+// `reflect.TypeFor[*Lit]()`.
+func checkRegistries(pass *analysis.Pass, res *Result, defs []sumTypeDef) {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				def, pos := directiveDef(pass, defs, declDirectives(file, decl, decl.Doc), directive.Registry)
+				if def == nil {
+					continue
+				}
+				if len(decl.Type.Params.List) == 0 {
+					reportDeclf(pass, pos, "registry function '%s' has no parameters",
+						decl.Name.Name)
+					continue
+				}
+				fn := pass.TypesInfo.Defs[decl.Name]
+				reportRegistry(pass, res, def, decl.Name, registrations(pass, fn))
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					vspec, ok := spec.(*ast.ValueSpec)
+					if !ok {
+						continue
+					}
+					doc := vspec.Doc
+					if doc == nil && len(decl.Specs) == 1 {
+						doc = decl.Doc
+					}
+					def, pos := directiveDef(pass, defs, declDirectives(file, vspec, doc), directive.Registry)
+					if def == nil {
+						continue
+					}
+					var lit *ast.CompositeLit
+					if len(vspec.Values) == 1 {
+						lit, _ = ast.Unparen(vspec.Values[0]).(*ast.CompositeLit)
+					}
+					if lit == nil {
+						reportDeclf(pass, pos, "registry variable must be "+
+							"initialized with a map literal")
+						continue
+					}
+					var keys []types.Type
+					for _, elt := range lit.Elts {
+						if kv, ok := elt.(*ast.KeyValueExpr); ok {
+							keys = append(keys, registeredType(pass, kv.Key))
+						}
+					}
+					reportRegistry(pass, res, def, lit, keys)
+				}
+			}
+		}
+	}
+}
+
+// directiveDef returns the sum type named by a directive of the given kind,
+// e.g., `go-sumtype:registry ...`, among the given directives, along with the
+// position of the directive. If there is no such directive, or if it doesn't
+// name a sum type, then nil is returned. The latter is also reported.
+func directiveDef(
+	pass *analysis.Pass,
+	defs []sumTypeDef,
+	dirs []directive.Directive,
+	kind directive.Kind,
+) (*sumTypeDef, token.Pos) {
+	for _, d := range dirs {
+		if d.Kind != kind {
+			continue
+		}
+		if len(d.Args) != 1 {
+			reportDeclf(pass, d.Pos, "%s directive requires a single sum type name", kind)
+			return nil, d.Pos
+		}
+		def, err := lookupDef(pass, defs, d.Pos, d.Args[0])
+		switch {
+		case err != nil:
+			reportDeclf(pass, d.Pos, "%s directive: %v", kind, err)
+		case def == nil:
+			reportDeclf(pass, d.Pos, "%s directive names '%s', which is not a sum type",
+				kind, d.Args[0])
+		}
+		return def, d.Pos
+	}
+	return nil, token.NoPos
+}
+
+// registrations returns the types registered by every call to the given
+// registration function in the package, as identified by its first argument.
+func registrations(pass *analysis.Pass, fn types.Object) []types.Type {
+	var tys []types.Type
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 || typeutil.Callee(pass.TypesInfo, call) != fn {
+				return true
+			}
+			tys = append(tys, registeredType(pass, call.Args[0]))
+			return true
+		})
+	}
+	return tys
+}
+
+// registeredType returns the type identified by an expression registering a
+// variant. This is the type of the argument to reflect.TypeOf, the type
+// argument of reflect.TypeFor, or the type of the expression itself.
+func registeredType(pass *analysis.Pass, expr ast.Expr) types.Type {
+	expr = ast.Unparen(expr)
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return pass.TypesInfo.TypeOf(expr)
+	}
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "reflect" {
+		return pass.TypesInfo.TypeOf(expr)
+	}
+	switch fn.Name() {
+	case "TypeOf":
+		if len(call.Args) == 1 {
+			return pass.TypesInfo.TypeOf(call.Args[0])
+		}
+	case "TypeFor":
+		if !allowsGenerics(pass, call.Pos()) {
+			break
+		}
+		inst, ok := pass.TypesInfo.Instances[calleeIdent(call.Fun)]
+		if ok && inst.TypeArgs.Len() == 1 {
+			return inst.TypeArgs.At(0)
+		}
+	}
+	return pass.TypesInfo.TypeOf(expr)
+}
+
+// calleeIdent returns the identifier naming the function in the callee
+// expression of a call, e.g., `TypeFor` in `reflect.TypeFor[T]`.
+func calleeIdent(fun ast.Expr) *ast.Ident {
+	switch fun := ast.Unparen(fun).(type) {
+	case *ast.IndexExpr:
+		return calleeIdent(fun.X)
+	case *ast.IndexListExpr:
+		return calleeIdent(fun.X)
+	case *ast.SelectorExpr:
+		return fun.Sel
+	case *ast.Ident:
+		return fun
+	}
+	return nil
+}
+
+// reportRegistry reports the variants of def that are missing from the given
+// registered types at node.
+func reportRegistry(
+	pass *analysis.Pass,
+	res *Result,
+	def *sumTypeDef,
+	node ast.Node,
+	registered []types.Type,
+) {
+	if allowsGenerics(pass, node.Pos()) {
+		registered = genericOrigins(registered)
+	}
+	missing := def.missing(registered)
+	if len(missing) == 0 {
+		return
+	}
+	names := missingNames(missing)
+	res.report(pass, &Finding{
+		Diagnostic: analysis.Diagnostic{
+			Pos:      node.Pos(),
+			Category: CategoryRegistry,
+			Message: fmt.Sprintf(
+				"registry for sum type '%s' is missing registrations for %s",
+				def.Decl.TypeName, strings.Join(names, ", ")),
+			Related: def.Decl.related(),
+		},
+		Type:    def.qualifiedName(),
+		Missing: names,
+	})
+}
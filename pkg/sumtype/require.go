@@ -0,0 +1,120 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkRequirements checks the `go-sumtype:require Type Interface...`
+// declarations in the current package, which list interfaces that every
+// variant of a sum type must implement, e.g., fmt.Stringer, so that a new
+// variant can't silently break logging or encoding. Each variant that doesn't
+// implement one of the interfaces is reported at its declaration.
+//
+// A variant implements an interface in the same way as it implements the sum
+// type: if only a pointer to the variant implements the sum type, then the
+// pointer must implement the interface.
+func checkRequirements(pass *analysis.Pass, res *Result, decls []sumTypeDecl, defs []sumTypeDef) {
+	for _, decl := range decls {
+		if decl.Kind != declRequire {
+			continue
+		}
+		def := findDefByName(defs, decl.TypeName)
+		if def == nil || def.Decl.Package != pass.Pkg {
+			reportDeclf(pass, decl.Pos, "require directive names '%s', "+
+				"which is not a sum type declared in this package", decl.TypeName)
+			continue
+		}
+		if len(decl.Options) == 0 {
+			reportDeclf(pass, decl.Pos, "require directive for '%s' lists no interfaces",
+				decl.TypeName)
+			continue
+		}
+		for _, name := range decl.Options {
+			iface, err := lookupInterface(pass.Pkg, name)
+			if err != nil {
+				reportDeclf(pass, decl.Pos, "%s", err)
+				continue
+			}
+			for _, v := range def.Variants {
+				if enclosingFile(pass, v.Pos()) == nil {
+					continue
+				}
+				reportRequirement(pass, res, def, decl, v, name, iface)
+			}
+		}
+	}
+}
+
+// reportRequirement reports the variant v of def if it doesn't implement the
+// interface iface, which is written as name in the directive decl.
+func reportRequirement(
+	pass *analysis.Pass,
+	res *Result,
+	def *sumTypeDef,
+	decl sumTypeDecl,
+	v types.Object,
+	name string,
+	iface *types.Interface,
+) {
+	ty := v.Type()
+	if !types.Implements(ty, def.Ty) {
+		ty = types.NewPointer(ty)
+	}
+	m, _ := types.MissingMethod(ty, iface, true)
+	if m == nil {
+		return
+	}
+	res.report(pass, &Finding{
+		Diagnostic: analysis.Diagnostic{
+			Pos:      v.Pos(),
+			Category: CategoryRequire,
+			Message: fmt.Sprintf(
+				"variant '%s' of sum type '%s' does not implement %s (missing method %s)",
+				v.Name(), def.Decl.TypeName, name, m.Name()),
+			Related: []analysis.RelatedInformation{{
+				Pos:     decl.Pos,
+				Message: fmt.Sprintf("%s required here", name),
+			}},
+		},
+		Type:    def.qualifiedName(),
+		Missing: []string{v.Name()},
+	})
+}
+
+// lookupInterface returns the interface with the given name as seen from pkg.
+// The name is either declared in pkg or in the universe, e.g., `error`, or it
+// is qualified by the name or the path of a package imported by pkg, directly
+// or indirectly, e.g., `fmt.Stringer` or `encoding/json.Marshaler`.
+func lookupInterface(pkg *types.Package, name string) (*types.Interface, error) {
+	var obj types.Object
+	if i := strings.LastIndex(name, "."); i < 0 {
+		if obj = pkg.Scope().Lookup(name); obj == nil {
+			obj = types.Universe.Lookup(name)
+		}
+	} else if imp := importedPackage(pkg, name[:i]); imp != nil {
+		obj = imp.Scope().Lookup(name[i+1:])
+	}
+	if _, ok := obj.(*types.TypeName); !ok {
+		return nil, fmt.Errorf("type '%s' is not defined", name)
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("type '%s' is not an interface", name)
+	}
+	return iface, nil
+}
+
+// importedPackage returns the package imported by pkg with the given name or
+// path, or nil if there is none. Packages imported directly take precedence.
+func importedPackage(pkg *types.Package, qual string) *types.Package {
+	for _, imp := range pkg.Imports() {
+		if imp.Name() == qual || imp.Path() == qual {
+			return imp
+		}
+	}
+	return findImport(pkg, qual)
+}
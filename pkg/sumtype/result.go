@@ -0,0 +1,213 @@
+package sumtype
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Result is the result of running the Analyzer on a single package. It
+// describes the sum types declared in that package, and may be used by other
+// analyzers and by tools that embed go-sumtype.
+type Result struct {
+	// SumTypes are the sum types declared in the package.
+	SumTypes []*SumType
+	// Switches are the type switches over sum types in the package.
+	Switches []*Switch
+	// Findings are the structured details of the exhaustiveness problems
+	// reported for the package.
+	Findings []*Finding
+	// Trace are the decisions made while checking the type switches of the
+	// package, in the order in which they were made. They are recorded for
+	// the packages of the main modules whether or not the -trace flag is
+	// given, which only logs them as well.
+	Trace []*TraceEntry
+}
+
+// TraceEntry is a decision made while checking a type switch, e.g., that one
+// of its cases covers a variant.
+type TraceEntry struct {
+	// Pos is the position of the node the decision is about, e.g., the
+	// switch or one of its cases.
+	Pos token.Pos
+	// Message describes the decision.
+	Message string
+}
+
+// Finding holds the structured details of a problem reported by the
+// Analyzer that are otherwise only available in its diagnostic message.
+type Finding struct {
+	// Diagnostic is the diagnostic that was reported.
+	Diagnostic analysis.Diagnostic
+	// Type is the name of the sum type or enum involved, qualified by its
+	// package path, e.g., github.com/foo/ast.Expr.
+	Type string
+	// Missing are the names of the variants or members that are missing,
+	// sorted by name.
+	Missing []string
+}
+
+// Categories of the diagnostics reported by the Analyzer.
+const (
+	// CategoryDeclaration is the category of problems with a declaration,
+	// e.g., a `go-sumtype:decl ...` naming a type that is not sealed.
+	CategoryDeclaration = "declaration"
+	// CategoryExhaustiveness is the category of switches that do not
+	// handle every variant of a sum type or every member of an enum.
+	CategoryExhaustiveness = "exhaustiveness"
+	// CategoryDispatchTable is the category of dispatch tables keyed by
+	// a bitflag enum that are missing flags.
+	CategoryDispatchTable = "dispatch-table"
+	// CategoryLookupTable is the category of arrays indexed by an enum that
+	// are missing members.
+	CategoryLookupTable = "lookup-table"
+	// CategoryEnumDefault is the category of enum switches without a
+	// default clause when one is required.
+	CategoryEnumDefault = "enum-default"
+	// CategoryDefaultPanic is the category of panicking default clauses of
+	// switches over sum types that do not mention the switched value, when
+	// such mentions are required.
+	CategoryDefaultPanic = "default-panic"
+	// CategoryBinding is the category of switches over sum types that
+	// refer to the switched variable in their cases without binding it, when
+	// such bindings are required.
+	CategoryBinding = "binding"
+	// CategoryNilCase is the category of switches over sum types without a
+	// `case nil:` clause, when such clauses are required.
+	CategoryNilCase = "nil-case"
+	// CategoryDefaultPolicy is the category of default clauses of switches
+	// over sum types that violate the policy for them, e.g., because default
+	// clauses are forbidden.
+	CategoryDefaultPolicy = "default-policy"
+	// CategoryRegistry is the category of handler registries that do not
+	// register every variant of a sum type.
+	CategoryRegistry = "registry"
+	// CategoryUnhandledVariant is the category of variants that are missing
+	// from a switch, reported at the variant's declaration when requested.
+	CategoryUnhandledVariant = "unhandled-variant"
+	// CategoryRequire is the category of variants that do not implement an
+	// interface required by a `go-sumtype:require ...` directive.
+	CategoryRequire = "require"
+	// CategoryEmbedding is the category of variants that embed their sum
+	// type, which unseals it.
+	CategoryEmbedding = "embedding"
+	// CategoryEscape is the category of values of sum types that escape into
+	// an empty interface in an exported API, when such escapes are reported.
+	CategoryEscape = "escape"
+	// CategoryMatcher is the category of matcher structs, declared with
+	// `go-sumtype:matcher ...`, that lack a field for a variant, and of
+	// literals of them that leave such a field nil.
+	CategoryMatcher = "matcher"
+	// CategoryConstructs is the category of factory functions, declared with
+	// `go-sumtype:constructs ...`, that never construct a variant.
+	CategoryConstructs = "constructs"
+	// CategoryWrappedError is the category of type switches over sum types
+	// of errors whose switched value may have been wrapped, e.g., by
+	// fmt.Errorf with %w, so that wrapped variants match no case.
+	CategoryWrappedError = "wrapped-error"
+)
+
+// SumType describes a sum type declared with `go-sumtype:decl ...`.
+type SumType struct {
+	// Type is the declared interface type.
+	Type *types.TypeName
+	// Decl is the position of the `go-sumtype:decl` directive, or of the
+	// interface itself if it was found with -auto-seal.
+	Decl token.Pos
+	// Variants are the types in the same package that implement the sum
+	// type, sorted by name.
+	Variants []*types.TypeName
+}
+
+// Switch describes a type switch over a sum type.
+type Switch struct {
+	// Stmt is the type switch statement.
+	Stmt *ast.TypeSwitchStmt
+	// SumType is the sum type being switched on.
+	SumType *SumType
+	// Handled are the variants that have a case clause in the switch,
+	// excluding interface variants, which are covered by their own variants.
+	Handled []*types.TypeName
+	// Missing are the variants that have no case clause in the switch.
+	Missing []*types.TypeName
+	// HasDefault is true if the switch has a default clause.
+	HasDefault bool
+	// Narrowed is the interface variant of the sum type that the switched
+	// value has, e.g., TerminalNode embedding Node, or nil if there is none.
+	// Handled and Missing then only include the variants implementing it.
+	Narrowed *types.TypeName
+}
+
+// report reports the diagnostic of the given finding and records the finding
+// in the result.
+func (res *Result) report(pass *analysis.Pass, f *Finding) {
+	pass.Report(f.Diagnostic)
+	res.Findings = append(res.Findings, f)
+}
+
+// describe records the sum type definitions and the switches over them found
+// in the package pkg. Only sum types declared in pkg are included in
+// SumTypes, but switches over sum types imported from other packages are
+// included in Switches.
+func (res *Result) describe(pkg *types.Package, defs []sumTypeDef, switches []*switchInfo) {
+	byDef := map[*sumTypeDef]*SumType{}
+	for i := range defs {
+		def := &defs[i]
+		obj, ok := def.Decl.Package.Scope().Lookup(def.Decl.TypeName).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		st := &SumType{
+			Type:     obj,
+			Decl:     def.Decl.Pos,
+			Variants: typeNames(def.Variants),
+		}
+		byDef[def] = st
+		if def.Decl.Package == pkg {
+			res.SumTypes = append(res.SumTypes, st)
+		}
+	}
+	for _, sw := range switches {
+		// A switch over a sum type narrowed to an interface variant is a
+		// switch over the sum type the variant belongs to.
+		def := sw.Def
+		var narrowed *types.TypeName
+		if def.Parent != nil {
+			def = def.Parent
+			narrowed, _ = sw.Def.Decl.Package.Scope().Lookup(sw.Def.Decl.TypeName).(*types.TypeName)
+		}
+		st := byDef[def]
+		if st == nil {
+			continue
+		}
+		missing := map[types.Object]bool{}
+		for _, v := range sw.Missing {
+			missing[v] = true
+		}
+		var handled []types.Object
+		for _, v := range sw.Def.Variants {
+			if !missing[v] && sw.Def.subInterface(v.Type()) == nil {
+				handled = append(handled, v)
+			}
+		}
+		res.Switches = append(res.Switches, &Switch{
+			Stmt:       sw.Stmt,
+			SumType:    st,
+			Handled:    typeNames(handled),
+			Missing:    typeNames(sw.Missing),
+			HasDefault: sw.HasDefault,
+			Narrowed:   narrowed,
+		})
+	}
+}
+
+// typeNames converts a list of variants to the type names they are.
+func typeNames(objs []types.Object) []*types.TypeName {
+	var names []*types.TypeName
+	for _, obj := range objs {
+		names = append(names, obj.(*types.TypeName))
+	}
+	return names
+}
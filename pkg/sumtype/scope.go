@@ -0,0 +1,38 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+// scope is set with the -scope flag. It selects the sum types whose switches
+// must be exhaustive: all of them, or only the exported ones, which make up
+// the public contract of a library, so that internal sum types can change
+// freely while they are being iterated on.
+var scope string
+
+func init() {
+	Analyzer.Flags.StringVar(&scope, "scope", scopeAll,
+		"the sum types whose switches must be exhaustive: all, or exported for only exported sum types")
+}
+
+// The scopes accepted by the -scope flag.
+const (
+	scopeAll      = "all"
+	scopeExported = "exported"
+)
+
+// checkScope returns an error if the -scope flag is invalid.
+func checkScope() error {
+	switch scope {
+	case scopeAll, scopeExported:
+		return nil
+	}
+	return fmt.Errorf("invalid scope '%s' (expected all or exported)", scope)
+}
+
+// inScope returns true if the switches over the given sum type must be
+// exhaustive.
+func inScope(def *sumTypeDef) bool {
+	return scope != scopeExported || ast.IsExported(def.Decl.TypeName)
+}
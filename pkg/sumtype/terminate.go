@@ -0,0 +1,87 @@
+package sumtype
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// terminators are the functions and methods, other than panic, that never
+// return to their caller, keyed by package path. Methods are named by their
+// receiver type, as in `T.Fatal`.
+var terminators = map[string]map[string]bool{
+	"log": {
+		"Fatal": true, "Fatalf": true, "Fatalln": true,
+		"Panic": true, "Panicf": true, "Panicln": true,
+		"Logger.Fatal": true, "Logger.Fatalf": true, "Logger.Fatalln": true,
+		"Logger.Panic": true, "Logger.Panicf": true, "Logger.Panicln": true,
+	},
+	"os": {
+		"Exit": true,
+	},
+	"runtime": {
+		"Goexit": true,
+	},
+	"testing": {
+		// The methods of *testing.T, *testing.B and *testing.F are
+		// promoted from testing.common.
+		"common.Fatal": true, "common.Fatalf": true, "common.FailNow": true,
+		"common.Skip": true, "common.Skipf": true, "common.SkipNow": true,
+		"TB.Fatal": true, "TB.Fatalf": true, "TB.FailNow": true,
+		"TB.Skip": true, "TB.Skipf": true, "TB.SkipNow": true,
+	},
+}
+
+// terminatingCall returns the call that ends the given clause if the clause
+// never completes normally: its last statement is a call to panic or to one
+// of the terminators, e.g., log.Fatalf or t.Fatal, and the statements before
+// it can't leave the clause, e.g., by returning. Otherwise, nil is returned.
+func terminatingCall(pass *analysis.Pass, clause *ast.CaseClause) *ast.CallExpr {
+	if len(clause.Body) == 0 {
+		return nil
+	}
+	for _, stmt := range clause.Body[:len(clause.Body)-1] {
+		switch stmt.(type) {
+		case *ast.ExprStmt, *ast.AssignStmt, *ast.DeclStmt, *ast.IncDecStmt, *ast.SendStmt:
+		default:
+			return nil
+		}
+	}
+	exprStmt, ok := clause.Body[len(clause.Body)-1].(*ast.ExprStmt)
+	if !ok {
+		return nil
+	}
+	call, ok := exprStmt.X.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	if isPanic(call) || isTerminator(typeutil.Callee(pass.TypesInfo, call)) {
+		return call
+	}
+	return nil
+}
+
+// isPanic returns true if the given call is a call to panic.
+func isPanic(call *ast.CallExpr) bool {
+	fun, ok := call.Fun.(*ast.Ident)
+	return ok && fun.Name == "panic"
+}
+
+// isTerminator returns true if obj is one of the terminators.
+func isTerminator(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return false
+	}
+	name := fn.Name()
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		named, ok := indirect(recv.Type()).(*types.Named)
+		if !ok {
+			return false
+		}
+		name = named.Obj().Name() + "." + name
+	}
+	return terminators[fn.Pkg().Path()][name]
+}
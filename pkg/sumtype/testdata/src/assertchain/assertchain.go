@@ -0,0 +1,124 @@
+package assertchain
+
+import "log"
+
+//go-sumtype:decl Expr
+
+type Expr interface{ expr() } // want Expr:`sumtype\(Add, Lit, Neg\)`
+
+type Lit struct{ V int }
+
+func (*Lit) expr() {}
+
+type Add struct{ X, Y Expr }
+
+func (*Add) expr() {}
+
+type Neg struct{ X Expr }
+
+func (*Neg) expr() {}
+
+// TestAssertionChainMissing
+func eval(e Expr) int {
+	if v, ok := e.(*Lit); ok { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Neg"
+		return v.V
+	} else if v, ok := e.(*Add); ok {
+		return eval(v.X) + eval(v.Y)
+	}
+	return 0
+}
+
+// TestAssertionChainElse
+func name(e Expr) string {
+	if _, ok := e.(*Lit); ok { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Add"
+		return "lit"
+	} else if _, ok := e.(*Neg); ok {
+		return "neg"
+	} else {
+		log.Fatalf("unexpected %T", e)
+	}
+	return ""
+}
+
+// TestAssertionChainNone
+func count(e Expr) int {
+	if _, ok := e.(*Lit); ok {
+		return 1
+	} else if v, ok := e.(*Add); ok {
+		return count(v.X) + count(v.Y)
+	} else if v, ok := e.(*Neg); ok {
+		return count(v.X)
+	}
+	return 0
+}
+
+// TestAssertionChainDefault: an else block that doesn't terminate disables
+// the check.
+func isLit(e Expr) bool {
+	if _, ok := e.(*Lit); ok {
+		return true
+	} else if _, ok := e.(*Add); ok {
+		return false
+	} else {
+		return false
+	}
+}
+
+// TestAssertionChainSingle: a single assertion is not a chain.
+func lit(e Expr) *Lit {
+	if v, ok := e.(*Lit); ok {
+		return v
+	}
+	return nil
+}
+
+// TestAssertionChainCondition: a link must only test ok.
+func positive(e Expr) bool {
+	if v, ok := e.(*Lit); ok && v.V > 0 {
+		return true
+	} else if _, ok := e.(*Add); ok {
+		return false
+	}
+	return false
+}
+
+// TestAssertionChainNames: links binding different names get no fix.
+func names(e Expr) int {
+	if l, ok := e.(*Lit); ok { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Neg"
+		return l.V
+	} else if a, ok := e.(*Add); ok {
+		return names(a.X)
+	}
+	return 0
+}
+
+// TestAssertionChainBreak: a break would break out of the switch instead.
+func first(es []Expr) {
+	for _, e := range es {
+		if _, ok := e.(*Lit); ok { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Neg"
+			break
+		} else if _, ok := e.(*Add); ok {
+			continue
+		}
+	}
+}
+
+// TestAssertionChainShadow: v would be shadowed by the binding of the switch.
+func shadow(e, v Expr) Expr {
+	if _, ok := e.(*Lit); ok { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Neg"
+		return v
+	} else if v, ok := e.(*Add); ok {
+		return v.X
+	}
+	return nil
+}
+
+// TestAssertionChainOK: ok is only true in its own link.
+func useOK(e Expr) bool {
+	if _, ok := e.(*Lit); ok { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Neg"
+		return ok
+	} else if _, ok := e.(*Add); ok {
+		return true
+	}
+	return false
+}
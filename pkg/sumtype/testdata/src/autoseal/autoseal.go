@@ -0,0 +1,57 @@
+package autoseal
+
+type Shape interface{ shape() } // want Shape:`sumtype\(Circle, Square\)`
+
+type Circle struct{}
+
+func (Circle) shape() {}
+
+type Square struct{}
+
+func (*Square) shape() {}
+
+//go-sumtype:decl State
+
+type State interface{ state() } // want State:`sumtype\(Idle, Running\)`
+
+type Idle struct{}
+
+func (Idle) state() {}
+
+type Running struct{}
+
+func (Running) state() {}
+
+// Open can be implemented in any package, so it is not a sum type.
+type Open interface{ Open() }
+
+type File struct{}
+
+func (File) Open() {}
+
+// Generic interfaces and constraints are skipped.
+type Box[T any] interface{ box() T }
+
+type number interface {
+	~int | ~float64
+	number()
+}
+
+func area(s Shape) {
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'Shape': missing cases for Square"
+	case Circle:
+	}
+}
+
+func step(s State) {
+	// The declared sum type is only checked once.
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'State': missing cases for Running"
+	case Idle:
+	}
+}
+
+func open(o Open) {
+	switch o.(type) {
+	case File:
+	}
+}
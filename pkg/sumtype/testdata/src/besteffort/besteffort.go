@@ -0,0 +1,55 @@
+package besteffort
+
+//go-sumtype:decl T
+
+type T interface{ sealed() } // want T:`sumtype\(A, B\)`
+
+type A struct{}
+
+func (*A) sealed() {}
+
+type B struct{}
+
+func (*B) sealed() {}
+
+// Switches are still checked in a package that doesn't type-check, e.g.,
+// while it is being edited.
+func checked(t T) int {
+	x := undefined()
+	switch t.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for B"
+	case *A:
+		return x
+	}
+	return 0
+}
+
+func unresolvedCase(t T) {
+	switch t.(type) {
+	case *A:
+	case *Undefined:
+	}
+}
+
+func unresolvedSubject() {
+	switch undefined().(type) {
+	case *A:
+	}
+}
+
+func unresolvedExpect(v interface{}) {
+	//go-sumtype:expect T
+	switch v.(type) {
+	case *A:
+	case Undefined:
+	}
+}
+
+func unresolvedTag() {
+	switch undefined() {
+	case 1:
+	}
+}
+
+var _ = map[Undefined]func(){}
+
+var _ = Undefined{A: nil}
@@ -0,0 +1,82 @@
+package binding
+
+import "fmt"
+
+//go-sumtype:decl T
+
+type T interface{ sealed() } // want T:`sumtype\(A, B\)`
+
+type A struct{ Name string }
+
+func (*A) sealed() {}
+
+type B struct{ N int }
+
+func (*B) sealed() {}
+
+func asserted(t T) string {
+	switch t.(type) { // want `switch over sum type 'T' refers to t in its cases without binding it: use 'switch t := t.\(type\)'`
+	case *A:
+		return t.(*A).Name
+	case *B:
+		return fmt.Sprint(t.(*B).N)
+	}
+	return ""
+}
+
+func passed(t T) {
+	switch t.(type) { // want "switch over sum type 'T' refers to t in its cases without binding it"
+	case *A, *B:
+		fmt.Println(t)
+	}
+}
+
+func commaOk(t T) int {
+	// Binding t would break the comma-ok assertion, so no fix is suggested.
+	switch t.(type) { // want "switch over sum type 'T' refers to t in its cases without binding it"
+	case *B:
+		if b, ok := t.(*B); ok {
+			return b.N
+		}
+	case *A:
+	}
+	return 0
+}
+
+func reassigned(t T) {
+	// Binding t would no longer assign the variable declared outside.
+	switch t.(type) { // want "switch over sum type 'T' refers to t in its cases without binding it"
+	case *A:
+		t = &B{}
+	case *B:
+	}
+	_ = t
+}
+
+func bound(t T) string {
+	switch t := t.(type) {
+	case *A:
+		return t.Name
+	case *B:
+		return fmt.Sprint(t.N)
+	}
+	return ""
+}
+
+func unreferenced(t T) string {
+	switch t.(type) {
+	case *A:
+		return "a"
+	case *B:
+		return "b"
+	}
+	return ""
+}
+
+func field(s struct{ t T }) {
+	switch s.t.(type) {
+	case *A:
+		fmt.Println(s.t)
+	case *B:
+	}
+}
@@ -0,0 +1,47 @@
+package ast
+
+//go-sumtype:decl Expr
+
+type Expr interface { // want Expr:`sumtype\(Add, Lit, Neg\)`
+	expr()
+	Kind() Kind
+}
+
+//go-sumtype:bridge Kind Expr
+
+type Kind int // want Kind:`bridge\(Expr: KindLit=Lit, KindAdd=Add, KindNeg=Neg\)`
+
+const (
+	KindLit Kind = iota
+	KindAdd
+	KindNeg
+)
+
+type Lit struct{}
+
+func (*Lit) expr()      {}
+func (*Lit) Kind() Kind { return KindLit }
+
+type Add struct{}
+
+func (*Add) expr()      {}
+func (*Add) Kind() Kind { return KindAdd }
+
+type Neg struct{}
+
+func (*Neg) expr()      {}
+func (*Neg) Kind() Kind { return KindNeg }
+
+func name(e Expr) string {
+	// TestBridgeMissing
+	switch e.Kind() { // want "exhaustiveness check failed for sum type 'Expr' by kind: missing cases for Add \\(KindAdd\\), Neg \\(KindNeg\\)"
+	case KindLit:
+		return "lit"
+	}
+
+	// TestBridgeNone
+	switch e.Kind() {
+	case KindLit, KindAdd, KindNeg:
+	}
+	return ""
+}
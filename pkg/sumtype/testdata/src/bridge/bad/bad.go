@@ -0,0 +1,37 @@
+package bad
+
+//go-sumtype:decl Shape
+
+type Shape interface { // want Shape:`sumtype\(Circle, Square, Triangle\)`
+	shape()
+	Kind() Kind
+}
+
+//go-sumtype:bridge Kind Shape // want "variants 'Circle' and 'Square' of sum type 'Shape' have the same kind 'KindCircle'" "cannot determine the kind of variant 'Triangle': method 'Kind' must only return a constant"
+
+type Kind int
+
+const (
+	KindCircle Kind = iota
+	KindSquare
+	KindTriangle
+)
+
+type Circle struct{}
+
+func (*Circle) shape()     {}
+func (*Circle) Kind() Kind { return KindCircle }
+
+type Square struct{}
+
+func (*Square) shape()     {}
+func (*Square) Kind() Kind { return KindCircle }
+
+type Triangle struct{ k Kind }
+
+func (*Triangle) shape()       {}
+func (t *Triangle) Kind() Kind { return t.k }
+
+//go-sumtype:bridge Missing Shape // want "type 'Missing' is not defined"
+
+//go-sumtype:bridge Kind Other // want "bridge directive names 'Other', which is not a sum type declared in this package"
@@ -0,0 +1,10 @@
+package bridge
+
+import "bridge/ast"
+
+func eval(e ast.Expr) {
+	// TestBridgeImported
+	switch e.Kind() { // want "exhaustiveness check failed for sum type 'Expr' by kind: missing cases for Neg \\(ast.KindNeg\\)"
+	case ast.KindLit, ast.KindAdd:
+	}
+}
@@ -0,0 +1,91 @@
+package compose
+
+//go-sumtype:decl Node = Expr | Stmt
+//go-sumtype:decl Expr
+//go-sumtype:decl Stmt
+
+type Node interface { // want Node:`sumtype\(Add, Assign, Lit, Return\) = Expr \| Stmt`
+	node()
+}
+
+type Expr interface { // want Expr:`sumtype\(Add, Lit\)`
+	Node
+	expr()
+}
+
+type Stmt interface { // want Stmt:`sumtype\(Assign, Return\)`
+	Node
+	stmt()
+}
+
+type Lit struct{}
+
+func (Lit) node() {}
+func (Lit) expr() {}
+
+type Add struct{}
+
+func (*Add) node() {}
+func (*Add) expr() {}
+
+type Assign struct{}
+
+func (Assign) node() {}
+func (Assign) stmt() {}
+
+type Return struct{}
+
+func (Return) node() {}
+func (Return) stmt() {}
+
+func parts(n Node) {
+	switch n.(type) {
+	case Expr, Stmt:
+	}
+}
+
+func leaves(n Node) {
+	switch n.(type) {
+	case Lit, *Add, Assign, Return:
+	}
+}
+
+func mixed(n Node) {
+	switch n.(type) {
+	case Expr, Assign, Return:
+	}
+}
+
+func missing(n Node) {
+	switch n.(type) { // want "exhaustiveness check failed for sum type 'Node': missing cases for Assign, Return"
+	case Expr:
+	}
+}
+
+func missingLeaf(n Node) {
+	switch n.(type) { // want "missing cases for Add"
+	case Lit, Stmt:
+	}
+}
+
+type Decl interface{ decl() }
+
+type Other interface{ other() } // want Other:`sumtype\(O\)`
+
+type O struct{}
+
+func (O) other() {}
+
+//go-sumtype:decl Other
+
+//go-sumtype:decl Bad1 = Expr | Decl // want "'Decl' in the declaration of 'Bad1' is not a sum type declared in this package"
+
+type Bad1 interface{ node() }
+
+//go-sumtype:decl Bad2 = Expr | Other // want "sum type 'Other' does not implement 'Bad2'"
+
+type Bad2 interface{ node() }
+
+//go-sumtype:decl Bad3 = Expr | // want "composed sum type 'Bad3' requires the names of sum types separated by '|'"
+
+type Bad3 interface{ node() }
@@ -0,0 +1,15 @@
+package use
+
+import "compose"
+
+func parts(n compose.Node) {
+	switch n.(type) {
+	case compose.Expr, compose.Stmt:
+	}
+}
+
+func missing(n compose.Node) {
+	switch n.(type) { // want "exhaustiveness check failed for sum type 'Node': missing cases for Add, Lit"
+	case compose.Stmt:
+	}
+}
@@ -0,0 +1,63 @@
+package constructs
+
+import "errors"
+
+//go-sumtype:decl Expr
+
+type Expr interface{ expr() } // want Expr:`sumtype\(Add, Bad, Lit, Neg\)`
+
+type Lit int
+
+func (Lit) expr() {}
+
+type Add struct{ X, Y Expr }
+
+func (*Add) expr() {}
+
+type Neg struct{ X Expr }
+
+func (*Neg) expr() {}
+
+type Bad struct{}
+
+func (Bad) expr() {}
+
+func parse(s string) (Expr, error) { // want "factory 'parse' for sum type 'Expr' never constructs Neg"
+	//go-sumtype:constructs Expr except Bad
+	switch s {
+	case "":
+		return nil, errors.New("empty")
+	case "+":
+		x, _ := parse(s[1:])
+		e := &Add{X: x, Y: x}
+		return e, nil
+	case "-":
+		// Function literals don't count.
+		f := func() Expr { return &Neg{} }
+		_ = f
+	}
+	return parseLit(s), nil
+}
+
+func parseLit(s string) Lit {
+	return Lit(len(s))
+}
+
+func parseAll(s string) Expr {
+	//go-sumtype:constructs Expr
+	switch s {
+	case "+":
+		return &Add{}
+	case "-":
+		return &Neg{}
+	case "!":
+		return Bad{}
+	}
+	return Lit(0)
+}
+
+func broken() {
+	//go-sumtype:constructs Expr except Mul // want "'Mul' is not a variant of sum type 'Expr'"
+	//go-sumtype:constructs Expr Bad // want "constructs directive requires a sum type name"
+	//go-sumtype:constructs Stmt // want "constructs directive names 'Stmt', which is not a sum type"
+}
@@ -0,0 +1,77 @@
+package defaultpanic
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+//go-sumtype:decl T
+
+type T interface{ sealed() } // want T:`sumtype\(A\)`
+
+type A struct{}
+
+func (*A) sealed() {}
+
+func get() T { return nil }
+
+func defaults(t T) {
+	// TestDefaultPanicBound
+	switch x := t.(type) {
+	case *A:
+		_ = x
+	default: // want "default clause of switch over sum type 'T' panics without mentioning the switched value"
+		panic("unreachable")
+	}
+
+	// TestDefaultPanicUnbound
+	switch t.(type) {
+	case *A:
+	default: // want "default clause of switch over sum type 'T' panics without mentioning the switched value"
+		panic("100% unreachable")
+	}
+
+	// TestDefaultPanicMentioned
+	switch x := t.(type) {
+	case *A:
+	default:
+		panic(x)
+	}
+
+	// TestDefaultPanicCall
+	switch get().(type) {
+	case *A:
+	default: // want "default clause of switch over sum type 'T' panics without mentioning the switched value"
+		panic("unreachable")
+	}
+
+	// TestDefaultExit
+	switch t.(type) {
+	case *A:
+	default: // want "default clause of switch over sum type 'T' terminates without mentioning the switched value"
+		os.Exit(1)
+	}
+
+	// TestDefaultFatal
+	switch x := t.(type) {
+	case *A:
+	default:
+		log.Fatalf("unexpected %T", x)
+	}
+
+	// TestDefaultMentionedBeforeExit
+	switch t.(type) {
+	case *A:
+	default:
+		fmt.Fprintf(os.Stderr, "unexpected %T\n", t)
+		os.Exit(1)
+	}
+
+	// TestDefaultNotTerminating
+	switch t.(type) {
+	case *A:
+	default:
+		log.Printf("unexpected %T", t)
+	}
+}
@@ -0,0 +1,82 @@
+package defaultpolicy
+
+//go-sumtype:decl Shape default=forbid
+
+type Shape interface{ shape() } // want Shape:`sumtype\(Circle, Square\) default=forbid`
+
+type Circle struct{}
+
+func (Circle) shape() {}
+
+type Square struct{}
+
+func (Square) shape() {}
+
+//go-sumtype:decl Color default=must-panic
+
+type Color interface{ color() } // want Color:`sumtype\(Blue, Red\) default=must-panic`
+
+type Red struct{}
+
+func (Red) color() {}
+
+type Blue struct{}
+
+func (Blue) color() {}
+
+//go-sumtype:decl Size
+
+type Size interface{ size() } // want Size:`sumtype\(Large, Small\)`
+
+type Small struct{}
+
+func (Small) size() {}
+
+type Large struct{}
+
+func (Large) size() {}
+
+//go-sumtype:decl Weight default=sometimes // want "invalid default clause policy 'sometimes' for sum type 'Weight'"
+
+type Weight interface{ weight() }
+
+func area(s Shape) {
+	switch s.(type) {
+	case Circle, Square:
+	default: // want "switch over sum type 'Shape' has a default clause, which is forbidden"
+		panic("unreachable")
+	}
+
+	// A forbidden default clause doesn't disable the exhaustiveness check.
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'Shape': missing cases for Square"
+	case Circle:
+	default: // want "switch over sum type 'Shape' has a default clause, which is forbidden"
+	}
+}
+
+func paint(c Color) {
+	switch c.(type) {
+	case Red, Blue:
+	default:
+		panic("unreachable")
+	}
+
+	switch c.(type) {
+	case Red, Blue:
+	default: // want "default clause of switch over sum type 'Color' must panic"
+	}
+
+	switch c.(type) { // want "exhaustiveness check failed for sum type 'Color': missing cases for Blue"
+	case Red:
+	default: // want "default clause of switch over sum type 'Color' must panic"
+		return
+	}
+}
+
+func measure(s Size) {
+	// Default clauses are allowed by default.
+	switch s.(type) {
+	case Small:
+	default:
+	}
+}
@@ -0,0 +1,40 @@
+package defaultpolicyflag
+
+//go-sumtype:decl Size
+
+type Size interface{ size() } // want Size:`sumtype\(Large, Small\)`
+
+type Small struct{}
+
+func (Small) size() {}
+
+type Large struct{}
+
+func (Large) size() {}
+
+//go-sumtype:decl Color default=allow
+
+type Color interface{ color() } // want Color:`sumtype\(Blue, Red\) default=allow`
+
+type Red struct{}
+
+func (Red) color() {}
+
+type Blue struct{}
+
+func (Blue) color() {}
+
+func measure(s Size) {
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'Size': missing cases for Large"
+	case Small:
+	default: // want "default clause of switch over sum type 'Size' must panic"
+	}
+}
+
+func paint(c Color) {
+	// The declaration of Color overrides the -default flag.
+	switch c.(type) {
+	case Red:
+	default:
+	}
+}
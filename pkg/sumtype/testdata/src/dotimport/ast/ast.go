@@ -0,0 +1,13 @@
+package ast
+
+//go-sumtype:decl Expr
+
+type Expr interface{ expr() }
+
+type Lit struct{}
+
+func (*Lit) expr() {}
+
+type Add struct{ X, Y Expr }
+
+func (*Add) expr() {}
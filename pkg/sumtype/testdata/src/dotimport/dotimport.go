@@ -0,0 +1,17 @@
+package dotimport
+
+import (
+	. "dotimport/ast"
+)
+
+func eval(e Expr) {
+	// TestDotImportMissing
+	switch e.(type) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Add"
+	case *Lit:
+	}
+
+	// TestDotImportNone
+	switch e.(type) {
+	case *Lit, *Add:
+	}
+}
@@ -0,0 +1,29 @@
+package embedding
+
+//go-sumtype:decl Expr
+
+type Expr interface{ expr() } // want Expr:`sumtype\(Lit, Named, Wrapped\)`
+
+type Lit struct{}
+
+func (*Lit) expr() {}
+
+// Wrapped adds a position to any expression, but also turns any value into
+// an Expr.
+type Wrapped struct {
+	Expr // want "variant 'Wrapped' of sum type 'Expr' embeds Expr, which unseals the sum type"
+	Pos  int
+}
+
+// Named embeds a variant rather than the sum type, so it is an ordinary
+// variant.
+type Named struct {
+	*Lit
+	Name string
+}
+
+func eval(e Expr) {
+	switch e.(type) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Named, Wrapped"
+	case *Lit:
+	}
+}
@@ -0,0 +1,21 @@
+package exclude
+
+//go-sumtype:decl Expr
+
+type Expr interface{ expr() } // want Expr:`sumtype\(Lit\)`
+
+type Lit struct{}
+
+func (*Lit) expr() {}
+
+type Wrapped struct {
+	Expr
+	Pos int
+}
+
+func eval(e Expr) {
+	// Wrapped is not a variant, so no case is required for it.
+	switch e.(type) {
+	case *Lit:
+	}
+}
@@ -0,0 +1,41 @@
+package enumalias
+
+import "enumalias/inner"
+
+// TestEnumAliasOfNamedType: an enum may be declared as an alias of a type
+// of another package, whose members are the constants declared here.
+
+//go-sumtype:enum Color
+
+type Color = inner.Color
+
+const (
+	Red   Color = 0
+	Green Color = 1
+	Blue  Color = 2
+)
+
+// TestEnumAliasOfBasicType: an enum may be declared as an alias of a basic
+// type.
+
+//go-sumtype:enum Local
+
+type Local = int
+
+const (
+	One   Local = 1
+	Two   Local = 2
+	Three Local = 3
+)
+
+func colors(c Color) {
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Blue"
+	case Red, Green:
+	}
+}
+
+func locals(l Local) {
+	switch l { // want "exhaustiveness check failed for enum 'Local': missing cases for Three"
+	case One, Two:
+	}
+}
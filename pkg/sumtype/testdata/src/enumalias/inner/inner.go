@@ -0,0 +1,3 @@
+package inner
+
+type Color int
@@ -0,0 +1,24 @@
+package enumdefault
+
+//go-sumtype:enum Color
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+)
+
+func enumDefault(c Color) {
+	// TestEnumRequireDefaultMissing
+	switch c { // want "switch over enum 'Color' has no default clause"
+	case Red, Green:
+	}
+
+	// TestEnumRequireDefaultPresent
+	switch c {
+	case Red, Green:
+	default:
+		panic("unreachable")
+	}
+}
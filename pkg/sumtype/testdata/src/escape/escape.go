@@ -0,0 +1,64 @@
+package escape
+
+//go-sumtype:decl Expr
+
+type Expr interface{ expr() } // want Expr:`sumtype\(Lit\)`
+
+type Lit struct{}
+
+func (*Lit) expr() {}
+
+var Last any
+
+var last any
+
+type Event struct {
+	Payload any
+	payload any
+}
+
+type parser struct{}
+
+func Parse(s string) any {
+	var e Expr = &Lit{}
+	return e // want "value of sum type 'Expr' escapes into any when returned from exported function 'Parse'"
+}
+
+func ParseBoth(s string) (Expr, any, error) {
+	var e Expr = &Lit{}
+	return e, e, nil // want "value of sum type 'Expr' escapes into any when returned from exported function 'ParseBoth'"
+}
+
+func (*Event) Value() interface{} {
+	var e Expr
+	return e // want "value of sum type 'Expr' escapes into any when returned from exported function 'Value'"
+}
+
+// The variant itself is not a sum type value.
+func Variant() any {
+	return &Lit{}
+}
+
+func parse(s string) any {
+	var e Expr
+	return e
+}
+
+func (parser) Parse(s string) any {
+	var e Expr
+	return e
+}
+
+func Record(e Expr, ev *Event) {
+	Last = e       // want "value of sum type 'Expr' escapes into any when assigned to exported variable 'Last'"
+	ev.Payload = e // want "value of sum type 'Expr' escapes into any when assigned to exported field 'Payload'"
+	last = e
+	ev.payload = e
+	var local any
+	local = e
+	_ = local
+	func() any {
+		Last = e // want "value of sum type 'Expr' escapes into any when assigned to exported variable 'Last'"
+		return e
+	}()
+}
@@ -0,0 +1,25 @@
+package a
+
+//go-sumtype:decl Expr
+
+type Expr interface{ a() }
+
+type Lit struct{}
+
+func (*Lit) a() {}
+
+type Neg struct{}
+
+func (*Neg) a() {}
+
+//go-sumtype:decl Stmt
+
+type Stmt interface{ stmt() }
+
+type Assign struct{}
+
+func (*Assign) stmt() {}
+
+type Return struct{}
+
+func (*Return) stmt() {}
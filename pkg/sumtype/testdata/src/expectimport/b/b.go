@@ -0,0 +1,13 @@
+package b
+
+//go-sumtype:decl Expr
+
+type Expr interface{ b() }
+
+type Call struct{}
+
+func (*Call) b() {}
+
+type Ident struct{}
+
+func (*Ident) b() {}
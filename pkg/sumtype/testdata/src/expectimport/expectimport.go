@@ -0,0 +1,47 @@
+package expectimport
+
+import (
+	"expectimport/a"
+	bb "expectimport/b"
+)
+
+func qualified(v any) {
+	// TestExpectQualified: names are qualified by the names of imports.
+	//go-sumtype:expect a.Expr
+	switch v.(type) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Neg"
+	case *a.Lit:
+	}
+
+	// TestExpectRenamedImport
+	//go-sumtype:expect bb.Expr
+	switch v.(type) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Ident"
+	case *bb.Call:
+	}
+
+	// TestExpectUnknownQualifier
+	//go-sumtype:expect b.Expr // want "expect directive names 'b.Expr', which is not a sum type"
+	switch v.(type) {
+	case *bb.Call:
+	}
+}
+
+func ambiguous(v any) {
+	// TestExpectAmbiguous: an unqualified name may name either sum type.
+	//go-sumtype:expect Expr // want "expect directive: 'Expr' is ambiguous, qualify it with its package as in a.Expr or b.Expr"
+	switch v.(type) {
+	case *a.Lit:
+	}
+}
+
+func param(v any) {
+	//go-sumtype:param v Expr // want "param directive: 'Expr' is ambiguous"
+}
+
+func unique(v any) {
+	// TestExpectUnqualifiedImport: an unqualified name may name the only
+	// sum type of an imported package with that name.
+	//go-sumtype:expect Stmt
+	switch v.(type) { // want "exhaustiveness check failed for sum type 'Stmt': missing cases for Return"
+	case *a.Assign:
+	}
+}
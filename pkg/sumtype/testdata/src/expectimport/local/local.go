@@ -0,0 +1,32 @@
+package local
+
+import "expectimport/a"
+
+//go-sumtype:decl Expr
+
+type Expr interface{ local() } // want Expr:"sumtype\\(X, Y\\)"
+
+type X struct{}
+
+func (*X) local() {}
+
+type Y struct{}
+
+func (*Y) local() {}
+
+func unqualified(v any) {
+	// TestExpectLocal: an unqualified name names the sum type of the
+	// package before those of imported packages.
+	//go-sumtype:expect Expr
+	switch v.(type) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Y"
+	case *X:
+	}
+
+	// TestExpectQualifiedShadowed: a qualified name names the sum type of
+	// the imported package even if this package declares one with the same
+	// name.
+	//go-sumtype:expect a.Expr
+	switch v.(type) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Neg"
+	case *a.Lit:
+	}
+}
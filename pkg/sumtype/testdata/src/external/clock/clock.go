@@ -0,0 +1,5 @@
+package clock
+
+import "time"
+
+func Today() time.Weekday { return time.Now().Weekday() }
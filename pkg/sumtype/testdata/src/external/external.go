@@ -0,0 +1,21 @@
+package external
+
+import (
+	"reflect"
+	"time"
+)
+
+func external(d time.Weekday, k reflect.Kind) {
+	// TestExternalEnumMissing
+	switch d { // want "exhaustiveness check failed for enum 'time.Weekday': missing cases for Saturday, Sunday"
+	case time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday:
+	}
+
+	// TestExternalEnumAllowlist: only allowed members are required
+	switch k { // want "exhaustiveness check failed for enum 'reflect.Kind': missing cases for String"
+	case reflect.Bool, reflect.Int:
+	}
+	switch k {
+	case reflect.Bool, reflect.Int, reflect.String:
+	}
+}
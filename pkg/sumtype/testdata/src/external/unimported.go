@@ -0,0 +1,11 @@
+package external
+
+import "external/clock"
+
+func unimported() {
+	// TestExternalEnumUnimported: the package of the enum is imported by
+	// the fix.
+	switch clock.Today() { // want "exhaustiveness check failed for enum 'time.Weekday': missing cases for Saturday, Sunday"
+	case 1, 2, 3, 4, 5:
+	}
+}
@@ -0,0 +1,26 @@
+package returnerr
+
+//go-sumtype:enum Color
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+func parse(c Color) (string, error) {
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Blue, Green"
+	case Red:
+		return "red", nil
+	}
+	return "", nil
+}
+
+func noError(c Color) int {
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Blue, Green"
+	case Red:
+	}
+	return 0
+}
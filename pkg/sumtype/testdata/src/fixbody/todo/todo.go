@@ -0,0 +1,19 @@
+package todo
+
+//go-sumtype:enum Color
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+func name(c Color) string {
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Blue, Green"
+	case Red:
+		return "red"
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package zero
+
+//go-sumtype:enum Color
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+)
+
+type Point struct{ X, Y int }
+
+func describe(c Color) (string, int, bool, *Point, Point, Color, error) {
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Green"
+	case Red:
+		return "red", 1, true, nil, Point{}, c, nil
+	}
+	return "", 0, false, nil, Point{}, c, nil
+}
+
+func visit(c Color) {
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Green"
+	case Red:
+	}
+}
+
+func generic[T any](c Color, v T) T {
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Green"
+	case Red:
+	}
+	return v
+}
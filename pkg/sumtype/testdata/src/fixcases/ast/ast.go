@@ -0,0 +1,17 @@
+package ast
+
+//go-sumtype:decl Expr
+
+type Expr interface{ expr() }
+
+type Lit struct{}
+
+func (Lit) expr() {}
+
+type Add struct{}
+
+func (*Add) expr() {}
+
+type Neg struct{}
+
+func (Neg) expr() {}
@@ -0,0 +1,10 @@
+package draw
+
+import (
+	"fixcases/shapes/v2"
+	"gopkg.in/figures.v1"
+)
+
+func Shape() shapes.Shape { return shapes.Circle{} }
+
+func Figure() figures.Figure { return &figures.Line{} }
@@ -0,0 +1,20 @@
+package fixcases
+
+import "fixcases/ast"
+
+func values(e ast.Expr) {
+	// Variants with value receivers are matched by value, and the others by
+	// pointer.
+	switch e.(type) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Add, Neg"
+	case ast.Lit:
+	}
+}
+
+func pointers(e ast.Expr) {
+	// Variants are matched by pointer when the switch already does.
+	switch e.(type) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Lit, Neg"
+	case *ast.Add:
+	default:
+		panic("unreachable")
+	}
+}
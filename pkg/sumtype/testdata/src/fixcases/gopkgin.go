@@ -0,0 +1,10 @@
+package fixcases
+
+import "fixcases/draw"
+
+func gopkgin() {
+	// The package is imported by the fix under its own name, which differs
+	// from the last element of its path.
+	switch draw.Figure().(type) { // want "exhaustiveness check failed for sum type 'Figure': missing cases for Line, Point"
+	}
+}
@@ -0,0 +1,5 @@
+package parse
+
+import "fixcases/ast"
+
+func Parse() ast.Expr { return ast.Lit{} }
@@ -0,0 +1,13 @@
+package shapes
+
+//go-sumtype:decl Shape
+
+type Shape interface{ shape() }
+
+type Circle struct{}
+
+func (Circle) shape() {}
+
+type Square struct{}
+
+func (*Square) shape() {}
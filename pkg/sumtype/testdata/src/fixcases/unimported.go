@@ -0,0 +1,9 @@
+package fixcases
+
+import "fixcases/parse"
+
+func unimported() {
+	// The package of the variants is imported by the fix.
+	switch parse.Parse().(type) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Add, Lit, Neg"
+	}
+}
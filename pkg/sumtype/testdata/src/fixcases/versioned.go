@@ -0,0 +1,11 @@
+package fixcases
+
+import "fixcases/shapes/v2"
+
+func versioned(s shapes.Shape) {
+	// The package is qualified by its name, not the last element of its
+	// path.
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'Shape': missing cases for Square"
+	case shapes.Circle:
+	}
+}
@@ -0,0 +1,25 @@
+package generic
+
+//go-sumtype:decl Value
+
+type Value interface{ value() } // want Value:`sumtype\(Box, Null\)`
+
+type Box[T any] struct{ V T }
+
+func (*Box[T]) value() {}
+
+type Null struct{}
+
+func (*Null) value() {}
+
+func show(v Value) {
+	// TestGenericNone
+	switch v.(type) {
+	case *Box[int], *Null:
+	}
+
+	// TestGenericMissing
+	switch v.(type) { // want "exhaustiveness check failed for sum type 'Value': missing cases for Box"
+	case *Null:
+	}
+}
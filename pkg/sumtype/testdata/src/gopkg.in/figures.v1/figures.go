@@ -0,0 +1,13 @@
+package figures
+
+//go-sumtype:decl Figure
+
+type Figure interface{ figure() }
+
+type Line struct{}
+
+func (*Line) figure() {}
+
+type Point struct{}
+
+func (*Point) figure() {}
@@ -0,0 +1,18 @@
+package grouped
+
+//go-sumtype:enum Color
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+func grouped(c Color) {
+	// TestEnumGroupedFix
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Blue, Green"
+	case Red:
+	}
+}
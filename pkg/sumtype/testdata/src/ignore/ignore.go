@@ -0,0 +1,33 @@
+package ignore
+
+//go-sumtype:decl Shape
+
+type Shape interface{ shape() } // want Shape:`sumtype\(Circle, Square\)`
+
+type Circle struct{}
+
+func (*Circle) shape() {}
+
+type Square struct{}
+
+func (*Square) shape() {}
+
+type ShapeMock struct{}
+
+func (*ShapeMock) shape() {}
+
+type ShapeStub struct{}
+
+func (*ShapeStub) shape() {}
+
+func area(s Shape) {
+	// TestIgnoreVariantsMissing
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'Shape': missing cases for Square"
+	case *Circle:
+	}
+
+	// TestIgnoreVariantsNone
+	switch s.(type) {
+	case *Circle, *Square:
+	}
+}
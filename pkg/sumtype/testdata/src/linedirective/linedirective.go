@@ -0,0 +1,21 @@
+package linedirective
+
+//go-sumtype:enum Color
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+)
+
+// name was generated from a template, and its positions are mapped back to
+// the template by the line directive.
+func name(c Color) string {
+//line color.tmpl:3
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Green"
+	case Red:
+		return "red"
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package matcher
+
+//go-sumtype:decl Expr
+
+type Expr interface{ expr() } // want Expr:`sumtype\(Add, Lit, Neg\)`
+
+type Lit struct{}
+
+func (*Lit) expr() {}
+
+type Add struct{ X, Y Expr }
+
+func (*Add) expr() {}
+
+type Neg struct{ X Expr }
+
+func (*Neg) expr() {}
+
+type Eval struct { // want "matcher 'Eval' for sum type 'Expr' is missing fields for Neg"
+	//go-sumtype:matcher Expr
+	OnLit   func(*Lit) int
+	OnAdd   func(*Add) int
+	OnOther func(int) int // want "field 'OnOther' of matcher 'Eval' does not handle a variant of sum type 'Expr'"
+	Default func(Expr) int
+	Name    string
+}
+
+type Printer struct {
+	//go-sumtype:matcher Expr
+	OnLit func(Lit) string
+	OnAdd func(*Add) string
+	OnNeg func(*Neg) string
+}
+
+type NotStruct interface {
+	//go-sumtype:matcher Expr // want "matcher 'NotStruct' is not a struct"
+	Match(Expr)
+}
+
+type Broken struct {
+	//go-sumtype:matcher Stmt // want "matcher directive names 'Stmt', which is not a sum type"
+	OnLit func(*Lit)
+}
+
+func use() {
+	_ = Printer{
+		OnLit: func(Lit) string { return "" },
+		OnAdd: func(*Add) string { return "" },
+		OnNeg: func(*Neg) string { return "" },
+	}
+	_ = &Printer{ // want "matcher 'Printer' leaves OnAdd, OnNeg nil"
+		OnLit: func(Lit) string { return "" },
+	}
+	_ = Printer{ // want "matcher 'Printer' leaves OnNeg nil"
+		func(Lit) string { return "" },
+		func(*Add) string { return "" },
+		nil,
+	}
+	_ = Printer{}                                // want "matcher 'Printer' leaves OnAdd, OnLit, OnNeg nil"
+	_ = Eval{OnLit: func(*Lit) int { return 0 }} // want "matcher 'Eval' leaves OnAdd nil"
+}
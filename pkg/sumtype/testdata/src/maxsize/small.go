@@ -0,0 +1,19 @@
+package maxsize
+
+//go-sumtype:decl T
+
+type T interface{ sealed() } // want T:`sumtype\(A, B\)`
+
+type A struct{}
+
+func (*A) sealed() {}
+
+type B struct{}
+
+func (*B) sealed() {}
+
+func checked(t T) {
+	switch t.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for B"
+	case *A:
+	}
+}
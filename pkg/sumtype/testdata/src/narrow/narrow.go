@@ -0,0 +1,60 @@
+package narrow
+
+//go-sumtype:decl Node
+
+type Node interface { // want Node:`sumtype\(Call, Ident, Leaf, TerminalNode\)`
+	node()
+}
+
+// TerminalNode is a variant of Node whose own variants are the nodes without
+// children.
+type TerminalNode interface {
+	Node
+	terminal()
+}
+
+type Call struct{}
+
+func (*Call) node() {}
+
+type Ident struct{}
+
+func (*Ident) node()     {}
+func (*Ident) terminal() {}
+
+type Leaf struct{}
+
+func (*Leaf) node()     {}
+func (*Leaf) terminal() {}
+
+func nested(n Node) {
+	switch n := n.(type) {
+	case TerminalNode:
+		switch n.(type) { // want "exhaustiveness check failed for sum type 'TerminalNode': missing cases for Leaf"
+		case *Ident:
+		}
+	case *Call:
+	}
+}
+
+func nestedExhaustive(n Node) {
+	switch n := n.(type) {
+	case TerminalNode:
+		switch n.(type) {
+		case *Ident, *Leaf:
+		}
+	case *Call:
+	}
+}
+
+func narrowed(t TerminalNode) {
+	switch t.(type) { // want "exhaustiveness check failed for sum type 'TerminalNode': missing cases for Ident$"
+	case *Leaf:
+	}
+}
+
+func unnarrowed(n Node) {
+	switch n.(type) { // want "exhaustiveness check failed for sum type 'Node': missing cases for Ident$"
+	case *Call, *Leaf:
+	}
+}
@@ -0,0 +1,66 @@
+package nilcase
+
+//go-sumtype:decl Shape nil
+
+type Shape interface{ shape() } // want Shape:`sumtype\(Circle, Square\) nil`
+
+type Circle struct{}
+
+func (*Circle) shape() {}
+
+type Square struct{}
+
+func (*Square) shape() {}
+
+//go-sumtype:decl Color
+
+type Color interface{ color() } // want Color:`sumtype\(Blue, Red\)`
+
+type Red struct{}
+
+func (Red) color() {}
+
+type Blue struct{}
+
+func (Blue) color() {}
+
+//go-sumtype:decl Size big // want "unknown option 'big' for sum type 'Size'"
+
+type Size interface{ size() }
+
+func area(s Shape) {
+	switch s.(type) { // want "switch over sum type 'Shape' has no case for nil"
+	case *Circle, *Square:
+	}
+
+	switch s.(type) {
+	case nil:
+	case *Circle, *Square:
+	}
+
+	// A default clause doesn't handle nil explicitly.
+	switch s.(type) { // want "switch over sum type 'Shape' has no case for nil"
+	case *Circle, *Square:
+	default:
+		panic("unreachable")
+	}
+
+	// A case for nil doesn't count as a case for a variant.
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'Shape': missing cases for Square"
+	case nil, *Circle:
+	}
+}
+
+func paint(c Color) {
+	// The -require-nil flag is not set by TestNilCase.
+	switch c.(type) {
+	case Red, Blue:
+	}
+
+	switch c.(type) {
+	case nil:
+	case Red, Blue:
+	default:
+		panic("unreachable")
+	}
+}
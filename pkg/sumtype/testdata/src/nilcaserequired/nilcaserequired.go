@@ -0,0 +1,34 @@
+package nilcaserequired
+
+//go-sumtype:decl Color
+
+type Color interface{ color() } // want Color:`sumtype\(Blue, Red\)`
+
+type Red struct{}
+
+func (Red) color() {}
+
+type Blue struct{}
+
+func (Blue) color() {}
+
+//go-sumtype:decl Number
+
+type Number interface{ int | float64 } // want Number:`sumtype\(float64, int\)`
+
+func paint(c Color) {
+	switch c.(type) { // want "switch over sum type 'Color' has no case for nil"
+	case Red, Blue:
+	}
+
+	switch c.(type) {
+	case nil, Red, Blue:
+	}
+}
+
+// A value of a type parameter constrained by Number can't be nil.
+func double[N Number](n N) {
+	switch any(n).(type) {
+	case int, float64:
+	}
+}
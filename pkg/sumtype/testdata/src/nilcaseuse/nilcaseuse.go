@@ -0,0 +1,10 @@
+package nilcaseuse
+
+import "nilcase"
+
+// The nil option is honored in packages importing the sum type.
+func area(s nilcase.Shape) {
+	switch s.(type) { // want "switch over sum type 'Shape' has no case for nil"
+	case *nilcase.Circle, *nilcase.Square:
+	}
+}
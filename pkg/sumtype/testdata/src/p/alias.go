@@ -0,0 +1,13 @@
+package main
+
+type AliasA = A
+
+type AliasPtrB = *B
+
+func alias(t T) {
+	// TestAliasCases
+	switch t.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for C"
+	case *AliasA:
+	case AliasPtrB:
+	}
+}
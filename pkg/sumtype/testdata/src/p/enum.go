@@ -0,0 +1,99 @@
+package main
+
+//go-sumtype:enum Color
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+//go-sumtype:enum Perm bitflag
+
+type Perm uint
+
+const (
+	Read Perm = 1 << iota
+	Write
+	Exec
+
+	ReadWrite = Read | Write
+)
+
+func enums(c Color, p Perm) {
+	// TestEnumMissingNone
+	switch c {
+	case Red, Green, Blue:
+	}
+
+	// TestEnumMissingOne
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Blue"
+	case Red:
+	case Green:
+	}
+
+	// TestEnumMissingOneWithPanic
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Green"
+	case Red, Blue:
+	default:
+		panic("unreachable")
+	}
+
+	// TestEnumNoMissingDefault
+	switch c {
+	case Red:
+	default:
+	}
+
+	// TestEnumBitflagSwitch: switches over bitflag enums are not checked
+	switch p {
+	case Read:
+	}
+
+	// TestEnumBitflagDispatchTable: combinations are not required
+	_ = map[Perm]string{ // want "dispatch table for bitflag enum 'Perm' is missing entries for Exec"
+		Read:      "r",
+		Write:     "w",
+		ReadWrite: "rw",
+	}
+	_ = map[Perm]string{
+		Read:  "r",
+		Write: "w",
+		Exec:  "x",
+	}
+
+	// TestEnumBitflagTests: combinations are not required
+	switch { // want "exhaustiveness check failed for bitflag enum 'Perm': missing tests for Exec"
+	case p&Read != 0:
+	case Write&p == Write, p&ReadWrite == ReadWrite:
+	}
+	switch {
+	case p&Read != 0:
+	case p&Write > 0:
+	case p&Exec == Exec:
+	}
+
+	// TestEnumBitflagTestsDefault
+	switch {
+	case p&Read != 0:
+	case p&Write != 0:
+	default:
+	}
+
+	// TestEnumBitflagTestsOther: tests of different values or of other
+	// conditions are not checked
+	var q Perm
+	switch {
+	case p&Read != 0:
+	case q&Write != 0:
+	}
+	switch {
+	case p&Read != 0:
+	case p == Write:
+	}
+	switch {
+	case p&Read != 0:
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+func expect(v any) {
+	// TestExpectMissing
+	//go-sumtype:expect T
+	switch v.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for C"
+	case *A, *B:
+	}
+
+	// TestExpectNone
+	//go-sumtype:expect T
+	switch v.(type) {
+	case *A, *B, *C:
+	}
+
+	// TestExpectNotSumType
+	//go-sumtype:expect U // want "expect directive names 'U', which is not a sum type"
+	switch v.(type) {
+	case *A:
+	}
+
+	// TestNoExpect
+	switch v.(type) {
+	case *A:
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+//go-sumtype:enum Level
+
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+
+	NumLevels
+)
+
+// TestLookupTableNone
+var levelNames = [NumLevels]string{"debug", "info", "warn", "error"}
+
+// TestLookupTablePositional
+var levelShort = [NumLevels]string{"D", "I", "W"} // want "lookup table indexed by enum 'Level' is missing entries for Error"
+
+// TestLookupTableKeyed
+var levelColors = [...]string{ // want "lookup table indexed by enum 'Level' is missing entries for Info, Warn"
+	Debug: "grey",
+	Error: "red",
+}
+
+// TestLookupTableUntyped: arrays not indexed by an enum are not checked
+var notLevels = [4]string{"a"}
+
+// TestLookupTableSentinelSwitch: NumLevels is the length of levelNames, so it
+// is not a member that switches must cover.
+func levelName(l Level) string {
+	switch l {
+	case Debug, Info, Warn, Error:
+		return levelNames[l]
+	}
+	return ""
+}
+
+// TestLookupTableSentinelMissing
+func levelShortName(l Level) string {
+	switch l { // want "exhaustiveness check failed for enum 'Level': missing cases for Error"
+	case Debug, Info, Warn:
+		return levelShort[l]
+	}
+	return ""
+}
@@ -4,7 +4,7 @@ import "fmt"
 
 //go-sumtype:decl T
 
-type T interface{ sealed() }
+type T interface{ sealed() } // want T:`sumtype\(A, B, C\)`
 
 type A struct{}
 
@@ -0,0 +1,6 @@
+package main
+
+//go-sumtype:enum NotEnumT // want "type 'NotEnumT' is not an enum"
+
+// TestNotEnum
+type NotEnumT struct{}
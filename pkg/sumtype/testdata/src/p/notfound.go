@@ -1,4 +1,4 @@
-package main // want "type 'NotFoundT' is not defined"
+package main
 
 // TestNotFound
-//go-sumtype:decl NotFoundT
+//go-sumtype:decl NotFoundT // want "type 'NotFoundT' is not defined"
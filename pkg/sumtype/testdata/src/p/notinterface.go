@@ -1,6 +1,6 @@
 package main
 
-//go-sumtype:decl NotInterfaceT
+//go-sumtype:decl NotInterfaceT // want "type 'NotInterfaceT' is not an interface"
 
 // TestNotInterface
-type NotInterfaceT struct{} // want "type 'NotInterfaceT' is not an interface"
+type NotInterfaceT struct{}
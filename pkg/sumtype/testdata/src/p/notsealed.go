@@ -1,6 +1,6 @@
 package main
 
-//go-sumtype:decl NotSealedT
+//go-sumtype:decl NotSealedT // want "interface 'NotSealedT' is not sealed"
 
 // TestNotSealed
-type NotSealedT interface{} // want "interface 'NotSealedT' is not sealed"
+type NotSealedT interface{}
@@ -0,0 +1,29 @@
+package main
+
+// TestParam
+func param(v, w any) {
+	//go-sumtype:param v T
+	switch v.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for C"
+	case *A, *B:
+	}
+
+	switch x := v.(type) {
+	case *A, *B, *C:
+		_ = x
+	}
+
+	// Only v is bound to T.
+	switch w.(type) {
+	case *A:
+	}
+}
+
+// TestParamNotParam
+func paramNotParam(v any) {
+	//go-sumtype:param u T // want "param directive names 'u', which is not a parameter of 'paramNotParam'"
+}
+
+// TestParamNotSumType
+func paramNotSumType(v any) {
+	//go-sumtype:param v U // want "param directive names 'U', which is not a sum type"
+}
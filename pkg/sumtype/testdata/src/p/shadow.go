@@ -0,0 +1,13 @@
+package main
+
+func shadow(t T) {
+	// TestShadowedVariant
+	//
+	// The local A implements T through its embedded *C, but is not the
+	// variant A.
+	type A struct{ *C }
+	switch t.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for A"
+	case *B, *C:
+	case *A:
+	}
+}
@@ -0,0 +1,9 @@
+package api
+
+import "reexport/internal/ast"
+
+type (
+	Expr = ast.Expr
+	Lit  = ast.Lit
+	Add  = ast.Add
+)
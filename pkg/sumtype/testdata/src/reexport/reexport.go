@@ -0,0 +1,15 @@
+package reexport
+
+import "reexport/api"
+
+func eval(e api.Expr) {
+	// TestReexportMissing
+	switch e.(type) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Add"
+	case *api.Lit:
+	}
+
+	// TestReexportNone
+	switch e.(type) {
+	case *api.Lit, *api.Add:
+	}
+}
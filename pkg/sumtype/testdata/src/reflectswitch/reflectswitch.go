@@ -0,0 +1,51 @@
+package reflectswitch
+
+import "reflect"
+
+//go-sumtype:decl Expr
+
+type Expr interface{ expr() } // want Expr:`sumtype\(Add, Lit, Neg\)`
+
+type Lit struct{}
+
+func (*Lit) expr() {}
+
+type Add struct{}
+
+func (*Add) expr() {}
+
+type Neg struct{}
+
+func (*Neg) expr() {}
+
+func eval(e Expr) {
+	// TestReflectSwitchMissing
+	switch reflect.TypeOf(e) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Neg"
+	case reflect.TypeOf(&Lit{}):
+	case reflect.TypeFor[*Add]():
+	}
+
+	// TestReflectSwitchPanic
+	switch reflect.TypeOf(e) { // want "exhaustiveness check failed for sum type 'Expr': missing cases for Add, Neg"
+	case reflect.TypeOf(&Lit{}):
+	default:
+		panic("unreachable")
+	}
+
+	// TestReflectSwitchNone
+	switch reflect.TypeOf(e) {
+	case reflect.TypeOf(&Lit{}), reflect.TypeOf(&Add{}), reflect.TypeOf(&Neg{}):
+	}
+
+	// TestReflectSwitchDefault
+	switch reflect.TypeOf(e) {
+	case reflect.TypeOf(&Lit{}):
+	default:
+	}
+
+	// TestReflectSwitchOther
+	var x any = e
+	switch reflect.TypeOf(x) {
+	case reflect.TypeOf(&Lit{}):
+	}
+}
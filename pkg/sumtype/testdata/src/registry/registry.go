@@ -0,0 +1,56 @@
+package registry
+
+import "reflect"
+
+//go-sumtype:decl Expr
+
+type Expr interface{ sealed() } // want Expr:`sumtype\(Add, Lit, Neg\)`
+
+type Lit struct{}
+
+func (*Lit) sealed() {}
+
+type Add struct{}
+
+func (*Add) sealed() {}
+
+type Neg struct{}
+
+func (Neg) sealed() {}
+
+type Handler func(Expr) int
+
+// TestRegistryMap
+var handlers = map[reflect.Type]Handler{ // want "registry for sum type 'Expr' is missing registrations for Neg"
+	//go-sumtype:registry Expr
+	reflect.TypeOf(&Lit{}):  nil,
+	reflect.TypeFor[*Add](): nil,
+}
+
+// TestRegistryMapComplete
+var complete = map[reflect.Type]Handler{
+	//go-sumtype:registry Expr
+	reflect.TypeOf(&Lit{}):  nil,
+	reflect.TypeFor[*Add](): nil,
+	reflect.TypeOf(Neg{}):   nil,
+}
+
+// TestRegistryFunc
+func Register(e Expr, h Handler) { // want "registry for sum type 'Expr' is missing registrations for Add"
+	//go-sumtype:registry Expr
+}
+
+func init() {
+	Register(&Lit{}, nil)
+	Register(Neg{}, nil)
+}
+
+// TestRegistryNotSumType
+var notSumType = map[string]Handler{
+	//go-sumtype:registry Handler // want "registry directive names 'Handler', which is not a sum type"
+}
+
+// TestRegistryNotMap
+var notMap Handler = func(Expr) int { //go-sumtype:registry Expr // want "registry variable must be initialized with a map literal"
+	return 0
+}
@@ -0,0 +1,44 @@
+package require
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+//go-sumtype:decl Expr
+//go-sumtype:require Expr fmt.Stringer json.Marshaler
+
+type Expr interface{ expr() } // want Expr:`sumtype\(Add, Lit, Neg\)`
+
+type Lit int
+
+func (Lit) expr()                        {}
+func (Lit) String() string               { return "lit" }
+func (Lit) MarshalJSON() ([]byte, error) { return nil, nil }
+
+type Add struct{ X, Y Expr } // want "variant 'Add' of sum type 'Expr' does not implement json.Marshaler \\(missing method MarshalJSON\\)"
+
+func (*Add) expr()          {}
+func (*Add) String() string { return "add" }
+
+type Neg struct{ X Expr } // want "variant 'Neg' of sum type 'Expr' does not implement fmt.Stringer \\(missing method String\\)" "variant 'Neg' of sum type 'Expr' does not implement json.Marshaler"
+
+func (*Neg) expr() {}
+
+//go-sumtype:decl Stmt
+//go-sumtype:require Stmt error // the pointer implements it
+
+type Stmt interface{ stmt() } // want Stmt:`sumtype\(Fail\)`
+
+type Fail struct{} // want "variant 'Fail' of sum type 'Stmt' does not implement fmt.Formatter \\(missing method Format\\)"
+
+func (*Fail) stmt()         {}
+func (*Fail) Error() string { return "fail" }
+
+//go-sumtype:require Stmt fmt.Formatter Missing Lit // want "type 'Missing' is not defined" "type 'Lit' is not an interface"
+//go-sumtype:require Unknown error // want "require directive names 'Unknown', which is not a sum type declared in this package"
+
+func dump(e Expr) string {
+	data, _ := json.Marshal(e)
+	return fmt.Sprintf("%v: %s", e, data)
+}
@@ -0,0 +1,37 @@
+package scope
+
+//go-sumtype:decl Shape
+//go-sumtype:decl state
+
+type Shape interface{ shape() } // want Shape:`sumtype\(Circle, Square\)`
+
+type Circle struct{}
+
+func (Circle) shape() {}
+
+type Square struct{}
+
+func (Square) shape() {}
+
+type state interface{ state() } // want state:`sumtype\(idle, running\)`
+
+type idle struct{}
+
+func (idle) state() {}
+
+type running struct{}
+
+func (running) state() {}
+
+func area(s Shape) {
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'Shape': missing cases for Square"
+	case Circle:
+	}
+}
+
+func step(s state) {
+	// The switch is not checked, since state is not exported.
+	switch s.(type) {
+	case idle:
+	}
+}
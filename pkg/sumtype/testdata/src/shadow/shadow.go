@@ -0,0 +1,52 @@
+package shadow
+
+//go-sumtype:decl T
+
+type T interface{ sealed() } // want T:`sumtype\(A, B\)`
+
+type A struct{}
+
+func (*A) sealed() {}
+
+type B struct{}
+
+func (*B) sealed() {}
+
+func shadowedCase(t T) {
+	// The local B is not the variant B, so it doesn't cover it.
+	type B struct{ *A }
+	switch t.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for B"
+	case *A, *B:
+	}
+}
+
+func shadowedSubject() {
+	// The switched value's static type decides the sum type, not its name.
+	T := any(&A{})
+	switch T.(type) {
+	case *A:
+	}
+}
+
+func field(s struct{ t T }) {
+	switch s.t.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for B"
+	case *A:
+	}
+}
+
+func call(f func() T) {
+	switch f().(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for A"
+	case *B:
+	}
+}
+
+type node struct{ child T }
+
+func (n node) Child() T { return n.child }
+
+func method(n node) {
+	switch c := n.Child().(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for B"
+	case *A:
+		_ = c
+	}
+}
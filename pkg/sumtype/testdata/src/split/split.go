@@ -0,0 +1,41 @@
+package split
+
+//go-sumtype:decl Shape
+
+type Shape interface{ shape() } // want Shape:`sumtype\(Circle, Square, Triangle\)`
+
+type Circle struct{}
+
+func (*Circle) shape() {}
+
+type Square struct{}
+
+func (*Square) shape() {}
+
+type Triangle struct{}
+
+func (*Triangle) shape() {}
+
+//go-sumtype:enum Color
+
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+func area(s Shape) {
+	// TestSplitMissing
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'Shape': missing cases for Square$" "exhaustiveness check failed for sum type 'Shape': missing cases for Triangle$"
+	case *Circle:
+	}
+}
+
+func name(c Color) {
+	// TestSplitMissingEnum
+	switch c { // want "exhaustiveness check failed for enum 'Color': missing cases for Green$" "exhaustiveness check failed for enum 'Color': missing cases for Blue$"
+	case Red:
+	}
+}
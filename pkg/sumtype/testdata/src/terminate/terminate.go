@@ -0,0 +1,87 @@
+package terminate
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"testing"
+)
+
+//go-sumtype:decl T
+
+type T interface{ sealed() } // want T:`sumtype\(A, B\)`
+
+type A struct{}
+
+func (A) sealed() {}
+
+type B struct{}
+
+func (B) sealed() {}
+
+// fake has a method named like a terminator that returns normally.
+type fake struct{}
+
+func (fake) Fatal(args ...interface{}) {}
+
+func defaults(x T, logger *log.Logger, t *testing.T, tb testing.TB, ok bool) {
+	switch x.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for B"
+	case A:
+	default:
+		log.Fatalf("unexpected %T", x)
+	}
+
+	switch x.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for B"
+	case A:
+	default:
+		logger.Panicln(x)
+	}
+
+	switch x.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for B"
+	case A:
+	default:
+		fmt.Fprintln(os.Stderr, "unexpected", x)
+		os.Exit(1)
+	}
+
+	switch x.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for B"
+	case A:
+	default:
+		runtime.Goexit()
+	}
+
+	switch x.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for B"
+	case A:
+	default:
+		t.Fatalf("unexpected %T", x)
+	}
+
+	switch x.(type) { // want "exhaustiveness check failed for sum type 'T': missing cases for B"
+	case A:
+	default:
+		tb.FailNow()
+	}
+
+	// Defaults that may complete normally still disable the check.
+	switch x.(type) {
+	case A:
+	default:
+		log.Printf("unexpected %T", x)
+	}
+
+	switch x.(type) {
+	case A:
+	default:
+		if ok {
+			return
+		}
+		os.Exit(1)
+	}
+
+	switch x.(type) {
+	case A:
+	default:
+		fake{}.Fatal(x)
+	}
+}
@@ -0,0 +1,26 @@
+package tracestd
+
+import "go/ast"
+
+type Shape interface{ shape() } // want Shape:`sumtype\(Circle, Square\)`
+
+type Circle struct{}
+
+func (Circle) shape() {}
+
+type Square struct{}
+
+func (Square) shape() {}
+
+func area(s Shape) {
+	switch s.(type) {
+	case Circle, Square:
+	}
+}
+
+func name(e ast.Expr) string {
+	if id, ok := e.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package unhandled
+
+//go-sumtype:decl Shape
+
+type Shape interface{ shape() } // want Shape:`sumtype\(Circle, Square, Triangle\)`
+
+type Circle struct{}
+
+func (*Circle) shape() {}
+
+type Square struct{} // want "variant 'Square' of sum type 'Shape' is not handled by switch at unhandled.go:25"
+
+func (*Square) shape() {}
+
+type Triangle struct{} // want "variant 'Triangle' of sum type 'Shape' is not handled by switch at unhandled.go:25" "variant 'Triangle' of sum type 'Shape' is not handled by switch at unhandled.go:30"
+
+func (*Triangle) shape() {}
+
+func area(s Shape) {
+	// TestUnhandledNone
+	switch s.(type) {
+	case *Circle, *Square, *Triangle:
+	}
+
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'Shape': missing cases for Square, Triangle"
+	case *Circle:
+	}
+
+	// TestUnhandledDefault
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'Shape': missing cases for Triangle"
+	case *Circle, *Square:
+	default:
+		panic("unreachable")
+	}
+}
@@ -0,0 +1,57 @@
+package union
+
+//go-sumtype:decl Shape
+
+type Shape interface{ Circle | Square | *Triangle } // want Shape:`sumtype\(Circle, Square, Triangle\)`
+
+type Circle struct{}
+
+func (Circle) round() {}
+
+type Square struct{}
+
+type Triangle struct{}
+
+func area[T Shape](s T) {
+	switch any(s).(type) { // want "exhaustiveness check failed for sum type 'Shape': missing cases for Triangle"
+	case Circle, Square:
+	}
+
+	switch s := any(s).(type) {
+	case Circle, Square, *Triangle:
+		_ = s
+	}
+}
+
+//go-sumtype:decl Number
+
+type Number interface{ int | int64 | float64 } // want Number:`sumtype\(float64, int, int64\)`
+
+func double[N Number](n N) {
+	switch any(n).(type) { // want "exhaustiveness check failed for sum type 'Number': missing cases for float64, int64"
+	case int:
+	}
+}
+
+// The methods of a constraint exclude the terms that lack them.
+//
+//go-sumtype:decl Round
+
+type Round interface { // want Round:`sumtype\(Circle\)`
+	Circle | Square
+	round()
+}
+
+func roll[T Round](r T) {
+	switch any(r).(type) {
+	case Circle:
+	}
+}
+
+//go-sumtype:decl Approx // want "constraint 'Approx' is not a sum type: term '~int' admits every type whose underlying type is int"
+
+type Approx interface{ ~int | string }
+
+//go-sumtype:decl Slices // want `constraint 'Slices' is not a sum type: term '\[\]int' is not a named type`
+
+type Slices interface{ []int | []string }
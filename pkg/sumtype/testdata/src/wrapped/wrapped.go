@@ -0,0 +1,81 @@
+package wrapped
+
+import (
+	"errors"
+	"fmt"
+)
+
+//go-sumtype:decl Error
+
+type Error interface { // want Error:`sumtype\(NotFound, Timeout\)`
+	error
+	sealed()
+}
+
+type NotFound struct{}
+
+func (NotFound) Error() string { return "not found" }
+func (NotFound) sealed()       {}
+
+type Timeout struct{}
+
+func (Timeout) Error() string { return "timeout" }
+func (Timeout) sealed()       {}
+
+func find() error { return NotFound{} }
+
+func load() (int, error) {
+	if err := find(); err != nil {
+		return 0, fmt.Errorf("loading: %w", err)
+	}
+	return 1, nil
+}
+
+func reload() (int, error) {
+	return load()
+}
+
+func direct() {
+	err := fmt.Errorf("context: %w", find())
+	//go-sumtype:expect Error
+	switch err.(type) { // want "type switch over error sum type 'Error' misses variants wrapped by fmt.Errorf with %w; use errors.As for each variant instead"
+	case NotFound, Timeout:
+	}
+}
+
+func joined() {
+	var err = errors.Join(find(), Timeout{})
+	//go-sumtype:expect Error
+	switch err.(type) { // want "misses variants wrapped by errors.Join"
+	case NotFound, Timeout:
+	}
+}
+
+func through() {
+	_, err := reload()
+	//go-sumtype:expect Error
+	switch err.(type) { // want "misses variants wrapped by fmt.Errorf with %w in 'load'"
+	case NotFound, Timeout:
+	}
+}
+
+func unwrapped() {
+	err := find()
+	//go-sumtype:expect Error
+	switch err.(type) {
+	case NotFound, Timeout:
+	}
+
+	// Formatting without %w does not wrap.
+	err = fmt.Errorf("context: %v", find())
+	//go-sumtype:expect Error
+	switch err.(type) {
+	case NotFound, Timeout:
+	}
+}
+
+func typed(e Error) {
+	switch e.(type) {
+	case NotFound, Timeout:
+	}
+}
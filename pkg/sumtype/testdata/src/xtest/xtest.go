@@ -0,0 +1,13 @@
+package xtest
+
+//go-sumtype:decl Shape
+
+type Shape interface{ shape() } // want Shape:`sumtype\(Circle, Square\)`
+
+type Circle struct{}
+
+func (*Circle) shape() {}
+
+type Square struct{}
+
+func (*Square) shape() {}
@@ -0,0 +1,15 @@
+package xtest_test
+
+import "xtest"
+
+func area(s xtest.Shape) {
+	// TestExternalTestMissing
+	switch s.(type) { // want "exhaustiveness check failed for sum type 'Shape': missing cases for Square"
+	case *xtest.Circle:
+	}
+
+	// TestExternalTestNone
+	switch s.(type) {
+	case *xtest.Circle, *xtest.Square:
+	}
+}
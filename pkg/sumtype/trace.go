@@ -0,0 +1,99 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/token"
+	"go/types"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// trace is set with the -trace flag. When true, the decisions made while
+// checking each type switch, which are always recorded in the Result, are
+// logged as well, which helps explaining why a switch was or wasn't reported.
+var trace bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&trace, "trace", false,
+		"log how each type switch is matched to a sum type and checked")
+}
+
+// tracef records a message about the node at pos in res if the package of the
+// pass is traced, and logs it when the -trace flag is given.
+func tracef(pass *analysis.Pass, res *Result, pos token.Pos, format string, args ...interface{}) {
+	if !traced(pass) {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	res.Trace = append(res.Trace, &TraceEntry{Pos: pos, Message: msg})
+	if trace {
+		log.Printf("%s: %s", pass.Fset.Position(pos), msg)
+	}
+}
+
+// traced returns true if the package of the given pass belongs to one of the
+// main modules. The packages of other modules and of the standard library are
+// only analyzed as dependencies, e.g., for their facts, and tracing them would
+// flood the log and waste memory. Outside of modules, only the standard library is left out.
+func traced(pass *analysis.Pass) bool {
+	if pass.Module != nil && pass.Module.Path != "" {
+		return pass.Module.Version == ""
+	}
+	if len(pass.Files) == 0 {
+		return false
+	}
+	goroot := filepath.Join(build.Default.GOROOT, "src") + string(filepath.Separator)
+	return !strings.HasPrefix(pass.Fset.Position(pass.Files[0].Pos()).Filename, goroot)
+}
+
+// traceSwitch traces the variants covered by each case clause of a type switch
+// over the sum type def, and how its default clause affects the check, given
+// whether the switch is checked.
+func traceSwitch(
+	pass *analysis.Pass,
+	res *Result,
+	def *sumTypeDef,
+	swtch *ast.TypeSwitchStmt,
+	caseTypes []types.Type,
+	checked bool,
+) {
+	if !traced(pass) {
+		return
+	}
+	exprs, _ := caseExprs(swtch.Body)
+	for i, expr := range exprs {
+		missing := map[types.Object]bool{}
+		for _, v := range def.missing(caseTypes[i : i+1]) {
+			missing[v] = true
+		}
+		var covered []types.Object
+		for _, v := range def.Variants {
+			if !missing[v] {
+				covered = append(covered, v)
+			}
+		}
+		if len(covered) == 0 {
+			tracef(pass, res, expr.Pos(), "case %s covers no variant of '%s'",
+				types.ExprString(expr), def.Decl.TypeName)
+		} else {
+			tracef(pass, res, expr.Pos(), "case %s covers %s",
+				types.ExprString(expr), strings.Join(missingNames(covered), ", "))
+		}
+	}
+	switch clause := defaultClause(swtch.Body); {
+	case clause == nil:
+		tracef(pass, res, swtch.Pos(), "switch has no default clause")
+	case terminatingCall(pass, clause) != nil:
+		tracef(pass, res, clause.Pos(), "default clause always terminates, so the switch is still checked")
+	case checked:
+		tracef(pass, res, clause.Pos(), "default clause may not terminate, but the switch is still "+
+			"checked under the '%s' policy for default clauses", def.defaultPolicy())
+	default:
+		tracef(pass, res, clause.Pos(), "default clause may not terminate, so the switch is not checked")
+	}
+}
@@ -0,0 +1,47 @@
+package sumtype
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// reportAtVariants is set with the -report-at-variants flag. When true, each
+// variant missing from a switch is also reported at its own declaration,
+// which is where the author of a new variant looks for the switches that
+// must handle it.
+var reportAtVariants bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&reportAtVariants, "report-at-variants", false,
+		"also report each variant missing from a switch at the variant's declaration")
+}
+
+// reportUnhandledVariants reports each variant missing from the given switch
+// at the declaration of the variant. Only variants declared in the current
+// package are reported, since diagnostics can't be reported in other
+// packages.
+func reportUnhandledVariants(pass *analysis.Pass, res *Result, sw *switchInfo) {
+	pos := pass.Fset.Position(sw.Stmt.Pos())
+	for _, v := range sw.Missing {
+		if v.Pkg() != pass.Pkg || enclosingFile(pass, v.Pos()) == nil {
+			continue
+		}
+		res.report(pass, &Finding{
+			Diagnostic: analysis.Diagnostic{
+				Pos:      v.Pos(),
+				Category: CategoryUnhandledVariant,
+				Message: fmt.Sprintf(
+					"variant '%s' of sum type '%s' is not handled by switch at %s:%d",
+					v.Name(), sw.Def.Decl.TypeName, filepath.Base(pos.Filename), pos.Line),
+				Related: []analysis.RelatedInformation{{
+					Pos:     sw.Stmt.Pos(),
+					Message: "switch missing the variant",
+				}},
+			},
+			Type:    sw.Def.qualifiedName(),
+			Missing: []string{v.Name()},
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// unionVariants returns the variants of a sum type declared as a constraint
+// with a union of types, e.g., `interface{ Circle | Square }`, which are the
+// types in its type set, sorted by name. Unlike a sealed interface, such a
+// constraint needs no unexported method, since its type set is closed by
+// definition. An error is returned if a term admits types beyond those it
+// names, like ~int, or names a type that can't be matched by a case clause
+// on its own, like []int.
+func unionVariants(iface *types.Interface) ([]types.Object, error) {
+	var terms []*types.Term
+	collectTerms(iface, &terms)
+	seen := map[types.Object]bool{}
+	var variants []types.Object
+	for _, term := range terms {
+		if term.Tilde() {
+			return nil, fmt.Errorf("term '%s' admits every type whose underlying type is %s",
+				term, term.Type())
+		}
+		// A term may be excluded by another union or by the methods
+		// of the constraint.
+		if !types.Satisfies(term.Type(), iface) {
+			continue
+		}
+		obj := termObject(term.Type())
+		if obj == nil {
+			return nil, fmt.Errorf("term '%s' is not a named type", term)
+		}
+		if !seen[obj] {
+			seen[obj] = true
+			variants = append(variants, obj)
+		}
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].Name() < variants[j].Name() })
+	return variants, nil
+}
+
+// collectTerms appends the terms of the unions making up the type set of the
+// given interface to terms, including those of the constraints it embeds.
+func collectTerms(iface *types.Interface, terms *[]*types.Term) {
+	for i := 0; i < iface.NumEmbeddeds(); i++ {
+		switch ty := iface.EmbeddedType(i).(type) {
+		case *types.Union:
+			for j := 0; j < ty.Len(); j++ {
+				*terms = append(*terms, ty.Term(j))
+			}
+		default:
+			if embedded, ok := ty.Underlying().(*types.Interface); ok {
+				collectTerms(embedded, terms)
+			} else {
+				*terms = append(*terms, types.NewTerm(false, ty))
+			}
+		}
+	}
+}
+
+// termObject returns the type name matched by a case clause naming the type
+// of a term, or a pointer to it. Generic types are named by their origin, as
+// they are in the case clauses of a switch. If the type has no name, then nil
+// is returned.
+func termObject(ty types.Type) types.Object {
+	ty = types.Unalias(ty)
+	if ptr, ok := ty.(*types.Pointer); ok {
+		ty = types.Unalias(ptr.Elem())
+	}
+	switch ty := ty.(type) {
+	case *types.Named:
+		return ty.Origin().Obj()
+	case *types.Basic:
+		if obj, ok := types.Universe.Lookup(ty.Name()).(*types.TypeName); ok {
+			return obj
+		}
+	}
+	return nil
+}
+
+// convertedTypeParam returns the type parameter of the value converted to an
+// interface in expr, e.g., T in `any(v)` where v has type T. A value whose
+// type is a type parameter can only be switched on after such a conversion,
+// and its dynamic type is then in the type set of the constraint of T. If
+// expr is no such conversion, then nil is returned.
+func convertedTypeParam(pass *analysis.Pass, expr ast.Expr) types.Type {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil
+	}
+	if tv, ok := pass.TypesInfo.Types[call.Fun]; !ok || !tv.IsType() || !types.IsInterface(tv.Type) {
+		return nil
+	}
+	tp, ok := pass.TypesInfo.TypeOf(call.Args[0]).(*types.TypeParam)
+	if !ok {
+		return nil
+	}
+	return tp
+}
@@ -0,0 +1,194 @@
+package sumtype
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// checkWrappedErrors is set with the -check-wrapped-errors flag. When
+// true, type switches over sum types of errors whose switched value may have
+// been wrapped are reported. The check is opt-in since a switch may be fine
+// when the wrapped errors never reach it, e.g., because they are unwrapped
+// before.
+var checkWrappedErrors bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&checkWrappedErrors, "check-wrapped-errors", false,
+		"report type switches over sum types of errors whose switched value may have been wrapped, "+
+			"e.g., by fmt.Errorf with %w")
+}
+
+// checkWrappedError reports the given type switch over a sum type of errors
+// if the switched value may have been wrapped, since wrapped variants match
+// none of its cases. The value may have been wrapped if it is the result of
+// fmt.Errorf with a %w verb or of errors.Join, either directly or through a
+// variable assigned such a result, or through a function of the package
+// returning one.
+func checkWrappedError(pass *analysis.Pass, res *Result, sw *switchInfo) {
+	errIface := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	if !types.Implements(sw.Def.Ty, errIface) {
+		return
+	}
+	w := &wrapFinder{pass: pass, visiting: map[*types.Func]bool{}}
+	how := w.expr(findTypeAssertExpr(sw.Stmt), 0)
+	if how == "" {
+		if obj := identObject(pass, findTypeAssertExpr(sw.Stmt)); obj != nil {
+			how = w.variable(enclosingFile(pass, sw.Stmt.Pos()), obj)
+		}
+	}
+	if how == "" {
+		return
+	}
+	res.report(pass, &Finding{
+		Diagnostic: analysis.Diagnostic{
+			Pos:      sw.Stmt.Pos(),
+			Category: CategoryWrappedError,
+			Message: fmt.Sprintf("type switch over error sum type '%s' misses variants wrapped by %s; "+
+				"use errors.As for each variant instead", sw.Def.Decl.TypeName, how),
+			Related: sw.Def.Decl.related(),
+		},
+		Type: sw.Def.qualifiedName(),
+	})
+}
+
+// identObject returns the variable named by expr, or nil if expr is not the
+// name of a variable.
+func identObject(pass *analysis.Pass, expr ast.Expr) *types.Var {
+	id, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, _ := pass.TypesInfo.Uses[id].(*types.Var)
+	return v
+}
+
+// wrapFinder finds where errors may have been wrapped.
+type wrapFinder struct {
+	pass *analysis.Pass
+	// visiting are the functions whose results are being inspected, which
+	// guards against recursion.
+	visiting map[*types.Func]bool
+}
+
+// variable returns a description of how the errors assigned to v in file may
+// have been wrapped, or an empty string if they are not wrapped.
+func (w *wrapFinder) variable(file *ast.File, v *types.Var) string {
+	if file == nil {
+		return ""
+	}
+	var how string
+	assigned := func(lhs []ast.Expr, rhs []ast.Expr) {
+		for i, l := range lhs {
+			id, ok := l.(*ast.Ident)
+			if !ok || how != "" || w.pass.TypesInfo.ObjectOf(id) != v {
+				continue
+			}
+			if len(rhs) == len(lhs) {
+				how = w.expr(rhs[i], 0)
+			} else if len(rhs) == 1 {
+				how = w.expr(rhs[0], i)
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			assigned(n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			var lhs []ast.Expr
+			for _, name := range n.Names {
+				lhs = append(lhs, name)
+			}
+			assigned(lhs, n.Values)
+		}
+		return how == ""
+	})
+	return how
+}
+
+// expr returns a description of how the index-th result of expr may have
+// been wrapped, or an empty string if it is not wrapped.
+func (w *wrapFinder) expr(expr ast.Expr, index int) string {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+	fn, ok := typeutil.Callee(w.pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return ""
+	}
+	switch {
+	case fn.Pkg().Path() == "fmt" && fn.Name() == "Errorf":
+		if len(call.Args) == 0 {
+			return ""
+		}
+		format := w.pass.TypesInfo.Types[call.Args[0]].Value
+		if format != nil && format.Kind() == constant.String &&
+			strings.Contains(constant.StringVal(format), "%w") {
+			return "fmt.Errorf with %w"
+		}
+	case fn.Pkg().Path() == "errors" && fn.Name() == "Join":
+		return "errors.Join"
+	case fn.Pkg() == w.pass.Pkg:
+		return w.function(fn, index)
+	}
+	return ""
+}
+
+// function returns a description of how the index-th result of the given
+// function of the package may have been wrapped, naming the function that
+// wraps it, or an empty string if it is not wrapped.
+func (w *wrapFinder) function(fn *types.Func, index int) string {
+	if w.visiting[fn] {
+		return ""
+	}
+	w.visiting[fn] = true
+	defer delete(w.visiting, fn)
+
+	decl := funcDecl(w.pass, fn)
+	if decl == nil || decl.Body == nil {
+		return ""
+	}
+	var how string
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if how != "" {
+				break
+			}
+			if index < len(n.Results) {
+				how = w.expr(n.Results[index], 0)
+			} else if len(n.Results) == 1 {
+				how = w.expr(n.Results[0], index)
+			}
+		}
+		return how == ""
+	})
+	if how != "" && !strings.Contains(how, " in '") {
+		how += fmt.Sprintf(" in '%s'", fn.Name())
+	}
+	return how
+}
+
+// funcDecl returns the declaration of the given function of the package, or
+// nil if it is not declared in the files being analyzed.
+func funcDecl(pass *analysis.Pass, fn *types.Func) *ast.FuncDecl {
+	file := enclosingFile(pass, fn.Pos())
+	if file == nil {
+		return nil
+	}
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok && pass.TypesInfo.Defs[decl.Name] == fn {
+			return decl
+		}
+	}
+	return nil
+}
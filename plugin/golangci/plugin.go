@@ -0,0 +1,57 @@
+// Command golangci is the go-sumtype linter as a plugin for golangci-lint's
+// legacy Go plugin system, for installations that do not support module
+// plugins yet. Build it with the same versions of Go and of the dependencies
+// as golangci-lint itself:
+//
+//	go build -buildmode=plugin -o go-sumtype.so ./plugin/golangci
+//
+// and reference the resulting file in .golangci.yml. Settings are the flags of
+// the analyzer, without the leading dash:
+//
+//	linters-settings:
+//	  custom:
+//	    gosumtype:
+//	      path: go-sumtype.so
+//	      description: exhaustiveness checks for sum types
+//	      settings:
+//	        preset: strict
+//	        ignore-variants: Mock$
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+	"golang.org/x/tools/go/analysis"
+)
+
+// New returns the analyzers of the plugin. It is the symbol looked up by
+// golangci-lint when it loads the plugin, and conf holds the settings given in
+// .golangci.yml, if any.
+func New(conf any) ([]*analysis.Analyzer, error) {
+	if conf != nil {
+		settings, ok := conf.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("go-sumtype: settings must be a map, not %T", conf)
+		}
+		// Presets are applied first, so that other settings override them.
+		var names []string
+		for name := range settings {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return names[i] == "preset" || (names[j] != "preset" && names[i] < names[j])
+		})
+		for _, name := range names {
+			if err := sumtype.Analyzer.Flags.Set(name, fmt.Sprint(settings[name])); err != nil {
+				return nil, fmt.Errorf("go-sumtype: setting '%s': %v", name, err)
+			}
+		}
+	}
+	return []*analysis.Analyzer{sumtype.Analyzer}, nil
+}
+
+// main is never called, as the package is loaded as a plugin, but it lets the
+// package build as part of ./... without -buildmode=plugin.
+func main() {}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+)
+
+// removeVariantMain implements `go-sumtype remove-variant`, which removes a
+// variant of a sum type throughout its module: its declaration and methods,
+// and its case clauses in every type switch. It lists the removed case clauses
+// that contained logic, and the remaining references to the variant, which
+// need manual attention. The result is type-checked before any file is
+// written, so nothing is written while references remain.
+func removeVariantMain(args []string) error {
+	flags := flag.NewFlagSet("remove-variant", flag.ExitOnError)
+	dryRun := flags.Bool("n", false, "only print what would be changed")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype remove-variant [flags] [package.]Variant\n\n")
+		fmt.Fprintf(flags.Output(), "Remove a variant of a sum type and its case clauses "+
+			"throughout its module.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	pattern, name := splitQualifiedName(flags.Arg(0))
+
+	pkgs, err := driver.Run(nil, pattern)
+	if err != nil {
+		return err
+	}
+	pkg, variant := findVariant(pkgs, name)
+	if variant == nil {
+		return fmt.Errorf("no variant of a sum type named '%s' in %s", name, pattern)
+	}
+	if pkg.Module == nil {
+		return fmt.Errorf("package %s is not in a module", pkg.PkgPath)
+	}
+	cfg := &driver.Config{Dir: pkg.Module.Dir, Tests: true}
+	all, err := driver.Run(cfg, "./...")
+	if err != nil {
+		return err
+	}
+	r := &remover{
+		variant:  posKey(pkg.Fset, variant.Pos()),
+		name:     name,
+		removals: map[string]map[int]int{},
+	}
+	for _, p := range all {
+		r.removeDecls(p)
+		r.removeCases(p)
+	}
+	for _, p := range all {
+		r.findReferences(p)
+	}
+	files, err := r.apply()
+	if err != nil {
+		return err
+	}
+	sort.Slice(r.notes, func(i, j int) bool {
+		a, b := r.notes[i].pos, r.notes[j].pos
+		return a.Filename < b.Filename || a.Filename == b.Filename && a.Offset < b.Offset
+	})
+
+	// Type-check the module without the variant before writing any file.
+	cfg.Overlay = files
+	if _, err := driver.Run(cfg, "./..."); err != nil {
+		r.printNotes()
+		return fmt.Errorf("removing '%s' would break the build: %v", name, err)
+	}
+
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Println(path)
+		if *dryRun {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, files[path], info.Mode()); err != nil {
+			return err
+		}
+	}
+	r.printNotes()
+	return nil
+}
+
+// remover collects the source removed to remove a variant, and the places
+// that need manual attention. Like renamer, it identifies the variant by the
+// position of its declaration.
+type remover struct {
+	variant string
+	name    string
+	// removals maps file paths to the ranges of source removed from them,
+	// as the end offset of each range by its start offset.
+	removals map[string]map[int]int
+	notes    []removalNote
+}
+
+// removalNote is a place that needs manual attention after removing a
+// variant.
+type removalNote struct {
+	pos token.Position
+	msg string
+}
+
+// printNotes prints the places that need manual attention, once each.
+func (r *remover) printNotes() {
+	seen := map[string]bool{}
+	for _, n := range r.notes {
+		note := fmt.Sprintf("%s:%d: %s", n.pos.Filename, n.pos.Line, n.msg)
+		if !seen[note] {
+			seen[note] = true
+			fmt.Println(note)
+		}
+	}
+}
+
+// remove removes the source between start and end.
+func (r *remover) remove(fset *token.FileSet, start, end token.Pos) {
+	p := fset.PositionFor(start, false)
+	if r.removals[p.Filename] == nil {
+		r.removals[p.Filename] = map[int]int{}
+	}
+	r.removals[p.Filename][p.Offset] = fset.PositionFor(end, false).Offset
+}
+
+// removed returns true if pos is in source that is removed.
+func (r *remover) removed(pos token.Position) bool {
+	for start, end := range r.removals[pos.Filename] {
+		if start <= pos.Offset && pos.Offset < end {
+			return true
+		}
+	}
+	return false
+}
+
+// isVariant returns true if ty is the variant, or a pointer to it.
+func (r *remover) isVariant(fset *token.FileSet, ty types.Type) bool {
+	if ptr, ok := ty.(*types.Pointer); ok {
+		ty = ptr.Elem()
+	}
+	named, ok := types.Unalias(ty).(*types.Named)
+	return ok && posKey(fset, named.Obj().Pos()) == r.variant
+}
+
+// removeDecls removes the declaration of the variant, along with its
+// methods, if they are in pkg.
+func (r *remover) removeDecls(pkg *driver.Package) {
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					tspec, ok := spec.(*ast.TypeSpec)
+					if !ok || posKey(pkg.Fset, tspec.Name.Pos()) != r.variant {
+						continue
+					}
+					if len(decl.Specs) == 1 {
+						r.remove(pkg.Fset, withDoc(decl, decl.Doc), decl.End())
+					} else {
+						r.remove(pkg.Fset, withDoc(tspec, tspec.Doc), tspec.End())
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv != nil && r.isVariant(pkg.Fset, pkg.TypesInfo.TypeOf(decl.Recv.List[0].Type)) {
+					r.remove(pkg.Fset, withDoc(decl, decl.Doc), decl.End())
+				}
+			}
+		}
+	}
+}
+
+// withDoc returns the start of node, including its doc comment.
+func withDoc(node ast.Node, doc *ast.CommentGroup) token.Pos {
+	if doc != nil {
+		return doc.Pos()
+	}
+	return node.Pos()
+}
+
+// removeCases removes the variant from the case clauses of the type switches
+// in pkg. A clause that only lists the variant is removed along with its body,
+// which is noted if it is not empty.
+func (r *remover) removeCases(pkg *driver.Package) {
+	for _, file := range pkg.Syntax {
+		ast.Inspect(file, func(n ast.Node) bool {
+			swtch, ok := n.(*ast.TypeSwitchStmt)
+			if !ok {
+				return true
+			}
+			for i, stmt := range swtch.Body.List {
+				clause := stmt.(*ast.CaseClause)
+				var keep []ast.Expr
+				for _, expr := range clause.List {
+					if !r.isVariant(pkg.Fset, pkg.TypesInfo.TypeOf(expr)) {
+						keep = append(keep, expr)
+					}
+				}
+				if len(keep) == len(clause.List) {
+					continue
+				}
+				if len(keep) > 0 {
+					r.removeExprs(pkg.Fset, clause.List, keep)
+					continue
+				}
+				end := swtch.Body.Rbrace
+				if i+1 < len(swtch.Body.List) {
+					end = swtch.Body.List[i+1].Pos()
+				}
+				r.remove(pkg.Fset, clause.Pos(), end)
+				if len(clause.Body) > 0 {
+					r.notes = append(r.notes, removalNote{
+						pos: pkg.Fset.PositionFor(clause.Pos(), false),
+						msg: fmt.Sprintf("removed the case for %s, whose body needs attention", r.name),
+					})
+				}
+			}
+			return true
+		})
+	}
+}
+
+// removeExprs removes the expressions of list that are not in keep, along with
+// the commas separating them from the others.
+func (r *remover) removeExprs(fset *token.FileSet, list, keep []ast.Expr) {
+	last := list[0]
+	kept := map[ast.Expr]bool{}
+	for _, expr := range keep {
+		kept[expr] = true
+		last = expr
+	}
+	after := false
+	for i, expr := range list {
+		switch {
+		case expr == last:
+			after = true
+		case kept[expr]:
+		case !after:
+			r.remove(fset, expr.Pos(), list[i+1].Pos())
+		default:
+			// The comma before the expressions after the last one
+			// kept is removed too.
+			r.remove(fset, last.End(), expr.End())
+		}
+	}
+}
+
+// findReferences notes the references to the variant in pkg that are not
+// removed.
+func (r *remover) findReferences(pkg *driver.Package) {
+	for id, obj := range pkg.TypesInfo.Uses {
+		if _, ok := obj.(*types.TypeName); !ok || posKey(pkg.Fset, obj.Pos()) != r.variant {
+			continue
+		}
+		if pos := pkg.Fset.PositionFor(id.Pos(), false); !r.removed(pos) {
+			r.notes = append(r.notes, removalNote{
+				pos: pos,
+				msg: fmt.Sprintf("%s is still referenced", r.name),
+			})
+		}
+	}
+}
+
+// apply returns the contents of the files with the source removed, formatted,
+// by path. Ranges within ranges that are removed are ignored.
+func (r *remover) apply() (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for path, removals := range r.removals {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var starts []int
+		for start := range removals {
+			starts = append(starts, start)
+		}
+		sort.Ints(starts)
+		var buf bytes.Buffer
+		last := 0
+		for _, start := range starts {
+			if start < last {
+				continue
+			}
+			buf.Write(src[last:start])
+			last = removals[start]
+			// Whole lines, such as declarations, are removed along
+			// with their line break.
+			if (start == 0 || src[start-1] == '\n') && last < len(src) && src[last] == '\n' {
+				last++
+			}
+		}
+		buf.Write(src[last:])
+		if files[path], err = format.Source(buf.Bytes()); err != nil {
+			return nil, fmt.Errorf("formatting %s: %v", path, err)
+		}
+	}
+	return files, nil
+}
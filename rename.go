@@ -0,0 +1,400 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype/directive"
+)
+
+// renameVariantMain implements `go-sumtype rename-variant`, which renames a
+// variant of a sum type throughout its module: its declaration, every
+// reference to it, including case clauses, the functions, methods and fields
+// derived from it, e.g., VisitLit in a visitor or OnLit in a matcher, and the
+// directives naming it. The result is type-checked before any file is written.
+func renameVariantMain(args []string) error {
+	flags := flag.NewFlagSet("rename-variant", flag.ExitOnError)
+	dryRun := flags.Bool("n", false, "only print the files that would be changed")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype rename-variant [flags] [package.]Variant NewName\n\n")
+		fmt.Fprintf(flags.Output(), "Rename a variant of a sum type and everything derived "+
+			"from it throughout its module.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	pattern, oldName := splitQualifiedName(flags.Arg(0))
+	newPattern, newName := splitQualifiedName(flags.Arg(1))
+	if newPattern != "." && newPattern != pattern {
+		return fmt.Errorf("a variant can only be renamed within its package")
+	}
+	if !token.IsIdentifier(newName) {
+		return fmt.Errorf("'%s' is not a valid identifier", newName)
+	}
+
+	pkgs, err := driver.Run(nil, pattern)
+	if err != nil {
+		return err
+	}
+	pkg, variant := findVariant(pkgs, oldName)
+	if variant == nil {
+		return fmt.Errorf("no variant of a sum type named '%s' in %s", oldName, pattern)
+	}
+	if pkg.Module == nil {
+		return fmt.Errorf("package %s is not in a module", pkg.PkgPath)
+	}
+	if variant.Pkg().Scope().Lookup(newName) != nil {
+		return fmt.Errorf("'%s' is already declared in package %s", newName, pkg.PkgPath)
+	}
+
+	cfg := &driver.Config{Dir: pkg.Module.Dir, Tests: true}
+	all, err := driver.Run(cfg, "./...")
+	if err != nil {
+		return err
+	}
+	if len(all) == 0 {
+		return fmt.Errorf("no packages in module %s", pkg.Module.Path)
+	}
+	r := &renamer{
+		fset:    all[0].Fset,
+		variant: posKey(pkg.Fset, variant.Pos()),
+		oldName: oldName,
+		newName: newName,
+		targets: map[string]string{},
+		edits:   map[string]map[int]renameEdit{},
+	}
+	r.findTargets(all)
+	for _, p := range all {
+		r.rename(p, variant.Pkg().Path())
+	}
+	files, err := r.apply()
+	if err != nil {
+		return err
+	}
+
+	// Type-check the module with the renamed files before writing them, and
+	// make sure that no switch lost exhaustiveness along the way.
+	cfg.Overlay = files
+	renamed, err := driver.Run(cfg, "./...")
+	if err != nil {
+		return fmt.Errorf("renaming '%s' to '%s' would break the build: %v", oldName, newName, err)
+	}
+	if n, m := len(driver.Findings(all)), len(driver.Findings(renamed)); m > n {
+		return fmt.Errorf("renaming '%s' to '%s' would add %d findings", oldName, newName, m-n)
+	}
+
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, e := range r.sortedEdits(path) {
+			fmt.Printf("%s:%d: %s -> %s\n", path, e.line, e.old, e.new)
+		}
+		if *dryRun {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, files[path], info.Mode()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findVariant returns the variant with the given name of a sum type declared
+// in one of the given packages, along with that package.
+func findVariant(pkgs []*driver.Package, name string) (*driver.Package, *types.TypeName) {
+	for _, pkg := range pkgs {
+		for _, st := range pkg.Result.SumTypes {
+			for _, v := range st.Variants {
+				if v.Name() == name && v.Pkg() == pkg.Types {
+					return pkg, v
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// renameEdit replaces the identifier at an offset in a file.
+type renameEdit struct {
+	line     int
+	old, new string
+}
+
+// renamer collects the edits renaming a variant. Objects are identified by
+// the positions of their declarations, since a package and its tests are
+// type-checked separately and so have distinct objects.
+type renamer struct {
+	fset             *token.FileSet
+	variant          string
+	oldName, newName string
+	// targets maps the objects to rename to their new names.
+	targets map[string]string
+	// edits maps file paths to the edits in them, by offset.
+	edits map[string]map[int]renameEdit
+}
+
+// posKey returns the key identifying the object declared at pos. It ignores
+// line directives, which may map several declarations to the same place.
+func posKey(fset *token.FileSet, pos token.Pos) string {
+	p := fset.PositionFor(pos, false)
+	return fmt.Sprintf("%s:%d", p.Filename, p.Offset)
+}
+
+// findTargets finds the objects to rename in the given packages: the variant,
+// the fields embedding it, and the functions, methods and fields whose name
+// is derived from the variant's and whose signature refers to it, e.g.,
+// NewLit, VisitLit(*Lit) or a matcher's OnLit field.
+func (r *renamer) findTargets(pkgs []*driver.Package) {
+	r.targets[r.variant] = r.newName
+	for _, pkg := range pkgs {
+		for _, obj := range pkg.TypesInfo.Defs {
+			if obj == nil || obj.Pos() == token.NoPos {
+				continue
+			}
+			switch obj := obj.(type) {
+			case *types.Var:
+				if obj.Embedded() && r.isVariant(obj.Type()) {
+					r.targets[posKey(r.fset, obj.Pos())] = r.newName
+					continue
+				}
+				sig, ok := obj.Type().Underlying().(*types.Signature)
+				if obj.IsField() && ok && r.refersToVariant(sig) {
+					r.addDerived(obj)
+				}
+			case *types.Func:
+				if r.refersToVariant(obj.Type().(*types.Signature)) {
+					r.addDerived(obj)
+				}
+			}
+		}
+	}
+}
+
+// addDerived adds obj to the targets if its name is derived from the name of
+// the variant, i.e., contains it as a word.
+func (r *renamer) addDerived(obj types.Object) {
+	if name := r.derivedName(obj.Name()); name != "" {
+		r.targets[posKey(r.fset, obj.Pos())] = name
+	}
+}
+
+// derivedName returns name with the name of the variant replaced by the new
+// one, or the empty string if name is not derived from the variant's. The
+// name of the variant may start the name, as in LitFromProto, or follow
+// another word, capitalized, as in VisitLit, and it must be followed by the
+// end of the name or another word.
+func (r *renamer) derivedName(name string) string {
+	old, new := r.oldName, r.newName
+	i := strings.Index(name, old)
+	if i != 0 {
+		old, new = upperFirst(old), upperFirst(new)
+		if i = strings.Index(name, old); i <= 0 {
+			return ""
+		}
+	}
+	rest := name[i+len(old):]
+	if next, _ := utf8.DecodeRuneInString(rest); rest != "" && !unicode.IsUpper(next) && next != '_' {
+		return ""
+	}
+	return name[:i] + new + rest
+}
+
+// upperFirst returns s with its first letter in upper case.
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+// isVariant returns true if ty is the variant, or a pointer to it.
+func (r *renamer) isVariant(ty types.Type) bool {
+	if ptr, ok := ty.(*types.Pointer); ok {
+		ty = ptr.Elem()
+	}
+	named, ok := types.Unalias(ty).(*types.Named)
+	return ok && posKey(r.fset, named.Obj().Pos()) == r.variant
+}
+
+// refersToVariant returns true if a parameter or result of sig is the variant,
+// or a pointer to it.
+func (r *renamer) refersToVariant(sig *types.Signature) bool {
+	for _, tuple := range []*types.Tuple{sig.Params(), sig.Results()} {
+		for i := 0; i < tuple.Len(); i++ {
+			if r.isVariant(tuple.At(i).Type()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rename adds the edits renaming the targets in the given package, including
+// at the start of their doc comments, and the variant in the directives of
+// its files if the package is the variant's or imports it.
+func (r *renamer) rename(pkg *driver.Package, variantPkg string) {
+	for id, obj := range pkg.TypesInfo.Defs {
+		r.renameIdent(id, obj)
+	}
+	for id, obj := range pkg.TypesInfo.Uses {
+		r.renameIdent(id, obj)
+	}
+	for _, file := range pkg.Syntax {
+		r.renameDocs(file, pkg.TypesInfo)
+	}
+	if pkg.PkgPath != variantPkg && pkg.Imports[variantPkg] == nil {
+		return
+	}
+	for _, file := range pkg.Syntax {
+		for _, cg := range file.Comments {
+			for _, c := range cg.List {
+				r.renameDirective(c)
+			}
+		}
+	}
+}
+
+// renameIdent adds an edit renaming id if it denotes one of the targets.
+func (r *renamer) renameIdent(id *ast.Ident, obj types.Object) {
+	if name, ok := r.target(obj); ok {
+		r.add(id.Pos(), id.Name, name)
+	}
+}
+
+// target returns the new name of obj if it is one of the targets.
+func (r *renamer) target(obj types.Object) (string, bool) {
+	if obj == nil {
+		return "", false
+	}
+	if fn, ok := obj.(*types.Func); ok {
+		obj = fn.Origin()
+	} else if v, ok := obj.(*types.Var); ok {
+		obj = v.Origin()
+	}
+	name, ok := r.targets[posKey(r.fset, obj.Pos())]
+	return name, ok
+}
+
+// renameDocs adds edits renaming the targets declared in file at the start
+// of their doc comments, as in "// NewLit returns ...".
+func (r *renamer) renameDocs(file *ast.File, info *types.Info) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			r.renameDoc(n.Doc, n.Name, info)
+		case *ast.GenDecl:
+			if ts, ok := n.Specs[0].(*ast.TypeSpec); ok && len(n.Specs) == 1 {
+				r.renameDoc(n.Doc, ts.Name, info)
+			}
+		case *ast.TypeSpec:
+			r.renameDoc(n.Doc, n.Name, info)
+		case *ast.Field:
+			for _, name := range n.Names {
+				r.renameDoc(n.Doc, name, info)
+			}
+		}
+		return true
+	})
+}
+
+// renameDoc adds an edit renaming the target declared by id where the given
+// doc comment starts with its name.
+func (r *renamer) renameDoc(doc *ast.CommentGroup, id *ast.Ident, info *types.Info) {
+	name, ok := r.target(info.Defs[id])
+	if !ok || doc == nil || !strings.HasPrefix(doc.List[0].Text, "// "+id.Name+" ") {
+		return
+	}
+	r.add(doc.List[0].Slash+token.Pos(len("// ")), id.Name, name)
+}
+
+// renameDirective adds edits renaming the variant where it is named by the
+// given comment, if it is a `go-sumtype:constructs ... except ...` directive.
+func (r *renamer) renameDirective(c *ast.Comment) {
+	d, ok := directive.ParseLine(c.Text)
+	if !ok || d.Kind != directive.Constructs {
+		return
+	}
+	except := false
+	offset := 0
+	for _, arg := range d.Args {
+		i := strings.Index(c.Text[offset:], arg)
+		offset += i
+		if except && arg == r.oldName {
+			r.add(c.Slash+token.Pos(offset), r.oldName, r.newName)
+		}
+		except = except || arg == "except"
+		offset += len(arg)
+	}
+}
+
+// add adds an edit replacing old with new at pos.
+func (r *renamer) add(pos token.Pos, old, new string) {
+	p := r.fset.PositionFor(pos, false)
+	if r.edits[p.Filename] == nil {
+		r.edits[p.Filename] = map[int]renameEdit{}
+	}
+	r.edits[p.Filename][p.Offset] = renameEdit{line: p.Line, old: old, new: new}
+}
+
+// apply returns the contents of the edited files, formatted, by path.
+func (r *renamer) apply() (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for path, edits := range r.edits {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		offsets := r.offsets(path)
+		for i := len(offsets) - 1; i >= 0; i-- {
+			offset := offsets[i]
+			e := edits[offset]
+			if string(src[offset:offset+len(e.old)]) != e.old {
+				return nil, fmt.Errorf("%s has changed since it was loaded", path)
+			}
+			src = append(src[:offset], append([]byte(e.new), src[offset+len(e.old):]...)...)
+		}
+		formatted, err := format.Source(src)
+		if err != nil {
+			return nil, fmt.Errorf("formatting %s: %v", path, err)
+		}
+		files[path] = formatted
+	}
+	return files, nil
+}
+
+// offsets returns the offsets of the edits in the file at path, in order.
+func (r *renamer) offsets(path string) []int {
+	var offsets []int
+	for offset := range r.edits[path] {
+		offsets = append(offsets, offset)
+	}
+	sort.Ints(offsets)
+	return offsets
+}
+
+// sortedEdits returns the edits in the file at path, in order.
+func (r *renamer) sortedEdits(path string) []renameEdit {
+	var edits []renameEdit
+	for _, offset := range r.offsets(path) {
+		edits = append(edits, r.edits[path][offset])
+	}
+	return edits
+}
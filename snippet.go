@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// snippetMain implements `go-sumtype snippet`, which prints an exhaustive
+// type switch over a sum type that is ready to be pasted into a new handler.
+func snippetMain(args []string) error {
+	flags := flag.NewFlagSet("snippet", flag.ExitOnError)
+	expr := flags.String("expr", "x", "the expression to switch on")
+	qualify := flags.Bool("qualify", false,
+		"qualify variant names with their package name")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype snippet [flags] [package.]Type\n\n")
+		fmt.Fprintf(flags.Output(), "Print an exhaustive type switch over a sum type.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	pattern, name := splitQualifiedName(flags.Arg(0))
+	pkgs, err := driver.Run(nil, pattern)
+	if err != nil {
+		return err
+	}
+	st := driver.FindSumType(pkgs, name)
+	if st == nil {
+		return fmt.Errorf("no sum type named '%s' in %s", name, pattern)
+	}
+	var qual types.Qualifier = func(*types.Package) string { return "" }
+	if *qualify {
+		qual = func(pkg *types.Package) string { return pkg.Name() }
+	}
+	fmt.Print(snippet(st, *expr, qual))
+	return nil
+}
+
+// snippet returns the source code of an exhaustive type switch over the given
+// sum type, with a default clause that panics.
+func snippet(st *sumtype.SumType, expr string, qual types.Qualifier) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "switch %s := %s.(type) {\n", expr, expr)
+	for _, v := range st.Variants {
+		fmt.Fprintf(&buf, "case %s:\n", variantTypeString(st, v, qual))
+	}
+	fmt.Fprintf(&buf, "default:\n")
+	fmt.Fprintf(&buf, "\tpanic(fmt.Sprintf(\"unhandled %s variant: %%T\", %s))\n",
+		st.Type.Name(), expr)
+	fmt.Fprintf(&buf, "}\n")
+	return buf.String()
+}
+
+// variantTypeString returns the type expression used to match the given
+// variant in a type switch. This is a pointer type when the variant only
+// implements the sum type through pointer receivers.
+func variantTypeString(st *sumtype.SumType, v *types.TypeName, qual types.Qualifier) string {
+	name := types.TypeString(v.Type(), qual)
+	iface := st.Type.Type().Underlying().(*types.Interface)
+	if !types.Implements(v.Type(), iface) {
+		return "*" + name
+	}
+	return name
+}
+
+// splitQualifiedName splits a name like `github.com/foo/ast.Expr` into a
+// package pattern and a type name. If the name is not qualified, then the
+// package in the current directory is used.
+func splitQualifiedName(qualified string) (pattern, name string) {
+	i := strings.LastIndex(qualified, ".")
+	if i <= 0 || i == len(qualified)-1 {
+		return ".", qualified
+	}
+	return qualified[:i], qualified[i+1:]
+}
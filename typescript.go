@@ -0,0 +1,220 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// typeScriptMain implements `go-sumtype typescript`, which generates
+// TypeScript definitions describing sum types as discriminated unions of
+// their variants, for clients sharing models with Go services.
+func typeScriptMain(args []string) error {
+	flags := flag.NewFlagSet("typescript", flag.ExitOnError)
+	out := flags.String("o", "", "write the definitions to this file instead of stdout")
+	discriminator := flags.String("discriminator", "type",
+		"the name of the property identifying the variant of a value")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype typescript [flags] [package.]Type...\n\n")
+		fmt.Fprintf(flags.Output(), "Generate TypeScript discriminated unions over "+
+			"the variants of each sum type.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	gen := &typeScriptGenerator{discriminator: *discriminator, defs: map[string]string{}}
+	for _, arg := range flags.Args() {
+		pattern, name := splitQualifiedName(arg)
+		pkgs, err := driver.Run(nil, pattern)
+		if err != nil {
+			return err
+		}
+		st := driver.FindSumType(pkgs, name)
+		if st == nil {
+			return fmt.Errorf("no sum type named '%s' in %s", name, pattern)
+		}
+		gen.pkgs = append(gen.pkgs, pkgs...)
+		gen.sumType(st)
+	}
+
+	if *out == "" {
+		gen.write(os.Stdout)
+		return nil
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	gen.write(f)
+	return f.Close()
+}
+
+// typeScriptGenerator accumulates TypeScript definitions for sum types, their
+// variants and any named types they refer to.
+type typeScriptGenerator struct {
+	pkgs          []*driver.Package
+	discriminator string
+	// defs maps the names of the definitions to their source, and order
+	// are the names in the order they were added.
+	defs  map[string]string
+	order []string
+}
+
+// add adds a definition with the given name.
+func (g *typeScriptGenerator) add(name, def string) {
+	if _, ok := g.defs[name]; !ok {
+		g.order = append(g.order, name)
+	}
+	g.defs[name] = def
+}
+
+// sumType adds definitions for the given sum type and all of its variants:
+// a union of the variants, each of which is an interface whose discriminator
+// property holds the variant's name.
+func (g *typeScriptGenerator) sumType(st *sumtype.SumType) {
+	name := st.Type.Name()
+	if _, ok := g.defs[name]; ok {
+		return
+	}
+	var members []string
+	for _, v := range st.Variants {
+		members = append(members, v.Name())
+	}
+	union := fmt.Sprintf("export type %s = %s;\n", name, strings.Join(members, " | "))
+	if len(members) == 0 {
+		union = fmt.Sprintf("export type %s = never;\n", name)
+	}
+	g.add(name, union)
+	for _, v := range st.Variants {
+		g.object(v, fmt.Sprintf("  %s: %s;\n", propertyName(g.discriminator), strconv.Quote(v.Name())))
+	}
+}
+
+// object adds an interface for the given type, with a property for each of
+// its exported fields, following their `json` struct tags, after the given
+// leading properties.
+func (g *typeScriptGenerator) object(obj *types.TypeName, leading string) {
+	// Reserve the name first, in case the type refers to itself.
+	g.add(obj.Name(), "")
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "export interface %s {\n", obj.Name())
+	buf.WriteString(leading)
+	if s, ok := obj.Type().Underlying().(*types.Struct); ok {
+		for i := 0; i < s.NumFields(); i++ {
+			f := s.Field(i)
+			if !f.Exported() {
+				continue
+			}
+			name, optional := f.Name(), ""
+			if tag, ok := reflect.StructTag(s.Tag(i)).Lookup("json"); ok {
+				parts := strings.Split(tag, ",")
+				if parts[0] == "-" {
+					continue
+				}
+				if parts[0] != "" {
+					name = parts[0]
+				}
+				for _, opt := range parts[1:] {
+					if opt == "omitempty" || opt == "omitzero" {
+						optional = "?"
+					}
+				}
+			}
+			fmt.Fprintf(&buf, "  %s%s: %s;\n", propertyName(name), optional, g.typ(f.Type()))
+		}
+	}
+	buf.WriteString("}\n")
+	g.defs[obj.Name()] = buf.String()
+}
+
+// typ returns the TypeScript type of a value of the given Go type, as it is
+// encoded by encoding/json.
+func (g *typeScriptGenerator) typ(ty types.Type) string {
+	ty = types.Unalias(ty)
+	if named, ok := ty.(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+			return "string"
+		}
+		for _, pkg := range g.pkgs {
+			for _, st := range pkg.Result.SumTypes {
+				if st.Type == obj {
+					g.sumType(st)
+					return obj.Name()
+				}
+			}
+		}
+		if _, ok := named.Underlying().(*types.Struct); ok {
+			if _, ok := g.defs[obj.Name()]; !ok {
+				g.object(obj, "")
+			}
+			return obj.Name()
+		}
+	}
+	switch t := ty.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsBoolean != 0:
+			return "boolean"
+		case t.Info()&types.IsNumeric != 0:
+			return "number"
+		case t.Info()&types.IsString != 0:
+			return "string"
+		}
+	case *types.Pointer:
+		return g.typ(t.Elem()) + " | null"
+	case *types.Slice:
+		if b, ok := t.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return "string"
+		}
+		return arrayOf(g.typ(t.Elem())) + " | null"
+	case *types.Array:
+		return arrayOf(g.typ(t.Elem()))
+	case *types.Map:
+		return "Record<string, " + g.typ(t.Elem()) + "> | null"
+	case *types.Struct:
+		return "object"
+	}
+	// Anything else, e.g., an interface, may be any JSON value.
+	return "unknown"
+}
+
+// arrayOf returns the type of an array of elements of type elem.
+func arrayOf(elem string) string {
+	if strings.Contains(elem, " ") {
+		return "(" + elem + ")[]"
+	}
+	return elem + "[]"
+}
+
+// propertyName returns name as the name of a property, quoted if it is not a
+// valid identifier.
+func propertyName(name string) string {
+	for i, r := range name {
+		if r != '_' && r != '$' && !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z') &&
+			(i == 0 || r < '0' || r > '9') {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
+
+// write writes the definitions to w.
+func (g *typeScriptGenerator) write(w io.Writer) {
+	fmt.Fprintf(w, "// Code generated by go-sumtype typescript. DO NOT EDIT.\n")
+	for _, name := range g.order {
+		fmt.Fprintf(w, "\n%s", g.defs[name])
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/go-sumtype/pkg/driver"
+	"github.com/BurntSushi/go-sumtype/pkg/sumtype"
+)
+
+// whyMain implements `go-sumtype why`, which explains how the type switch at
+// a given line was treated by the analyzer: whether it was checked, which sum
+// type it matched, how its default clause was classified and which variants
+// each case covered. It reuses the trace recorded in the result of the
+// analyzer, which the -trace flag logs, restricted to the switch.
+func whyMain(args []string) error {
+	flags := flag.NewFlagSet("why", flag.ExitOnError)
+	tags := flags.String("tags", "", "a comma-separated list of build tags to consider satisfied")
+	sumtype.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: go-sumtype why [flags] file.go:line\n\n")
+		fmt.Fprintf(flags.Output(), "Explain how the type switch at the given line was checked.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	i := strings.LastIndex(flags.Arg(0), ":")
+	if i < 0 {
+		return fmt.Errorf("invalid position '%s' (expected file.go:line)", flags.Arg(0))
+	}
+	line, err := strconv.Atoi(flags.Arg(0)[i+1:])
+	if err != nil {
+		return fmt.Errorf("invalid position '%s' (expected file.go:line)", flags.Arg(0))
+	}
+	path, err := filepath.Abs(flags.Arg(0)[:i])
+	if err != nil {
+		return err
+	}
+
+	cfg := &driver.Config{Tests: true}
+	if *tags != "" {
+		cfg.BuildFlags = append(cfg.BuildFlags, "-tags="+*tags)
+	}
+	pkgs, err := driver.Run(cfg, "file="+path)
+	if err != nil {
+		return err
+	}
+
+	pkg, swtch, nested := findTypeSwitch(pkgs, path, line)
+	if swtch == nil {
+		return fmt.Errorf("no type switch at %s:%d", flags.Arg(0)[:i], line)
+	}
+	start, end := pkg.Fset.Position(swtch.Pos()), pkg.Fset.Position(swtch.End())
+	fmt.Printf("type switch at %s:%d:%d\n", flags.Arg(0)[:i], start.Line, start.Column)
+	// The switch is traced once for the package and once for its test
+	// variant, if any.
+	type key struct {
+		pos token.Position
+		msg string
+	}
+	seen := map[key]bool{}
+	for _, p := range pkgs {
+		for _, entry := range p.Result.Trace {
+			pos := p.Fset.Position(entry.Pos)
+			k := key{pos, entry.Message}
+			if pos.Filename != path || !within(pos, start, end) || seen[k] {
+				continue
+			}
+			if inAny(pos, pkg.Fset, nested) {
+				continue
+			}
+			seen[k] = true
+			fmt.Printf("\t%d:%d: %s\n", pos.Line, pos.Column, entry.Message)
+		}
+	}
+	for _, f := range driver.Findings(pkgs) {
+		if f.Position.File == path && f.Position.Line == start.Line && f.Position.Column == start.Column {
+			fmt.Printf("reported: %s\n", f.Message)
+		}
+	}
+	return nil
+}
+
+// findTypeSwitch returns the innermost type switch in the file at path that
+// contains the given line, along with the package containing it and the type
+// switches nested in it.
+func findTypeSwitch(pkgs []*driver.Package, path string, line int) (*driver.Package, *ast.TypeSwitchStmt, []ast.Node) {
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			if pkg.Fset.Position(file.Pos()).Filename != path {
+				continue
+			}
+			var found *ast.TypeSwitchStmt
+			ast.Inspect(file, func(n ast.Node) bool {
+				if n == nil {
+					return false
+				}
+				start, end := pkg.Fset.Position(n.Pos()).Line, pkg.Fset.Position(n.End()).Line
+				if line < start || line > end {
+					return false
+				}
+				if swtch, ok := n.(*ast.TypeSwitchStmt); ok {
+					found = swtch
+				}
+				return true
+			})
+			if found == nil {
+				continue
+			}
+			var nested []ast.Node
+			ast.Inspect(found.Body, func(n ast.Node) bool {
+				if swtch, ok := n.(*ast.TypeSwitchStmt); ok {
+					nested = append(nested, swtch)
+					return false
+				}
+				return true
+			})
+			return pkg, found, nested
+		}
+	}
+	return nil, nil, nil
+}
+
+// within returns true if pos is between start and end, inclusive.
+func within(pos, start, end token.Position) bool {
+	before := func(a, b token.Position) bool {
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	}
+	return !before(pos, start) && !before(end, pos)
+}
+
+// inAny returns true if pos is within any of the given nodes.
+func inAny(pos token.Position, fset *token.FileSet, nodes []ast.Node) bool {
+	for _, n := range nodes {
+		if within(pos, fset.Position(n.Pos()), fset.Position(n.End())) {
+			return true
+		}
+	}
+	return false
+}